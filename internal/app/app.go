@@ -4,6 +4,7 @@ import (
 	"context"
 	"litespend-api/internal/config"
 	"litespend-api/internal/httpsrv"
+	"litespend-api/internal/jobs"
 	"litespend-api/internal/repository"
 	"litespend-api/internal/repository/databases"
 	"litespend-api/internal/service"
@@ -16,6 +17,7 @@ type App struct {
 	repository *repository.Repository
 	service    *service.Service
 	server     *httpsrv.Server
+	jobs       *jobs.Runner
 }
 
 func NewApp(cfg config.Config) *App {
@@ -34,17 +36,56 @@ func NewApp(cfg config.Config) *App {
 
 	server := httpsrv.NewServer(cfg.Server, services, sessionManager, repo)
 
+	runner := jobs.NewRunner(
+		jobs.Job{
+			Name:     "post_due_prescribed_expanses",
+			Interval: cfg.App.JobsInterval,
+			Run: func(ctx context.Context) error {
+				posted, err := services.PrescribedExpanse.PostDue(ctx)
+				if posted > 0 {
+					slog.InfoContext(ctx, "Posted due prescribed expanses", slog.Int("count", posted))
+				}
+				return err
+			},
+		},
+		jobs.Job{
+			Name:     "purge_trash",
+			Interval: cfg.App.JobsInterval,
+			Run: func(ctx context.Context) error {
+				purged, err := services.Trash.Purge(ctx, cfg.App.TrashRetention)
+				if purged.Accounts+purged.Categories+purged.Transactions > 0 {
+					slog.InfoContext(ctx, "Purged trash",
+						slog.Int("accounts", purged.Accounts),
+						slog.Int("categories", purged.Categories),
+						slog.Int("transactions", purged.Transactions),
+					)
+				}
+				return err
+			},
+		},
+	)
+
 	app := App{
 		config:     cfg,
 		repository: repo,
 		service:    services,
 		server:     server,
+		jobs:       runner,
 	}
 
 	return &app
 }
 
 func (a *App) Run() {
-	slog.InfoContext(context.Background(), "Starting server")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		a.jobs.Wait()
+	}()
+
+	slog.InfoContext(ctx, "Starting background jobs")
+	a.jobs.Start(ctx)
+
+	slog.InfoContext(ctx, "Starting server")
 	a.server.Run()
 }
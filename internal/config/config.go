@@ -22,6 +22,24 @@ func (c PostgresConfig) GetDSNString() string {
 type AppConfig struct {
 	LogLevel   string        `env:"LOG_LEVEL"`
 	SessionTTL time.Duration `env:"SESSION_TTL"`
+	// Период запуска фоновых задач
+	JobsInterval time.Duration `env:"JOBS_INTERVAL" env-default:"1h"`
+	// Сколько удалённые записи хранятся в корзине
+	TrashRetention time.Duration `env:"TRASH_RETENTION" env-default:"720h"`
+}
+
+// Validate проверяет периоды: с нулевым или отрицательным интервалом задачи падают
+// при запуске, а такой срок хранения стирал бы корзину сразу после удаления.
+func (c AppConfig) Validate() error {
+	if c.JobsInterval <= 0 {
+		return fmt.Errorf("JOBS_INTERVAL must be positive, got %s", c.JobsInterval)
+	}
+
+	if c.TrashRetention <= 0 {
+		return fmt.Errorf("TRASH_RETENTION must be positive, got %s", c.TrashRetention)
+	}
+
+	return nil
 }
 
 type ServerConfig struct {
@@ -46,6 +64,10 @@ func GetConfig() Config {
 		if err != nil {
 			panic(fmt.Errorf("failed to read env: %w", err))
 		}
+
+		if err := configInstance.App.Validate(); err != nil {
+			panic(fmt.Errorf("invalid config: %w", err))
+		}
 	})
 
 	return configInstance
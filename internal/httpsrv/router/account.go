@@ -1,6 +1,7 @@
 package router
 
 import (
+	"errors"
 	"litespend-api/internal/httpsrv/middleware"
 	"litespend-api/internal/model"
 	"litespend-api/internal/service"
@@ -79,15 +80,59 @@ func (r AccountRouter) DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	err = r.service.Account.Delete(c.Request.Context(), logined, id)
+	var req model.DeleteAccountRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = r.service.Account.Delete(c.Request.Context(), logined, id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondAccountError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "account deleted"})
 }
 
+func (r AccountRouter) RestoreAccount(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	err = r.service.Account.Restore(c.Request.Context(), logined, id)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "account restored"})
+}
+
+func respondAccountError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccountHasTransactions):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidAccountDeletion):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func (r AccountRouter) CreateAccount(c *gin.Context) {
 	logined, ok := middleware.GetUserFromContext(c)
 	if !ok {
@@ -104,9 +149,113 @@ func (r AccountRouter) CreateAccount(c *gin.Context) {
 
 	id, err := r.service.Account.Create(c.Request.Context(), logined, req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidLoanTerms) || errors.Is(err, service.ErrOnBudgetInvestment) ||
+			errors.Is(err, service.ErrInvalidCurrency) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"id": id})
 }
+
+func (r AccountRouter) AdjustBalance(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req model.AdjustAccountBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	adjustment, err := r.service.Account.AdjustBalance(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustment)
+}
+
+// GetReconciliation показывает расхождение остатка по выписке с подтверждёнными
+// транзакциями: ?date=2025-01-31&statement_balance=1234.56
+func (r AccountRouter) GetReconciliation(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	date, err := parseQueryDate(c, "date")
+	if err != nil || date == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date"})
+		return
+	}
+
+	statementBalance, err := parseQueryDecimal(c, "statement_balance")
+	if err != nil || statementBalance == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid statement_balance"})
+		return
+	}
+
+	reconciliation, err := r.service.Account.GetReconciliation(c.Request.Context(), logined, id, *date, *statementBalance)
+	if err != nil {
+		respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reconciliation)
+}
+
+func (r AccountRouter) Reconcile(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req model.ReconciliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := r.service.Account.Reconcile(c.Request.Context(), logined, id, req)
+	if err != nil {
+		if errors.Is(err, service.ErrReconciliationMismatch) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "reconciliation": result.AccountReconciliation})
+			return
+		}
+		respondAccountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -33,6 +33,22 @@ func (r *BudgetRouter) CreateBudget(c *gin.Context) {
 
 	id, err := r.service.Budget.Create(c.Request.Context(), logined, req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidBudgetPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrBudgetExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -66,6 +82,10 @@ func (r *BudgetRouter) UpdateBudget(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, service.ErrInvalidBudgetUpdate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if errors.Is(err, service.ErrAccessDenied) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
@@ -77,6 +97,195 @@ func (r *BudgetRouter) UpdateBudget(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "budget updated"})
 }
 
+func (r *BudgetRouter) DeleteBudget(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid budget id"})
+		return
+	}
+
+	err = r.service.Budget.Delete(c.Request.Context(), logined, id)
+	if err != nil {
+		if errors.Is(err, service.ErrBudgetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "budget deleted"})
+}
+
+func (r *BudgetRouter) AssignBudget(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	year, month, err := parseBudgetPeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req model.AssignBudgetRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	allocation, err := r.service.Budget.Assign(c.Request.Context(), logined, year, month, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBudgetPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, allocation)
+}
+
+func (r *BudgetRouter) QuickAssignBudget(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	year, month, err := parseBudgetPeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req model.QuickAssignRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := r.service.Budget.QuickAssign(c.Request.Context(), logined, year, month, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBudgetPeriod) || errors.Is(err, service.ErrInvalidQuickAssign) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *BudgetRouter) MoveMoney(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	year, month, err := parseBudgetPeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req model.MoveBudgetRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	move, err := r.service.Budget.MoveMoney(c.Request.Context(), logined, year, month, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBudgetPeriod) || errors.Is(err, service.ErrInvalidBudgetMove) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrCategoryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, move)
+}
+
+func (r *BudgetRouter) GetMoves(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	month, err := strconv.ParseUint(c.Query("month"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
+		return
+	}
+
+	year, err := strconv.ParseUint(c.Query("year"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+		return
+	}
+
+	moves, err := r.service.Budget.GetMoves(c.Request.Context(), logined, uint(year), uint(month))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBudgetPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, moves)
+}
+
+func parseBudgetPeriod(c *gin.Context) (uint, uint, error) {
+	year, err := strconv.ParseUint(c.Param("year"), 10, 32)
+	if err != nil {
+		return 0, 0, errors.New("invalid year")
+	}
+
+	month, err := strconv.ParseUint(c.Param("month"), 10, 32)
+	if err != nil {
+		return 0, 0, errors.New("invalid month")
+	}
+
+	return uint(year), uint(month), nil
+}
+
 func (r *BudgetRouter) GetBudget(c *gin.Context) {
 	logined, ok := middleware.GetUserFromContext(c)
 	if !ok {
@@ -36,6 +36,10 @@ func (r *CategoryRouter) CreateCategory(c *gin.Context) {
 
 	id, err := r.service.Category.Create(c.Request.Context(), logined, req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCategoryTarget) || errors.Is(err, service.ErrCategoryGroupNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -65,6 +69,10 @@ func (r *CategoryRouter) UpdateCategory(c *gin.Context) {
 
 	err = r.service.Category.Update(c.Request.Context(), logined, id, req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCategoryTarget) || errors.Is(err, service.ErrCategoryGroupNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if errors.Is(err, service.ErrCategoryNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
@@ -94,7 +102,107 @@ func (r *CategoryRouter) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	err = r.service.Category.Delete(c.Request.Context(), logined, id)
+	var req model.DeleteCategoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage, err := r.service.Category.Delete(c.Request.Context(), logined, id, req)
+	if err != nil {
+		if errors.Is(err, service.ErrCategoryInUse) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "usage": usage})
+			return
+		}
+		respondCategoryReassignError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category deleted", "reassigned": usage})
+}
+
+func (r *CategoryRouter) MergeCategory(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	var req model.MergeCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage, err := r.service.Category.Merge(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondCategoryReassignError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category merged", "reassigned": usage})
+}
+
+func (r *CategoryRouter) GetCategoryUsage(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	usage, err := r.service.Category.GetUsage(c.Request.Context(), logined, id)
+	if err != nil {
+		respondCategoryReassignError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+func respondCategoryReassignError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCategoryReassign):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPaymentCategory):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (r *CategoryRouter) GetCategory(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	category, err := r.service.Category.GetByID(c.Request.Context(), logined, id)
 	if err != nil {
 		if errors.Is(err, service.ErrCategoryNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -108,10 +216,26 @@ func (r *CategoryRouter) DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "category deleted"})
+	c.JSON(http.StatusOK, category)
 }
 
-func (r *CategoryRouter) GetCategory(c *gin.Context) {
+func (r *CategoryRouter) GetCategories(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	categories, err := r.service.Category.GetList(c.Request.Context(), logined)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+func (r *CategoryRouter) RestoreCategory(c *gin.Context) {
 	logined, ok := middleware.GetUserFromContext(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
@@ -125,12 +249,16 @@ func (r *CategoryRouter) GetCategory(c *gin.Context) {
 		return
 	}
 
-	category, err := r.service.Category.GetByID(c.Request.Context(), logined, id)
+	err = r.service.Category.Restore(c.Request.Context(), logined, id)
 	if err != nil {
 		if errors.Is(err, service.ErrCategoryNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, service.ErrPaymentCategory) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		if errors.Is(err, service.ErrAccessDenied) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
@@ -139,21 +267,33 @@ func (r *CategoryRouter) GetCategory(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, category)
+	c.JSON(http.StatusOK, gin.H{"message": "category restored"})
 }
 
-func (r *CategoryRouter) GetCategories(c *gin.Context) {
+// ReorderCategories сохраняет порядок категорий после перетаскивания, в том числе
+// между группами.
+func (r *CategoryRouter) ReorderCategories(c *gin.Context) {
 	logined, ok := middleware.GetUserFromContext(c)
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
 
-	categories, err := r.service.Category.GetList(c.Request.Context(), logined)
+	var req model.ReorderCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := r.service.Category.Reorder(c.Request.Context(), logined, req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCategoryOrder) || errors.Is(err, service.ErrCategoryGroupNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, categories)
+	c.JSON(http.StatusOK, gin.H{"message": "categories reordered"})
 }
@@ -0,0 +1,146 @@
+package router
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"litespend-api/internal/httpsrv/middleware"
+	"litespend-api/internal/model"
+	"litespend-api/internal/service"
+	"net/http"
+	"strconv"
+)
+
+type CategoryGroupRouter struct {
+	service *service.Service
+}
+
+func NewCategoryGroupRouter(service *service.Service) *CategoryGroupRouter {
+	return &CategoryGroupRouter{
+		service: service,
+	}
+}
+
+func (r *CategoryGroupRouter) CreateCategoryGroup(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.CreateCategoryGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := r.service.CategoryGroup.Create(c.Request.Context(), logined, req)
+	if err != nil {
+		respondCategoryGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+func (r *CategoryGroupRouter) GetCategoryGroups(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	groups, err := r.service.CategoryGroup.GetList(c.Request.Context(), logined)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"groups": groups})
+}
+
+func (r *CategoryGroupRouter) UpdateCategoryGroup(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category group id"})
+		return
+	}
+
+	var req model.UpdateCategoryGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = r.service.CategoryGroup.Update(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondCategoryGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category group updated"})
+}
+
+func (r *CategoryGroupRouter) DeleteCategoryGroup(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category group id"})
+		return
+	}
+
+	err = r.service.CategoryGroup.Delete(c.Request.Context(), logined, id)
+	if err != nil {
+		respondCategoryGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category group deleted"})
+}
+
+func (r *CategoryGroupRouter) ReorderCategoryGroups(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.ReorderCategoryGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := r.service.CategoryGroup.Reorder(c.Request.Context(), logined, req)
+	if err != nil {
+		respondCategoryGroupError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "category groups reordered"})
+}
+
+func respondCategoryGroupError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCategoryGroupNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCategoryGroupName), errors.Is(err, service.ErrInvalidCategoryGroupOrder):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,99 @@
+package router
+
+import (
+	"errors"
+	"litespend-api/internal/httpsrv/middleware"
+	"litespend-api/internal/model"
+	"litespend-api/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeRateRouter struct {
+	service *service.Service
+}
+
+func NewExchangeRateRouter(service *service.Service) *ExchangeRateRouter {
+	return &ExchangeRateRouter{
+		service: service,
+	}
+}
+
+func (r *ExchangeRateRouter) CreateExchangeRate(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.CreateExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate, err := r.service.ExchangeRate.Create(c.Request.Context(), logined, req)
+	if err != nil {
+		respondExchangeRateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// GetExchangeRates возвращает курсы, currency ограничивает выборку парами с этой валютой.
+func (r *ExchangeRateRouter) GetExchangeRates(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var currency *model.Currency
+	if raw := c.Query("currency"); raw != "" {
+		value := model.Currency(raw)
+		currency = &value
+	}
+
+	rates, err := r.service.ExchangeRate.GetList(c.Request.Context(), logined, currency)
+	if err != nil {
+		respondExchangeRateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rates)
+}
+
+// ImportExchangeRates загружает курсы из файла в поле file формы.
+func (r *ExchangeRateRouter) ImportExchangeRates(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	fileData, err := readUploadedFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := r.service.ExchangeRate.Import(c.Request.Context(), logined, fileData)
+	if err != nil {
+		respondExchangeRateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func respondExchangeRateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidExchangeRate), errors.Is(err, service.ErrInvalidCurrency),
+		errors.Is(err, service.ErrImportInvalidFile):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
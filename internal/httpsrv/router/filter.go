@@ -0,0 +1,137 @@
+package router
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"litespend-api/internal/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const filterDateLayout = "2006-01-02"
+
+// ParseTransactionFilterFromContext читает фильтры списка транзакций из query.
+// Списки ID принимаются как повторяющимся параметром, так и через запятую.
+func ParseTransactionFilterFromContext(c *gin.Context) (model.TransactionFilter, error) {
+	var (
+		filter model.TransactionFilter
+		err    error
+	)
+
+	if filter.From, err = parseQueryDate(c, "from"); err != nil {
+		return filter, err
+	}
+
+	if filter.To, err = parseQueryDate(c, "to"); err != nil {
+		return filter, err
+	}
+
+	if filter.AccountIDs, err = parseQueryIDs(c, "account_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.CategoryIDs, err = parseQueryIDs(c, "category_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.PayeeIDs, err = parseQueryIDs(c, "payee_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.MinAmount, err = parseQueryDecimal(c, "min_amount"); err != nil {
+		return filter, err
+	}
+
+	if filter.MaxAmount, err = parseQueryDecimal(c, "max_amount"); err != nil {
+		return filter, err
+	}
+
+	if filter.IsCleared, err = parseQueryBool(c, "cleared"); err != nil {
+		return filter, err
+	}
+
+	if filter.IsApproved, err = parseQueryBool(c, "approved"); err != nil {
+		return filter, err
+	}
+
+	uncategorized, err := parseQueryBool(c, "uncategorized")
+	if err != nil {
+		return filter, err
+	}
+	filter.Uncategorized = uncategorized != nil && *uncategorized
+
+	if kindStr := c.Query("type"); kindStr != "" {
+		kind := model.TransactionKind(kindStr)
+		if !kind.IsValid() {
+			return filter, fmt.Errorf("invalid type %q", kindStr)
+		}
+		filter.Kind = &kind
+	}
+
+	return filter, nil
+}
+
+func parseQueryDate(c *gin.Context, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse(filterDateLayout, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", key)
+	}
+
+	return &date, nil
+}
+
+func parseQueryIDs(c *gin.Context, key string) ([]uint64, error) {
+	var ids []uint64
+
+	for _, value := range c.QueryArray(key) {
+		for _, raw := range strings.Split(value, ",") {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+
+			id, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s", key)
+			}
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func parseQueryDecimal(c *gin.Context, key string) (*decimal.Decimal, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := decimal.NewFromString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", key)
+	}
+
+	return &value, nil
+}
+
+func parseQueryBool(c *gin.Context, key string) (*bool, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", key)
+	}
+
+	return &value, nil
+}
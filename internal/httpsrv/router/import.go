@@ -1 +1,114 @@
 package router
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"litespend-api/internal/httpsrv/middleware"
+	"litespend-api/internal/model"
+	"litespend-api/internal/service"
+	"net/http"
+	"strconv"
+)
+
+type ImportRouter struct {
+	service *service.Service
+}
+
+func NewImportRouter(service *service.Service) *ImportRouter {
+	return &ImportRouter{
+		service: service,
+	}
+}
+
+func (r *ImportRouter) ParseFile(c *gin.Context) {
+	_, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	fileData, err := readUploadedFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	structure, err := r.service.Import.ParseExcelFile(fileData)
+	if err != nil {
+		if errors.Is(err, service.ErrImportInvalidFile) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, structure)
+}
+
+func (r *ImportRouter) ImportData(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	fileData, err := readUploadedFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Счёт необязателен: веб-клиент отправляет только файл и сопоставление столбцов
+	var req model.ImportRequest
+	if raw := c.PostForm("account_id"); raw != "" {
+		accountID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+			return
+		}
+		req.AccountID = &accountID
+	}
+
+	if err := json.Unmarshal([]byte(c.PostForm("mapping")), &req.Mapping); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mapping"})
+		return
+	}
+
+	result, err := r.service.Import.ImportData(c.Request.Context(), logined, fileData, req)
+	if err != nil {
+		if errors.Is(err, service.ErrImportInvalidFile) || errors.Is(err, service.ErrImportInvalidMapping) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccountNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func readUploadedFile(c *gin.Context) ([]byte, error) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return nil, errors.New("file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
@@ -0,0 +1,157 @@
+package router
+
+import (
+	"errors"
+	"litespend-api/internal/httpsrv/middleware"
+	"litespend-api/internal/model"
+	"litespend-api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvestmentRouter struct {
+	service *service.Service
+}
+
+func NewInvestmentRouter(service *service.Service) *InvestmentRouter {
+	return &InvestmentRouter{
+		service: service,
+	}
+}
+
+func (r InvestmentRouter) GetSummary(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	summary, err := r.service.Investment.GetSummary(c.Request.Context(), logined, id)
+	if err != nil {
+		respondInvestmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (r InvestmentRouter) RecordActivity(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req model.CreateInvestmentActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	activity, err := r.service.Investment.RecordActivity(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondInvestmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, activity)
+}
+
+func (r InvestmentRouter) GetActivities(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	activities, err := r.service.Investment.GetActivities(c.Request.Context(), logined, id)
+	if err != nil {
+		respondInvestmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, activities)
+}
+
+func (r InvestmentRouter) CreatePrice(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.CreateSecurityPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	price, err := r.service.Investment.CreatePrice(c.Request.Context(), logined, req)
+	if err != nil {
+		respondInvestmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, price)
+}
+
+// GetPrices возвращает введённые цены, ticker ограничивает выборку одной бумагой.
+func (r InvestmentRouter) GetPrices(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var ticker *string
+	if raw := c.Query("ticker"); raw != "" {
+		ticker = &raw
+	}
+
+	prices, err := r.service.Investment.GetPrices(c.Request.Context(), logined, ticker)
+	if err != nil {
+		respondInvestmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prices)
+}
+
+func respondInvestmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotInvestmentAccount), errors.Is(err, service.ErrInvalidInvestmentActivity),
+		errors.Is(err, service.ErrInvalidSecurityPrice):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInsufficientHolding):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,197 @@
+package router
+
+import (
+	"errors"
+	"litespend-api/internal/httpsrv/middleware"
+	"litespend-api/internal/model"
+	"litespend-api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type LoanRouter struct {
+	service *service.Service
+}
+
+func NewLoanRouter(service *service.Service) *LoanRouter {
+	return &LoanRouter{
+		service: service,
+	}
+}
+
+func (r LoanRouter) GetLoan(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	loan, err := r.service.Loan.Get(c.Request.Context(), logined, id)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, loan)
+}
+
+func (r LoanRouter) UpdateLoan(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req model.UpdateLoanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = r.service.Loan.Update(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "loan updated"})
+}
+
+func (r LoanRouter) GetSchedule(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	schedule, err := r.service.Loan.GetSchedule(c.Request.Context(), logined, id)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+// GetProjection возвращает прогноз погашения, extra_payment — ежемесячный
+// досрочный платёж, эффект которого нужно показать.
+func (r LoanRouter) GetProjection(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	extra, err := parseQueryDecimal(c, "extra_payment")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if extra == nil {
+		extra = &decimal.Zero
+	}
+
+	projection, err := r.service.Loan.GetProjection(c.Request.Context(), logined, id, *extra)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, projection)
+}
+
+func (r LoanRouter) RecordPayment(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	var req model.LoanPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := r.service.Loan.RecordPayment(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payment)
+}
+
+func (r LoanRouter) GetPayments(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid account id"})
+		return
+	}
+
+	payments, err := r.service.Loan.GetPayments(c.Request.Context(), logined, id)
+	if err != nil {
+		respondLoanError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
+
+func respondLoanError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAccountNotFound), errors.Is(err, service.ErrLoanNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidLoanTerms), errors.Is(err, service.ErrInvalidLoanPayment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -35,5 +35,10 @@ func ParsePaginationFromContext(c *gin.Context) model.PaginationParams {
 		params.Search = &searchStr
 	}
 
+	// Keyset-пагинация: для первой страницы передаётся пустой cursor
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		params.Cursor = &cursor
+	}
+
 	return params
 }
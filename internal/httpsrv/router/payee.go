@@ -0,0 +1,270 @@
+package router
+
+import (
+	"errors"
+	"litespend-api/internal/httpsrv/middleware"
+	"litespend-api/internal/model"
+	"litespend-api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PayeeRouter struct {
+	service *service.Service
+}
+
+func NewPayeeRouter(service *service.Service) *PayeeRouter {
+	return &PayeeRouter{
+		service: service,
+	}
+}
+
+func (r *PayeeRouter) CreatePayee(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.CreatePayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payee, err := r.service.Payee.Create(c.Request.Context(), logined, req)
+	if err != nil {
+		respondPayeeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payee)
+}
+
+// GetPayees возвращает получателей, search ищет по части имени.
+func (r *PayeeRouter) GetPayees(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var search *string
+	if raw, exists := c.GetQuery("search"); exists {
+		search = &raw
+	}
+
+	payees, err := r.service.Payee.GetList(c.Request.Context(), logined, search)
+	if err != nil {
+		respondPayeeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payees)
+}
+
+// AutocompletePayees подбирает получателей по тексту q с предлагаемой категорией.
+func (r *PayeeRouter) AutocompletePayees(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := r.service.Payee.Autocomplete(c.Request.Context(), logined, c.Query("q"), limit)
+	if err != nil {
+		respondPayeeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+func (r *PayeeRouter) GetPayee(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payee id"})
+		return
+	}
+
+	payee, err := r.service.Payee.Get(c.Request.Context(), logined, id)
+	if err != nil {
+		respondPayeeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payee)
+}
+
+func (r *PayeeRouter) UpdatePayee(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payee id"})
+		return
+	}
+
+	var req model.UpdatePayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = r.service.Payee.Update(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondPayeeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payee updated"})
+}
+
+func (r *PayeeRouter) DeletePayee(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payee id"})
+		return
+	}
+
+	err = r.service.Payee.Delete(c.Request.Context(), logined, id)
+	if err != nil {
+		respondPayeeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "payee deleted"})
+}
+
+// MergePayee сливает получателя-дубликат с получателем into_payee_id.
+func (r *PayeeRouter) MergePayee(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payee id"})
+		return
+	}
+
+	var req model.MergePayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := r.service.Payee.Merge(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondPayeeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (r *PayeeRouter) CreateRenameRule(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payee id"})
+		return
+	}
+
+	var req model.CreatePayeeRenameRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := r.service.Payee.CreateRenameRule(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondPayeeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (r *PayeeRouter) DeleteRenameRule(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payee id"})
+		return
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("rule_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	err = r.service.Payee.DeleteRenameRule(c.Request.Context(), logined, id, ruleID)
+	if err != nil {
+		respondPayeeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "rename rule deleted"})
+}
+
+func respondPayeeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPayeeNotFound), errors.Is(err, service.ErrPayeeRenameRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidPayee), errors.Is(err, service.ErrInvalidPayeeMerge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPayeeExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,237 @@
+package router
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"litespend-api/internal/httpsrv/middleware"
+	"litespend-api/internal/model"
+	"litespend-api/internal/service"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type PrescribedExpanseRouter struct {
+	service *service.Service
+}
+
+func NewPrescribedExpanseRouter(service *service.Service) *PrescribedExpanseRouter {
+	return &PrescribedExpanseRouter{
+		service: service,
+	}
+}
+
+func (r *PrescribedExpanseRouter) CreatePrescribedExpanse(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.CreatePrescribedExpanseRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := r.service.PrescribedExpanse.Create(c.Request.Context(), logined, req)
+	if err != nil {
+		respondPrescribedExpanseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+func (r *PrescribedExpanseRouter) UpdatePrescribedExpanse(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid prescribed expanse id"})
+		return
+	}
+
+	var req model.UpdatePrescribedExpanseRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = r.service.PrescribedExpanse.Update(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondPrescribedExpanseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "prescribed expanse updated"})
+}
+
+func (r *PrescribedExpanseRouter) DeletePrescribedExpanse(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid prescribed expanse id"})
+		return
+	}
+
+	err = r.service.PrescribedExpanse.Delete(c.Request.Context(), logined, id)
+	if err != nil {
+		respondPrescribedExpanseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "prescribed expanse deleted"})
+}
+
+func (r *PrescribedExpanseRouter) GetPrescribedExpanse(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid prescribed expanse id"})
+		return
+	}
+
+	prescribedExpanse, err := r.service.PrescribedExpanse.GetByID(c.Request.Context(), logined, id)
+	if err != nil {
+		respondPrescribedExpanseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prescribedExpanse)
+}
+
+func (r *PrescribedExpanseRouter) GetPrescribedExpanses(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	prescribedExpanses, err := r.service.PrescribedExpanse.GetList(c.Request.Context(), logined)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescribedExpanses)
+}
+
+// GetPrescribedExpansesWithPaymentStatus отдаёт повторения месяца из year/month,
+// по умолчанию — текущего.
+func (r *PrescribedExpanseRouter) GetPrescribedExpansesWithPaymentStatus(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	now := time.Now()
+	year, month := uint64(now.Year()), uint64(now.Month())
+
+	var err error
+	if raw := c.Query("year"); raw != "" {
+		year, err = strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid year"})
+			return
+		}
+	}
+
+	if raw := c.Query("month"); raw != "" {
+		month, err = strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month"})
+			return
+		}
+	}
+
+	prescribedExpanses, err := r.service.PrescribedExpanse.GetListWithPaymentStatus(c.Request.Context(), logined, uint(year), uint(month))
+	if err != nil {
+		respondPrescribedExpanseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prescribedExpanses)
+}
+
+func (r *PrescribedExpanseRouter) MarkAsPaid(c *gin.Context) {
+	r.markAsPaid(c, false)
+}
+
+func (r *PrescribedExpanseRouter) MarkAsPaidPartial(c *gin.Context) {
+	r.markAsPaid(c, true)
+}
+
+func (r *PrescribedExpanseRouter) markAsPaid(c *gin.Context, partial bool) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid prescribed expanse id"})
+		return
+	}
+
+	// Для полной оплаты тело запроса необязательно
+	var req model.PayPrescribedExpanseRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if partial && req.Amount == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount is required"})
+		return
+	}
+	if !partial {
+		req.Amount = nil
+	}
+
+	transactionID, err := r.service.PrescribedExpanse.MarkAsPaid(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondPrescribedExpanseError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transaction_id": transactionID, "message": "prescribed expanse paid"})
+}
+
+func respondPrescribedExpanseError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPrescribedExpanse),
+		errors.Is(err, service.ErrInvalidPayment),
+		errors.Is(err, service.ErrInvalidBudgetPeriod),
+		errors.Is(err, service.ErrOffBudgetCategory),
+		errors.Is(err, service.ErrPaymentCategory):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNothingToPay):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPrescribedExpanseNotFound), errors.Is(err, service.ErrAccountNotFound),
+		errors.Is(err, service.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -12,6 +12,18 @@ type Router struct {
 	Budget      *BudgetRouter
 	Auth        *AuthRouter
 	Account     *AccountRouter
+	Import      *ImportRouter
+
+	CategoryGroup     *CategoryGroupRouter
+	ExchangeRate      *ExchangeRateRouter
+	Investment        *InvestmentRouter
+	Loan              *LoanRouter
+	Payee             *PayeeRouter
+	PrescribedExpanse *PrescribedExpanseRouter
+	Rule              *RuleRouter
+	Statistics        *StatisticsRouter
+	Sync              *SyncRouter
+	Trash             *TrashRouter
 }
 
 func NewRouter(service *service.Service, sessionManager *session.SessionManager) *Router {
@@ -22,5 +34,17 @@ func NewRouter(service *service.Service, sessionManager *session.SessionManager)
 		Budget:      NewBudgetRouter(service),
 		Auth:        NewAuthRouter(service),
 		Account:     NewAccountRouter(service),
+		Import:      NewImportRouter(service),
+
+		CategoryGroup:     NewCategoryGroupRouter(service),
+		ExchangeRate:      NewExchangeRateRouter(service),
+		Investment:        NewInvestmentRouter(service),
+		Loan:              NewLoanRouter(service),
+		Payee:             NewPayeeRouter(service),
+		PrescribedExpanse: NewPrescribedExpanseRouter(service),
+		Rule:              NewRuleRouter(service),
+		Statistics:        NewStatisticsRouter(service),
+		Sync:              NewSyncRouter(service),
+		Trash:             NewTrashRouter(service),
 	}
 }
@@ -0,0 +1,162 @@
+package router
+
+import (
+	"errors"
+	"litespend-api/internal/httpsrv/middleware"
+	"litespend-api/internal/model"
+	"litespend-api/internal/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RuleRouter struct {
+	service *service.Service
+}
+
+func NewRuleRouter(service *service.Service) *RuleRouter {
+	return &RuleRouter{
+		service: service,
+	}
+}
+
+func (r *RuleRouter) CreateRule(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.CreateTransactionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := r.service.Rule.Create(c.Request.Context(), logined, req)
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (r *RuleRouter) GetRules(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	rules, err := r.service.Rule.GetList(c.Request.Context(), logined)
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// UpdateRule заменяет правило целиком, тело как при создании.
+func (r *RuleRouter) UpdateRule(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	var req model.CreateTransactionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = r.service.Rule.Update(c.Request.Context(), logined, id, req)
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "rule updated"})
+}
+
+func (r *RuleRouter) DeleteRule(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule id"})
+		return
+	}
+
+	err = r.service.Rule.Delete(c.Request.Context(), logined, id)
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "rule deleted"})
+}
+
+// PreviewRules показывает, что изменил бы прогон правил по истории, ничего не меняя.
+func (r *RuleRouter) PreviewRules(c *gin.Context) {
+	r.runRules(c, true)
+}
+
+// ApplyRules прогоняет правила по истории и сохраняет изменения.
+func (r *RuleRouter) ApplyRules(c *gin.Context) {
+	r.runRules(c, false)
+}
+
+func (r *RuleRouter) runRules(c *gin.Context, dryRun bool) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	// Без тела правила прогоняются по всей истории
+	var req model.ApplyRulesRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := r.service.Rule.Apply(c.Request.Context(), logined, req, dryRun)
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func respondRuleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRuleNotFound), errors.Is(err, service.ErrAccountNotFound),
+		errors.Is(err, service.ErrCategoryNotFound), errors.Is(err, service.ErrPayeeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidRule), errors.Is(err, service.ErrInvalidRuleRun):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
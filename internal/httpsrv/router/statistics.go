@@ -0,0 +1,150 @@
+package router
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"litespend-api/internal/httpsrv/middleware"
+	"litespend-api/internal/model"
+	"litespend-api/internal/service"
+	"net/http"
+	"time"
+)
+
+type StatisticsRouter struct {
+	service *service.Service
+}
+
+func NewStatisticsRouter(service *service.Service) *StatisticsRouter {
+	return &StatisticsRouter{
+		service: service,
+	}
+}
+
+// GetCurrentBalance отдаёт сводку за месяц из year/month, по умолчанию — текущий.
+func (r *StatisticsRouter) GetCurrentBalance(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.CurrentBalanceStatisticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	if req.Year == 0 {
+		req.Year = uint(now.Year())
+	}
+	if req.Month == 0 {
+		req.Month = uint(now.Month())
+	}
+
+	statistics, err := r.service.Statistics.GetCurrentBalance(c.Request.Context(), logined, req.Year, req.Month)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBudgetPeriod) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statistics)
+}
+
+func (r *StatisticsRouter) GetPeriodStatistics(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.PeriodStatisticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	statistics, err := r.service.Statistics.GetPeriodStatistics(c.Request.Context(), logined, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatisticsFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statistics)
+}
+
+func (r *StatisticsRouter) GetCategoryStatistics(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.CategoryStatisticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	statistics, err := r.service.Statistics.GetCategoryStatistics(c.Request.Context(), logined, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatisticsFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statistics)
+}
+
+func (r *StatisticsRouter) GetPayeeStatistics(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.PayeeStatisticsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	statistics, err := r.service.Statistics.GetPayeeStatistics(c.Request.Context(), logined, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatisticsFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statistics)
+}
+
+func (r *StatisticsRouter) GetNetWorth(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	netWorth, err := r.service.Statistics.GetNetWorth(c.Request.Context(), logined)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, netWorth)
+}
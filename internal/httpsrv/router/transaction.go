@@ -36,6 +36,18 @@ func (r *TransactionRouter) CreateTransaction(c *gin.Context) {
 
 	id, err := r.service.Transaction.Create(c.Request.Context(), logined, req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidSplit) || errors.Is(err, service.ErrOffBudgetCategory) || errors.Is(err, service.ErrInvalidPayee) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccountNotFound) || errors.Is(err, service.ErrPayeeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -43,6 +55,41 @@ func (r *TransactionRouter) CreateTransaction(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"id": id})
 }
 
+func (r *TransactionRouter) CreateTransfer(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.CreateTransferRequest
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := r.service.Transaction.CreateTransfer(c.Request.Context(), logined, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTransfer) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccountNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
 func (r *TransactionRouter) UpdateTransaction(c *gin.Context) {
 	logined, ok := middleware.GetUserFromContext(c)
 	if !ok {
@@ -73,6 +120,19 @@ func (r *TransactionRouter) UpdateTransaction(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, service.ErrInvalidTransfer) || errors.Is(err, service.ErrInvalidSplit) || errors.Is(err, service.ErrOffBudgetCategory) ||
+			errors.Is(err, service.ErrInvalidPayee) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccountNotFound) || errors.Is(err, service.ErrPayeeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrTransactionReconciled) || errors.Is(err, service.ErrInvestmentTransaction) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -104,6 +164,10 @@ func (r *TransactionRouter) DeleteTransaction(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, service.ErrInvestmentTransaction) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -151,11 +215,71 @@ func (r *TransactionRouter) GetTransactions(c *gin.Context) {
 
 	params := ParsePaginationFromContext(c)
 
-	result, err := r.service.Transaction.GetListPaginated(c.Request.Context(), logined, nil, params)
+	filter, err := ParseTransactionFilterFromContext(c)
 	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if params.Cursor != nil {
+		result, err := r.service.Transaction.GetListByCursor(c.Request.Context(), logined, filter, params)
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidTransactionFilter) || errors.Is(err, service.ErrInvalidCursor) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	result, err := r.service.Transaction.GetListPaginated(c.Request.Context(), logined, filter, params)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTransactionFilter) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, result)
 }
+
+func (r *TransactionRouter) RestoreTransaction(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction id"})
+		return
+	}
+
+	err = r.service.Transaction.Restore(c.Request.Context(), logined, id)
+	if err != nil {
+		if errors.Is(err, service.ErrTransactionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccessDenied) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrAccountInTrash) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "transaction restored"})
+}
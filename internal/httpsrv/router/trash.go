@@ -0,0 +1,34 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"litespend-api/internal/httpsrv/middleware"
+	"litespend-api/internal/service"
+	"net/http"
+)
+
+type TrashRouter struct {
+	service *service.Service
+}
+
+func NewTrashRouter(service *service.Service) *TrashRouter {
+	return &TrashRouter{
+		service: service,
+	}
+}
+
+func (r *TrashRouter) GetTrash(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	trash, err := r.service.Trash.GetList(c.Request.Context(), logined)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
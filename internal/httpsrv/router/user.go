@@ -3,6 +3,7 @@ package router
 import (
 	"errors"
 	"github.com/gin-gonic/gin"
+	"litespend-api/internal/httpsrv/middleware"
 	"litespend-api/internal/model"
 	"litespend-api/internal/service"
 	"litespend-api/internal/session"
@@ -91,3 +92,45 @@ func (r *UserRouter) Logout(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "logout successful"})
 }
+
+func (r *UserRouter) GetSettings(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	settings, err := r.service.User.GetSettings(c.Request.Context(), logined)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (r *UserRouter) UpdateSettings(c *gin.Context) {
+	logined, ok := middleware.GetUserFromContext(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req model.UpdateUserSettingsRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := r.service.User.UpdateSettings(c.Request.Context(), logined, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSettings) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
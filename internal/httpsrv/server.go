@@ -61,14 +61,29 @@ func (s *Server) setup() {
 			auth.POST("/logout", s.router.User.Logout)
 		}
 
+		users := apiv1.Group("/users")
+		users.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
+		{
+			users.GET("/me/settings", s.router.User.GetSettings)
+			users.PATCH("/me/settings", s.router.User.UpdateSettings)
+		}
+
 		transactions := apiv1.Group("/transactions")
 		transactions.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
 		{
 			transactions.POST("", s.router.Transaction.CreateTransaction)
+			transactions.POST("/transfers", s.router.Transaction.CreateTransfer)
+			transactions.GET("/statistics/balance", s.router.Statistics.GetCurrentBalance)
+			transactions.GET("/statistics/current-balance", s.router.Statistics.GetCurrentBalance)
+			transactions.GET("/statistics/periods", s.router.Statistics.GetPeriodStatistics)
+			transactions.GET("/statistics/categories", s.router.Statistics.GetCategoryStatistics)
+			transactions.GET("/statistics/payees", s.router.Statistics.GetPayeeStatistics)
+			transactions.GET("/statistics/net-worth", s.router.Statistics.GetNetWorth)
 			transactions.GET("", s.router.Transaction.GetTransactions)
 			transactions.GET("/:id", s.router.Transaction.GetTransaction)
 			transactions.PUT("/:id", s.router.Transaction.UpdateTransaction)
 			transactions.DELETE("/:id", s.router.Transaction.DeleteTransaction)
+			transactions.POST("/:id/restore", s.router.Transaction.RestoreTransaction)
 		}
 
 		categories := apiv1.Group("/categories")
@@ -76,14 +91,36 @@ func (s *Server) setup() {
 		{
 			categories.POST("", s.router.Category.CreateCategory)
 			categories.GET("", s.router.Category.GetCategories)
+			categories.POST("/reorder", s.router.Category.ReorderCategories)
 			categories.PUT("/:id", s.router.Category.UpdateCategory)
 			categories.DELETE("/:id", s.router.Category.DeleteCategory)
+			categories.POST("/:id/restore", s.router.Category.RestoreCategory)
+			categories.POST("/:id/merge", s.router.Category.MergeCategory)
+			categories.GET("/:id/usage", s.router.Category.GetCategoryUsage)
+		}
+
+		categoryGroups := apiv1.Group("/category-groups")
+		categoryGroups.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
+		{
+			categoryGroups.POST("", s.router.CategoryGroup.CreateCategoryGroup)
+			categoryGroups.GET("", s.router.CategoryGroup.GetCategoryGroups)
+			categoryGroups.POST("/reorder", s.router.CategoryGroup.ReorderCategoryGroups)
+			categoryGroups.PUT("/:id", s.router.CategoryGroup.UpdateCategoryGroup)
+			categoryGroups.DELETE("/:id", s.router.CategoryGroup.DeleteCategoryGroup)
 		}
 
 		budgets := apiv1.Group("/budgets")
 		budgets .Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
 		{
+			budgets.POST("", s.router.Budget.CreateBudget)
 			budgets.GET("/detailed", s.router.Budget.GetBudgets)
+			budgets.GET("/moves", s.router.Budget.GetMoves)
+			budgets.GET("/:id", s.router.Budget.GetBudget)
+			budgets.PUT("/:id", s.router.Budget.UpdateBudget)
+			budgets.DELETE("/:id", s.router.Budget.DeleteBudget)
+			budgets.POST("/:year/:month/assign", s.router.Budget.AssignBudget)
+			budgets.POST("/:year/:month/quick-assign", s.router.Budget.QuickAssignBudget)
+			budgets.POST("/:year/:month/move", s.router.Budget.MoveMoney)
 		}
 
 		accounts := apiv1.Group("/accounts")
@@ -93,6 +130,92 @@ func (s *Server) setup() {
 			accounts.GET("", s.router.Account.GetAccounts)
 			accounts.PATCH("/:id", s.router.Account.UpdateAccount)
 			accounts.DELETE("/:id", s.router.Account.DeleteAccount)
+			accounts.POST("/:id/restore", s.router.Account.RestoreAccount)
+			accounts.POST("/:id/adjust-balance", s.router.Account.AdjustBalance)
+			accounts.GET("/:id/reconciliation", s.router.Account.GetReconciliation)
+			accounts.POST("/:id/reconciliation", s.router.Account.Reconcile)
+			accounts.GET("/:id/loan", s.router.Loan.GetLoan)
+			accounts.PATCH("/:id/loan", s.router.Loan.UpdateLoan)
+			accounts.GET("/:id/loan/schedule", s.router.Loan.GetSchedule)
+			accounts.GET("/:id/loan/projection", s.router.Loan.GetProjection)
+			accounts.GET("/:id/loan/payments", s.router.Loan.GetPayments)
+			accounts.POST("/:id/loan/payments", s.router.Loan.RecordPayment)
+			accounts.GET("/:id/investments", s.router.Investment.GetSummary)
+			accounts.GET("/:id/investments/activities", s.router.Investment.GetActivities)
+			accounts.POST("/:id/investments/activities", s.router.Investment.RecordActivity)
+		}
+
+		exchangeRates := apiv1.Group("/exchange-rates")
+		exchangeRates.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
+		{
+			exchangeRates.POST("", s.router.ExchangeRate.CreateExchangeRate)
+			exchangeRates.GET("", s.router.ExchangeRate.GetExchangeRates)
+			exchangeRates.POST("/import", s.router.ExchangeRate.ImportExchangeRates)
+		}
+
+		investments := apiv1.Group("/investments")
+		investments.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
+		{
+			investments.GET("/prices", s.router.Investment.GetPrices)
+			investments.POST("/prices", s.router.Investment.CreatePrice)
+		}
+
+		payees := apiv1.Group("/payees")
+		payees.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
+		{
+			payees.POST("", s.router.Payee.CreatePayee)
+			payees.GET("", s.router.Payee.GetPayees)
+			payees.GET("/autocomplete", s.router.Payee.AutocompletePayees)
+			payees.GET("/:id", s.router.Payee.GetPayee)
+			payees.PUT("/:id", s.router.Payee.UpdatePayee)
+			payees.DELETE("/:id", s.router.Payee.DeletePayee)
+			payees.POST("/:id/merge", s.router.Payee.MergePayee)
+			payees.POST("/:id/rename-rules", s.router.Payee.CreateRenameRule)
+			payees.DELETE("/:id/rename-rules/:rule_id", s.router.Payee.DeleteRenameRule)
+		}
+
+		rules := apiv1.Group("/rules")
+		rules.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
+		{
+			rules.POST("", s.router.Rule.CreateRule)
+			rules.GET("", s.router.Rule.GetRules)
+			rules.POST("/preview", s.router.Rule.PreviewRules)
+			rules.POST("/apply", s.router.Rule.ApplyRules)
+			rules.PUT("/:id", s.router.Rule.UpdateRule)
+			rules.DELETE("/:id", s.router.Rule.DeleteRule)
+		}
+
+		prescribedExpanses := apiv1.Group("/prescribed-expanses")
+		prescribedExpanses.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
+		{
+			prescribedExpanses.POST("", s.router.PrescribedExpanse.CreatePrescribedExpanse)
+			prescribedExpanses.GET("", s.router.PrescribedExpanse.GetPrescribedExpanses)
+			prescribedExpanses.GET("/with-payment-status", s.router.PrescribedExpanse.GetPrescribedExpansesWithPaymentStatus)
+			prescribedExpanses.GET("/:id", s.router.PrescribedExpanse.GetPrescribedExpanse)
+			prescribedExpanses.PUT("/:id", s.router.PrescribedExpanse.UpdatePrescribedExpanse)
+			prescribedExpanses.DELETE("/:id", s.router.PrescribedExpanse.DeletePrescribedExpanse)
+			prescribedExpanses.POST("/:id/mark-as-paid", s.router.PrescribedExpanse.MarkAsPaid)
+			prescribedExpanses.POST("/:id/mark-as-paid-partial", s.router.PrescribedExpanse.MarkAsPaidPartial)
+		}
+
+		imports := apiv1.Group("/import")
+		imports.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
+		{
+			imports.POST("/parse", s.router.Import.ParseFile)
+			imports.POST("/data", s.router.Import.ImportData)
+		}
+
+		trash := apiv1.Group("/trash")
+		trash.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
+		{
+			trash.GET("", s.router.Trash.GetTrash)
+		}
+
+		sync := apiv1.Group("/sync")
+		sync.Use(middleware.RequireAuth(s.sessionManager, s.repository.UserRepository))
+		{
+			sync.GET("", s.router.Sync.Pull)
+			sync.POST("", s.router.Sync.Push)
 		}
 
 		admin := apiv1.Group("/admin")
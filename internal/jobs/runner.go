@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job — периодическая фоновая задача.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runner выполняет задачи внутри процесса API. Каждая задача запускается сразу
+// после старта и затем с собственным периодом, пока не отменён контекст.
+type Runner struct {
+	jobs []Job
+	wg   sync.WaitGroup
+}
+
+func NewRunner(jobs ...Job) *Runner {
+	return &Runner{
+		jobs: jobs,
+	}
+}
+
+func (r *Runner) Start(ctx context.Context) {
+	for _, job := range r.jobs {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.loop(ctx, job)
+		}()
+	}
+}
+
+// Wait дожидается завершения задач после отмены контекста.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+func (r *Runner) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		r.run(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Runner) run(ctx context.Context, job Job) {
+	defer func() {
+		if p := recover(); p != nil {
+			slog.ErrorContext(ctx, "Job panicked", slog.String("job", job.Name), slog.Any("panic", p))
+		}
+	}()
+
+	started := time.Now()
+	if err := job.Run(ctx); err != nil {
+		slog.ErrorContext(ctx, "Job failed", slog.String("job", job.Name), slog.String("error", err.Error()))
+		return
+	}
+
+	slog.DebugContext(ctx, "Job finished", slog.String("job", job.Name), slog.Duration("duration", time.Since(started)))
+}
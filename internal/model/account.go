@@ -11,6 +11,10 @@ const (
 	AccountTypeCash   AccountType = "cash"
 	AccountTypeBank   AccountType = "bank"
 	AccountTypeCredit AccountType = "credit"
+	// Кредит или ипотека с графиком платежей
+	AccountTypeLoan AccountType = "loan"
+	// Брокерский счёт: деньги на счёте и позиции по бумагам
+	AccountTypeInvestment AccountType = "investment"
 )
 
 type Account struct {
@@ -20,9 +24,18 @@ type Account struct {
 	Type       AccountType     `json:"type" db:"type"`
 	IsArchived bool            `json:"is_archived" db:"is_archived"`
 	OrderNum   int             `json:"order_num" db:"order_num"`
+	OnBudget   bool            `json:"on_budget" db:"on_budget"`
+	Currency   Currency        `json:"currency" db:"currency"`
 	Balance    decimal.Decimal `json:"balance" db:"balance"`
 	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+
+	// Версия изменения и ID, выданный клиентом при офлайн-создании
+	Version  int64   `json:"version" db:"version"`
+	ClientID *string `json:"client_id,omitempty" db:"client_id"`
+
+	// Время переноса в корзину, у активных записей пусто
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 type AccountDB struct {
@@ -32,8 +45,13 @@ type AccountDB struct {
 	Type       AccountType `db:"type"`
 	IsArchived bool        `db:"is_archived"`
 	OrderNum   int         `db:"order_num"`
+	OnBudget   bool        `db:"on_budget"`
+	Currency   Currency    `db:"currency"`
 	CreatedAt  time.Time   `db:"created_at"`
 	UpdatedAt  time.Time   `db:"updated_at"`
+	Version    int64       `db:"version"`
+	ClientID   *string     `db:"client_id"`
+	DeletedAt  *time.Time  `db:"deleted_at"`
 }
 
 type CreateAccountRequest struct {
@@ -41,6 +59,18 @@ type CreateAccountRequest struct {
 	Type       AccountType `json:"type" db:"type"`
 	IsArchived bool        `json:"is_archived" db:"is_archived"`
 	OrderNum   int         `json:"order_num" db:"order_num"`
+	// По умолчанию счёт бюджетный
+	OnBudget *bool `json:"on_budget,omitempty"`
+	// По умолчанию — базовая валюта пользователя, после создания не меняется
+	Currency *Currency `json:"currency,omitempty"`
+
+	// Условия кредита, обязательны для счёта типа loan. Такой счёт по умолчанию
+	// внебюджетный, а без starting_balance открывается с долгом на всю сумму кредита.
+	Loan *LoanTermsRequest `json:"loan,omitempty"`
+
+	// Остаток на счёте на дату starting_date (по умолчанию — сегодня)
+	StartingBalance *decimal.Decimal `json:"starting_balance,omitempty"`
+	StartingDate    *time.Time       `json:"starting_date,omitempty"`
 }
 
 type CreateAccountRecord struct {
@@ -49,8 +79,45 @@ type CreateAccountRecord struct {
 	Type       AccountType
 	IsArchived bool
 	OrderNum   int
+	OnBudget   bool
+	Currency   Currency
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+
+	// Начальный остаток проводится транзакцией, если задан
+	StartingBalance *AccountBalanceRecord
+	// Условия кредита для счёта типа loan
+	Loan *LoanTermsRecord
+}
+
+// AccountBalanceRecord — служебная транзакция, доводящая баланс счёта до нужного.
+type AccountBalanceRecord struct {
+	Amount decimal.Decimal
+	Date   time.Time
+	Note   string
+}
+
+type AdjustAccountBalanceRequest struct {
+	Balance decimal.Decimal `json:"balance"`
+	Date    *time.Time      `json:"date,omitempty"`
+	Note    *string         `json:"note,omitempty"`
+}
+
+type AdjustAccountBalanceRecord struct {
+	AccountID uint64
+	Balance   decimal.Decimal
+	Date      time.Time
+	Note      string
+	CreatedAt time.Time
+}
+
+// AccountBalanceAdjustment — результат выставления баланса. Если баланс уже
+// совпадал, транзакция не создаётся и TransactionID пуст.
+type AccountBalanceAdjustment struct {
+	TransactionID   *uint64         `json:"transaction_id,omitempty"`
+	PreviousBalance decimal.Decimal `json:"previous_balance"`
+	Balance         decimal.Decimal `json:"balance"`
+	Difference      decimal.Decimal `json:"difference"`
 }
 
 type UpdateAccountRequest struct {
@@ -65,3 +132,48 @@ type UpdateAccountRecord struct {
 	OrderNum   *int
 	UpdatedAt  time.Time
 }
+
+// AccountDeleteMode — что делать с транзакциями удаляемого счёта.
+type AccountDeleteMode string
+
+const (
+	// Счёт не удаляется, а архивируется вместе с историей
+	AccountDeleteModeArchive AccountDeleteMode = "archive"
+	// Транзакции счёта уходят в корзину вместе с ним
+	AccountDeleteModeCascade AccountDeleteMode = "cascade"
+	// Транзакции переносятся на другой счёт
+	AccountDeleteModeReassign AccountDeleteMode = "reassign"
+)
+
+func (m AccountDeleteMode) IsValid() bool {
+	switch m {
+	case AccountDeleteModeArchive, AccountDeleteModeCascade, AccountDeleteModeReassign:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanTakeTransactionsOf сообщает, можно ли перенести на счёт транзакции удаляемого
+// счёта from. Счёт должен быть другим счётом того же владельца в той же валюте.
+// Инвестиционные и кредитные счета не подходят: их движения ведутся по позициям
+// и графику платежей, чужие транзакции их нарушат.
+func (a Account) CanTakeTransactionsOf(from Account) bool {
+	if a.ID == from.ID || a.UserID != from.UserID || a.Currency != from.Currency {
+		return false
+	}
+
+	return a.Type != AccountTypeInvestment && a.Type != AccountTypeLoan
+}
+
+type DeleteAccountRequest struct {
+	Mode            AccountDeleteMode `form:"mode"`
+	TargetAccountID *uint64           `form:"target_account_id"`
+}
+
+type DeleteAccountRecord struct {
+	ID              uint64
+	Mode            AccountDeleteMode
+	TargetAccountID *uint64
+	DeletedAt       time.Time
+}
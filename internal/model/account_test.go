@@ -0,0 +1,29 @@
+package model
+
+import "testing"
+
+func TestAccountCanTakeTransactionsOf(t *testing.T) {
+	from := Account{ID: 1, UserID: 10, Type: AccountTypeBank, Currency: "RUB"}
+
+	tests := []struct {
+		name   string
+		target Account
+		want   bool
+	}{
+		{name: "счёт того же владельца в той же валюте", target: Account{ID: 2, UserID: 10, Type: AccountTypeCash, Currency: "RUB"}, want: true},
+		{name: "кредитная карта подходит", target: Account{ID: 2, UserID: 10, Type: AccountTypeCredit, Currency: "RUB"}, want: true},
+		{name: "тот же счёт", target: from},
+		{name: "чужой счёт", target: Account{ID: 2, UserID: 11, Type: AccountTypeBank, Currency: "RUB"}},
+		{name: "другая валюта", target: Account{ID: 2, UserID: 10, Type: AccountTypeBank, Currency: "USD"}},
+		{name: "инвестиционный счёт", target: Account{ID: 2, UserID: 10, Type: AccountTypeInvestment, Currency: "RUB"}},
+		{name: "кредит", target: Account{ID: 2, UserID: 10, Type: AccountTypeLoan, Currency: "RUB"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.target.CanTakeTransactionsOf(from); got != tt.want {
+				t.Errorf("CanTakeTransactionsOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -14,6 +14,10 @@ type BudgetAllocation struct {
 	Assigned   decimal.Decimal `json:"assigned" db:"assigned"`
 	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+
+	// Версия изменения и ID, выданный клиентом при офлайн-создании
+	Version  int64   `json:"version" db:"version"`
+	ClientID *string `json:"client_id,omitempty" db:"client_id"`
 }
 
 type CreateBudgetAllocationRecord struct {
@@ -38,3 +42,72 @@ type CreateBudgetAllocationRequest struct {
 	Month      uint            `json:"month" binding:"required"`
 	Assigned   decimal.Decimal `json:"assigned" binding:"required"`
 }
+
+type AssignBudgetRequest struct {
+	CategoryID uint64          `json:"category_id" binding:"required"`
+	Amount     decimal.Decimal `json:"amount"`
+}
+
+type AssignBudgetRecord struct {
+	UserID     uint64
+	CategoryID uint64
+	Year       uint
+	Month      uint
+	Amount     decimal.Decimal
+	UpdatedAt  time.Time
+}
+
+type QuickAssignSource string
+
+const (
+	QuickAssignSourceLastMonthAssigned QuickAssignSource = "last_month_assigned"
+	QuickAssignSourceLastMonthSpent    QuickAssignSource = "last_month_spent"
+)
+
+type QuickAssignRequest struct {
+	Source QuickAssignSource `json:"source" binding:"required"`
+}
+
+type QuickAssignRecord struct {
+	UserID      uint64
+	Year        uint
+	Month       uint
+	Source      QuickAssignSource
+	SourceYear  uint
+	SourceMonth uint
+	UpdatedAt   time.Time
+}
+
+type QuickAssignResult struct {
+	CategoriesUpdated int `json:"categories_updated"`
+}
+
+type BudgetMove struct {
+	ID             uint64          `json:"id" db:"id"`
+	UserID         uint64          `json:"user_id" db:"user_id"`
+	Year           uint            `json:"year" db:"year"`
+	Month          uint            `json:"month" db:"month"`
+	FromCategoryID uint64          `json:"from_category_id" db:"from_category_id"`
+	ToCategoryID   uint64          `json:"to_category_id" db:"to_category_id"`
+	Amount         decimal.Decimal `json:"amount" db:"amount"`
+	Note           string          `json:"note" db:"note"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
+
+type MoveBudgetRequest struct {
+	FromCategoryID uint64          `json:"from_category_id" binding:"required"`
+	ToCategoryID   uint64          `json:"to_category_id" binding:"required"`
+	Amount         decimal.Decimal `json:"amount"`
+	Note           string          `json:"note"`
+}
+
+type CreateBudgetMoveRecord struct {
+	UserID         uint64
+	Year           uint
+	Month          uint
+	FromCategoryID uint64
+	ToCategoryID   uint64
+	Amount         decimal.Decimal
+	Note           string
+	CreatedAt      time.Time
+}
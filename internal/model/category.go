@@ -6,50 +6,173 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+type CategoryTargetType string
+
+const (
+	// Назначать фиксированную сумму каждый месяц
+	CategoryTargetMonthlyFunding CategoryTargetType = "monthly_funding"
+	// Держать в категории не меньше суммы
+	CategoryTargetBalance CategoryTargetType = "target_balance"
+	// Накопить сумму к дате, равномерно по месяцам
+	CategoryTargetBalanceByDate CategoryTargetType = "target_balance_by_date"
+	// Покрывать траты месяца, но не больше суммы
+	CategoryTargetSpendingCap CategoryTargetType = "spending_cap"
+)
+
+func (t CategoryTargetType) IsValid() bool {
+	switch t {
+	case CategoryTargetMonthlyFunding, CategoryTargetBalance, CategoryTargetBalanceByDate, CategoryTargetSpendingCap:
+		return true
+	default:
+		return false
+	}
+}
+
+type CategoryTarget struct {
+	Type   CategoryTargetType `json:"type"`
+	Amount decimal.Decimal    `json:"amount"`
+	Date   *time.Time         `json:"date,omitempty"`
+}
+
 type Category struct {
-	ID        uint64    `json:"id" db:"id"`
-	UserID    uint64    `json:"user_id" db:"user_id"`
-	Name      string    `json:"name" db:"name"`
-	GroupName string    `json:"group_name" db:"group_name"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID           uint64              `json:"id" db:"id"`
+	UserID       uint64              `json:"user_id" db:"user_id"`
+	Name         string              `json:"name" db:"name"`
+	GroupID      *uint64             `json:"group_id" db:"group_id"`
+	SortOrder    int                 `json:"sort_order" db:"sort_order"`
+	IsHidden     bool                `json:"is_hidden" db:"is_hidden"`
+	TargetType   *CategoryTargetType `json:"target_type,omitempty" db:"target_type"`
+	TargetAmount *decimal.Decimal    `json:"target_amount,omitempty" db:"target_amount"`
+	TargetDate   *time.Time          `json:"target_date,omitempty" db:"target_date"`
+	CreatedAt    time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at" db:"updated_at"`
+
+	// Кредитная карта, платёж по которой копится в категории. Такие категории
+	// создаются и удаляются вместе со счётом карты.
+	PaymentAccountID *uint64 `json:"payment_account_id,omitempty" db:"payment_account_id"`
+
+	// Версия изменения и ID, выданный клиентом при офлайн-создании
+	Version  int64   `json:"version" db:"version"`
+	ClientID *string `json:"client_id,omitempty" db:"client_id"`
+
+	// Время переноса в корзину, у активных записей пусто
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+func (c Category) Target() *CategoryTarget {
+	if c.TargetType == nil || c.TargetAmount == nil {
+		return nil
+	}
+
+	return &CategoryTarget{
+		Type:   *c.TargetType,
+		Amount: *c.TargetAmount,
+		Date:   c.TargetDate,
+	}
 }
 
 type CategoryBudget struct {
 	CategoryID  int64   `json:"category_id" db:"category_id"`
 	Name        string  `json:"name" db:"category_name"`
-	GroupName   string  `json:"group_name" db:"category_group_name"`
+	IsHidden    bool    `json:"is_hidden" db:"is_hidden"`
 	Assigned    float64 `json:"assigned" db:"assigned"`
 	Spent       float64 `json:"spent" db:"spent"`
 	Available   float64 `json:"available" db:"available"`
 	CarriedOver float64 `json:"carried_over" db:"carried_over"`
+
+	Target *CategoryTarget `json:"target,omitempty" db:"-"`
+	// Сколько ещё нужно назначить в этом месяце, чтобы идти по цели
+	Needed float64 `json:"needed" db:"-"`
+	// У категории платежа по кредитной карте — счёт карты
+	PaymentAccountID *uint64 `json:"payment_account_id,omitempty" db:"-"`
 }
 
 type CategoryBudgetResponse struct {
-	ToBeBudgeted decimal.Decimal `json:"to_be_budgeted"`
-	Categories []CategoryBudget `json:"categories"`
+	// Базовая валюта, в которую пересчитаны суммы
+	Currency     Currency              `json:"currency"`
+	ToBeBudgeted decimal.Decimal       `json:"to_be_budgeted"`
+	Underfunded  decimal.Decimal       `json:"underfunded"`
+	Groups       []CategoryGroupBudget `json:"groups"`
+	// Карты, долг по которым больше доступного в категории платежа
+	CreditCardWarnings []CreditCardWarning `json:"credit_card_warnings"`
+	// Валюты счетов без курса к базовой валюте: их суммы в ответ не вошли
+	MissingRates []Currency `json:"missing_rates,omitempty"`
+}
+
+// CreditCardWarning — долг по карте на конец месяца, не покрытый категорией платежа.
+type CreditCardWarning struct {
+	AccountID   uint64          `json:"account_id"`
+	AccountName string          `json:"account_name"`
+	CategoryID  uint64          `json:"category_id"`
+	Debt        decimal.Decimal `json:"debt"`
+	Available   decimal.Decimal `json:"available"`
+	Shortfall   decimal.Decimal `json:"shortfall"`
 }
 
 type CreateCategoryRequest struct {
-	Name      string `json:"name" binding:"required"`
-	GroupName string `json:"group_name"`
+	Name     string          `json:"name" binding:"required"`
+	GroupID  *uint64         `json:"group_id"`
+	IsHidden bool            `json:"is_hidden"`
+	Target   *CategoryTarget `json:"target,omitempty"`
 }
 
 type UpdateCategoryRequest struct {
-	Name      *string `json:"name"`
-	GroupName *string `json:"group_name"`
+	Name *string `json:"name"`
+	// Передача group_id переносит категорию в конец группы, remove_group убирает её из группы
+	GroupID     *uint64 `json:"group_id"`
+	RemoveGroup bool    `json:"remove_group,omitempty"`
+	IsHidden    *bool   `json:"is_hidden"`
+	// Передача target заменяет цель, remove_target удаляет её
+	Target       *CategoryTarget `json:"target,omitempty"`
+	RemoveTarget bool            `json:"remove_target,omitempty"`
 }
 
 type UpdateCategoryRecord struct {
-	Name      *string
-	GroupName *string
-	UpdatedAt time.Time
+	Name         *string
+	GroupID      *uint64
+	RemoveGroup  bool
+	IsHidden     *bool
+	Target       *CategoryTarget
+	RemoveTarget bool
+	UpdatedAt    time.Time
 }
 
 type CreateCategoryRecord struct {
 	UserID    uint64
 	Name      string
-	GroupName string
+	GroupID   *uint64
+	IsHidden  bool
+	Target    *CategoryTarget
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
+
+// CategoryUsage — сколько записей ссылается на категорию. Транзакция с разбивкой
+// считается один раз, даже если категория встречается в нескольких строках.
+// Правила и получатели учитываются, чтобы удалённая категория не осталась
+// в их подстановках.
+type CategoryUsage struct {
+	Transactions int `json:"transactions" db:"transactions"`
+	Allocations  int `json:"allocations" db:"allocations"`
+	Rules        int `json:"rules" db:"rules"`
+	Payees       int `json:"payees" db:"payees"`
+}
+
+func (u CategoryUsage) IsUsed() bool {
+	return u.Transactions > 0 || u.Allocations > 0 || u.Rules > 0 || u.Payees > 0
+}
+
+type DeleteCategoryRequest struct {
+	// Категория, в которую переносится история удаляемой
+	ReassignTo *uint64 `form:"reassign_to"`
+}
+
+type MergeCategoryRequest struct {
+	IntoCategoryID uint64 `json:"into_category_id" binding:"required"`
+}
+
+type ReassignCategoryRecord struct {
+	FromID    uint64
+	ToID      uint64
+	DeletedAt time.Time
+}
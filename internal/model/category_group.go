@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+)
+
+type CategoryGroup struct {
+	ID        uint64    `json:"id" db:"id"`
+	UserID    uint64    `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	SortOrder int       `json:"sort_order" db:"sort_order"`
+	IsHidden  bool      `json:"is_hidden" db:"is_hidden"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Версия изменения и ID, выданный клиентом при офлайн-создании
+	Version  int64   `json:"version" db:"version"`
+	ClientID *string `json:"client_id,omitempty" db:"client_id"`
+}
+
+type CreateCategoryGroupRequest struct {
+	Name     string `json:"name" binding:"required"`
+	IsHidden bool   `json:"is_hidden"`
+}
+
+type UpdateCategoryGroupRequest struct {
+	Name     *string `json:"name"`
+	IsHidden *bool   `json:"is_hidden"`
+}
+
+type CreateCategoryGroupRecord struct {
+	UserID    uint64
+	Name      string
+	IsHidden  bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type UpdateCategoryGroupRecord struct {
+	Name      *string
+	IsHidden  *bool
+	UpdatedAt time.Time
+}
+
+// ReorderCategoryGroupsRequest — группы пользователя в новом порядке.
+type ReorderCategoryGroupsRequest struct {
+	GroupIDs []uint64 `json:"group_ids" binding:"required"`
+}
+
+// ReorderCategoriesRequest — категории группы в новом порядке. Категория из
+// другой группы при этом переносится в group_id, пустой group_id — без группы.
+type ReorderCategoriesRequest struct {
+	GroupID     *uint64  `json:"group_id"`
+	CategoryIDs []uint64 `json:"category_ids" binding:"required"`
+}
+
+type ReorderCategoriesRecord struct {
+	GroupID     *uint64
+	CategoryIDs []uint64
+	UpdatedAt   time.Time
+}
+
+type ReorderCategoryGroupsRecord struct {
+	GroupIDs  []uint64
+	UpdatedAt time.Time
+}
+
+// CategoryGroupBudget — группа на экране бюджета с итогами по её категориям.
+// У категорий без группы GroupID пустой.
+type CategoryGroupBudget struct {
+	GroupID    *uint64          `json:"group_id"`
+	Name       string           `json:"name"`
+	IsHidden   bool             `json:"is_hidden"`
+	Assigned   float64          `json:"assigned"`
+	Spent      float64          `json:"spent"`
+	Available  float64          `json:"available"`
+	Needed     float64          `json:"needed"`
+	Categories []CategoryBudget `json:"categories"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "testing"
+
+func TestCategoryUsageIsUsed(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage CategoryUsage
+		want  bool
+	}{
+		{name: "ничего не ссылается"},
+		{name: "транзакции", usage: CategoryUsage{Transactions: 1}, want: true},
+		{name: "назначения", usage: CategoryUsage{Allocations: 1}, want: true},
+		{name: "правило подставляет категорию", usage: CategoryUsage{Rules: 1}, want: true},
+		{name: "последняя категория получателя", usage: CategoryUsage{Payees: 1}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.usage.IsUsed(); got != tt.want {
+				t.Errorf("IsUsed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Currency — трёхбуквенный код валюты ISO 4217 в верхнем регистре.
+type Currency string
+
+// DefaultCurrency — валюта счетов и базовая валюта пользователей по умолчанию.
+const DefaultCurrency Currency = "RUB"
+
+func (c Currency) IsValid() bool {
+	if len(c) != 3 {
+		return false
+	}
+
+	for _, r := range c {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ExchangeRate — одна единица FromCurrency стоит Rate единиц ToCurrency на дату.
+// Для пересчёта подходит и обратная пара.
+type ExchangeRate struct {
+	ID           uint64          `json:"id" db:"id"`
+	UserID       uint64          `json:"user_id" db:"user_id"`
+	FromCurrency Currency        `json:"from_currency" db:"from_currency"`
+	ToCurrency   Currency        `json:"to_currency" db:"to_currency"`
+	Rate         decimal.Decimal `json:"rate" db:"rate"`
+	Date         time.Time       `json:"date" db:"date"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+}
+
+type CreateExchangeRateRequest struct {
+	FromCurrency Currency        `json:"from_currency" binding:"required"`
+	ToCurrency   Currency        `json:"to_currency" binding:"required"`
+	Rate         decimal.Decimal `json:"rate" binding:"required"`
+	Date         time.Time       `json:"date" binding:"required"`
+}
+
+func (r CreateExchangeRateRequest) IsValid() bool {
+	return r.FromCurrency.IsValid() && r.ToCurrency.IsValid() &&
+		r.FromCurrency != r.ToCurrency && r.Rate.IsPositive()
+}
+
+// CreateExchangeRateRecord — повторный курс той же пары на ту же дату заменяет прежний.
+type CreateExchangeRateRecord struct {
+	UserID       uint64
+	FromCurrency Currency
+	ToCurrency   Currency
+	Rate         decimal.Decimal
+	Date         time.Time
+	CreatedAt    time.Time
+}
+
+type ExchangeRateImportResult struct {
+	RatesImported int      `json:"rates_imported"`
+	Errors        []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCurrencyIsValid(t *testing.T) {
+	tests := map[Currency]bool{
+		"RUB":  true,
+		"USD":  true,
+		"usd":  false,
+		"RU":   false,
+		"EURO": false,
+		"US1":  false,
+		"":     false,
+	}
+
+	for currency, want := range tests {
+		if got := currency.IsValid(); got != want {
+			t.Errorf("Currency(%q).IsValid() = %v, want %v", currency, got, want)
+		}
+	}
+}
+
+func TestCreateExchangeRateRequestIsValid(t *testing.T) {
+	valid := CreateExchangeRateRequest{FromCurrency: "USD", ToCurrency: "RUB", Rate: decimal.NewFromFloat(92.5)}
+	if !valid.IsValid() {
+		t.Error("valid rate rejected")
+	}
+
+	same := valid
+	same.ToCurrency = "USD"
+	if same.IsValid() {
+		t.Error("rate between the same currency accepted")
+	}
+
+	zero := valid
+	zero.Rate = decimal.Zero
+	if zero.IsValid() {
+		t.Error("zero rate accepted")
+	}
+}
@@ -6,6 +6,8 @@ type ExcelColumnMapping struct {
 	TransactionAmount      string  `json:"transaction_amount,omitempty"`      // столбец для суммы транзакции
 	TransactionDate        *string `json:"transaction_date,omitempty"`        // столбец для даты транзакции
 	TransactionCategory    *string `json:"transaction_category,omitempty"`    // столбец для категории транзакции
+	TransactionType        *string `json:"transaction_type,omitempty"`        // столбец для типа транзакции (доход/расход)
+	TransactionPayee       *string `json:"transaction_payee,omitempty"`       // столбец для получателя транзакции
 
 	// Категории
 	CategoryName *string `json:"category_name,omitempty"` // столбец для названия категории
@@ -21,13 +23,16 @@ type ExcelFileStructure struct {
 	Rows    int      `json:"rows"`    // количество строк данных (без заголовка)
 }
 
+// ImportRequest — без AccountID транзакции попадают на первый бюджетный счёт пользователя.
 type ImportRequest struct {
-	Mapping ExcelColumnMapping `json:"mapping" binding:"required"`
+	AccountID *uint64            `json:"account_id,omitempty"`
+	Mapping   ExcelColumnMapping `json:"mapping" binding:"required"`
 }
 
 type ImportResult struct {
 	TransactionsCreated int      `json:"transactions_created"`
 	CategoriesCreated   int      `json:"categories_created"`
 	BudgetsCreated      int      `json:"budgets_created"`
+	RulesApplied        int      `json:"rules_applied"`
 	Errors              []string `json:"errors,omitempty"`
 }
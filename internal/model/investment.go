@@ -0,0 +1,212 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type InvestmentActivityKind string
+
+const (
+	InvestmentActivityBuy      InvestmentActivityKind = "buy"
+	InvestmentActivitySell     InvestmentActivityKind = "sell"
+	InvestmentActivityDividend InvestmentActivityKind = "dividend"
+)
+
+func (k InvestmentActivityKind) IsValid() bool {
+	switch k {
+	case InvestmentActivityBuy, InvestmentActivitySell, InvestmentActivityDividend:
+		return true
+	}
+	return false
+}
+
+// InvestmentActivity — сделка или дивиденд по счёту типа investment. Amount —
+// движение денег по счёту, оно же проведено транзакцией TransactionID.
+type InvestmentActivity struct {
+	ID            uint64                 `json:"id" db:"id"`
+	UserID        uint64                 `json:"user_id" db:"user_id"`
+	AccountID     uint64                 `json:"account_id" db:"account_id"`
+	TransactionID uint64                 `json:"transaction_id" db:"transaction_id"`
+	Ticker        string                 `json:"ticker" db:"ticker"`
+	Kind          InvestmentActivityKind `json:"kind" db:"kind"`
+	Quantity      decimal.Decimal        `json:"quantity" db:"quantity"`
+	Price         decimal.Decimal        `json:"price" db:"price"`
+	Fee           decimal.Decimal        `json:"fee" db:"fee"`
+	Amount        decimal.Decimal        `json:"amount" db:"amount"`
+	RealizedGain  decimal.Decimal        `json:"realized_gain" db:"realized_gain"`
+	Date          time.Time              `json:"date" db:"date"`
+	CreatedAt     time.Time              `json:"created_at" db:"created_at"`
+}
+
+// CreateInvestmentActivityRequest — для buy и sell нужны quantity и price, для
+// dividend — amount.
+type CreateInvestmentActivityRequest struct {
+	Ticker   string                 `json:"ticker" binding:"required"`
+	Kind     InvestmentActivityKind `json:"kind" binding:"required"`
+	Quantity decimal.Decimal        `json:"quantity"`
+	Price    decimal.Decimal        `json:"price"`
+	Fee      decimal.Decimal        `json:"fee"`
+	Amount   decimal.Decimal        `json:"amount"`
+	Date     time.Time              `json:"date" binding:"required"`
+	Note     string                 `json:"note"`
+}
+
+func (r CreateInvestmentActivityRequest) IsValid() bool {
+	if !r.Kind.IsValid() {
+		return false
+	}
+
+	if r.Kind == InvestmentActivityDividend {
+		return r.Amount.IsPositive()
+	}
+
+	return r.Quantity.IsPositive() && !r.Price.IsNegative() && !r.Fee.IsNegative()
+}
+
+type CreateInvestmentActivityRecord struct {
+	UserID    uint64
+	AccountID uint64
+	Ticker    string
+	Kind      InvestmentActivityKind
+	Quantity  decimal.Decimal
+	Price     decimal.Decimal
+	Fee       decimal.Decimal
+	Amount    decimal.Decimal
+	Date      time.Time
+	Note      string
+	CreatedAt time.Time
+}
+
+// InvestmentActivityResult — пустая Activity значит, что продаётся больше бумаг,
+// чем есть на счёте, и ничего не проведено.
+type InvestmentActivityResult struct {
+	Activity *InvestmentActivity `json:"activity"`
+	Holding  InvestmentHolding   `json:"holding"`
+}
+
+// InvestmentHolding — позиция по бумаге. Себестоимость считается по средней
+// цене покупки, вместе с комиссиями.
+type InvestmentHolding struct {
+	AccountID uint64          `json:"account_id" db:"account_id"`
+	Ticker    string          `json:"ticker" db:"ticker"`
+	Quantity  decimal.Decimal `json:"quantity" db:"quantity"`
+	CostBasis decimal.Decimal `json:"cost_basis" db:"cost_basis"`
+}
+
+// Apply возвращает позицию после операции, движение денег по счёту и
+// реализованную прибыль. ok ложно, если продаётся больше, чем есть.
+func (h InvestmentHolding) Apply(record CreateInvestmentActivityRecord) (holding InvestmentHolding, amount decimal.Decimal, realizedGain decimal.Decimal, ok bool) {
+	holding = h
+
+	switch record.Kind {
+	case InvestmentActivityBuy:
+		cost := record.Quantity.Mul(record.Price).Add(record.Fee).Round(2)
+		holding.Quantity = holding.Quantity.Add(record.Quantity)
+		holding.CostBasis = holding.CostBasis.Add(cost)
+		return holding, cost.Neg(), decimal.Zero, true
+	case InvestmentActivitySell:
+		if record.Quantity.GreaterThan(holding.Quantity) {
+			return h, decimal.Zero, decimal.Zero, false
+		}
+
+		soldBasis := holding.CostBasis
+		if record.Quantity.LessThan(holding.Quantity) {
+			soldBasis = holding.CostBasis.Mul(record.Quantity).Div(holding.Quantity).Round(2)
+		}
+		proceeds := record.Quantity.Mul(record.Price).Sub(record.Fee).Round(2)
+
+		holding.Quantity = holding.Quantity.Sub(record.Quantity)
+		holding.CostBasis = holding.CostBasis.Sub(soldBasis)
+		return holding, proceeds, proceeds.Sub(soldBasis), true
+	default:
+		return holding, record.Amount, decimal.Zero, true
+	}
+}
+
+// HoldingValuation — позиция, оценённая по последнему снимку цены. Без снимка
+// позиция оценивается по себестоимости.
+type HoldingValuation struct {
+	InvestmentHolding
+	Price          *decimal.Decimal `json:"price" db:"price"`
+	PriceDate      *time.Time       `json:"price_date" db:"price_date"`
+	MarketValue    decimal.Decimal  `json:"market_value" db:"-"`
+	UnrealizedGain decimal.Decimal  `json:"unrealized_gain" db:"-"`
+}
+
+func (v HoldingValuation) Valued() HoldingValuation {
+	v.MarketValue = v.CostBasis
+	if v.Price != nil {
+		v.MarketValue = v.Quantity.Mul(*v.Price).Round(2)
+	}
+	v.UnrealizedGain = v.MarketValue.Sub(v.CostBasis)
+
+	return v
+}
+
+// InvestmentSummary — оценка инвестиционного счёта: свободные деньги на счёте и
+// бумаги по рыночной цене.
+type InvestmentSummary struct {
+	AccountID      uint64             `json:"account_id"`
+	Cash           decimal.Decimal    `json:"cash"`
+	MarketValue    decimal.Decimal    `json:"market_value"`
+	TotalValue     decimal.Decimal    `json:"total_value"`
+	CostBasis      decimal.Decimal    `json:"cost_basis"`
+	UnrealizedGain decimal.Decimal    `json:"unrealized_gain"`
+	RealizedGain   decimal.Decimal    `json:"realized_gain"`
+	Dividends      decimal.Decimal    `json:"dividends"`
+	Holdings       []HoldingValuation `json:"holdings"`
+}
+
+// InvestmentIncome — реализованная прибыль и дивиденды по счёту.
+type InvestmentIncome struct {
+	RealizedGain decimal.Decimal `db:"realized_gain"`
+	Dividends    decimal.Decimal `db:"dividends"`
+}
+
+func NewInvestmentSummary(accountID uint64, cash decimal.Decimal, holdings []HoldingValuation, income InvestmentIncome) InvestmentSummary {
+	summary := InvestmentSummary{
+		AccountID:    accountID,
+		Cash:         cash,
+		RealizedGain: income.RealizedGain,
+		Dividends:    income.Dividends,
+		Holdings:     make([]HoldingValuation, 0, len(holdings)),
+	}
+
+	for _, holding := range holdings {
+		holding = holding.Valued()
+		summary.MarketValue = summary.MarketValue.Add(holding.MarketValue)
+		summary.CostBasis = summary.CostBasis.Add(holding.CostBasis)
+		summary.UnrealizedGain = summary.UnrealizedGain.Add(holding.UnrealizedGain)
+		summary.Holdings = append(summary.Holdings, holding)
+	}
+	summary.TotalValue = summary.Cash.Add(summary.MarketValue)
+
+	return summary
+}
+
+// SecurityPrice — цена бумаги на дату, введённая вручную.
+type SecurityPrice struct {
+	ID        uint64          `json:"id" db:"id"`
+	UserID    uint64          `json:"user_id" db:"user_id"`
+	Ticker    string          `json:"ticker" db:"ticker"`
+	Price     decimal.Decimal `json:"price" db:"price"`
+	Date      time.Time       `json:"date" db:"date"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+type CreateSecurityPriceRequest struct {
+	Ticker string          `json:"ticker" binding:"required"`
+	Price  decimal.Decimal `json:"price" binding:"required"`
+	Date   time.Time       `json:"date" binding:"required"`
+}
+
+// CreateSecurityPriceRecord — повторный снимок на ту же дату заменяет цену.
+type CreateSecurityPriceRecord struct {
+	UserID    uint64
+	Ticker    string
+	Price     decimal.Decimal
+	Date      time.Time
+	CreatedAt time.Time
+}
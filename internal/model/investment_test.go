@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestInvestmentHoldingApply(t *testing.T) {
+	holding := InvestmentHolding{Ticker: "SBER"}
+	trade := func(kind InvestmentActivityKind, quantity int64, price int64, fee int64) CreateInvestmentActivityRecord {
+		return CreateInvestmentActivityRecord{
+			Kind:     kind,
+			Quantity: decimal.NewFromInt(quantity),
+			Price:    decimal.NewFromInt(price),
+			Fee:      decimal.NewFromInt(fee),
+		}
+	}
+
+	// Две покупки по разной цене дают среднюю себестоимость 110 за бумагу
+	holding, amount, _, _ := holding.Apply(trade(InvestmentActivityBuy, 10, 100, 0))
+	if amount.String() != "-1000" {
+		t.Errorf("buy amount = %s, want -1000", amount)
+	}
+	holding, _, _, _ = holding.Apply(trade(InvestmentActivityBuy, 10, 120, 0))
+
+	holding, amount, gain, ok := holding.Apply(trade(InvestmentActivitySell, 5, 150, 10))
+	if !ok {
+		t.Fatal("sell within holding rejected")
+	}
+	if amount.String() != "740" || gain.String() != "190" {
+		t.Errorf("sell amount = %s, gain = %s, want 740 and 190", amount, gain)
+	}
+	if holding.Quantity.String() != "15" || holding.CostBasis.String() != "1650" {
+		t.Errorf("holding = %s for %s, want 15 for 1650", holding.Quantity, holding.CostBasis)
+	}
+
+	if _, _, _, ok := holding.Apply(trade(InvestmentActivitySell, 16, 150, 0)); ok {
+		t.Error("sell above holding accepted")
+	}
+
+	dividend := CreateInvestmentActivityRecord{Kind: InvestmentActivityDividend, Amount: decimal.NewFromInt(42)}
+	after, amount, _, _ := holding.Apply(dividend)
+	if amount.String() != "42" || !after.Quantity.Equal(holding.Quantity) {
+		t.Errorf("dividend amount = %s, quantity = %s, want 42 and unchanged", amount, after.Quantity)
+	}
+}
+
+func TestNewInvestmentSummary(t *testing.T) {
+	price := decimal.NewFromInt(130)
+	priceDate := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	holdings := []HoldingValuation{
+		{
+			InvestmentHolding: InvestmentHolding{Ticker: "SBER", Quantity: decimal.NewFromInt(10), CostBasis: decimal.NewFromInt(1100)},
+			Price:             &price,
+			PriceDate:         &priceDate,
+		},
+		// Без снимка цены позиция оценивается по себестоимости
+		{InvestmentHolding: InvestmentHolding{Ticker: "GAZP", Quantity: decimal.NewFromInt(5), CostBasis: decimal.NewFromInt(800)}},
+	}
+
+	summary := NewInvestmentSummary(1, decimal.NewFromInt(500), holdings, InvestmentIncome{RealizedGain: decimal.NewFromInt(190)})
+
+	if summary.MarketValue.String() != "2100" || summary.UnrealizedGain.String() != "200" || summary.TotalValue.String() != "2600" {
+		t.Errorf("summary = market %s, unrealized %s, total %s, want 2100, 200, 2600",
+			summary.MarketValue, summary.UnrealizedGain, summary.TotalValue)
+	}
+}
+
+func TestNewNetWorth(t *testing.T) {
+	netWorth := NewNetWorth(DefaultCurrency, []NetWorthAccount{
+		{AccountID: 1, Type: AccountTypeBank, Value: decimal.NewNullDecimal(decimal.NewFromInt(1000))},
+		{AccountID: 2, Type: AccountTypeInvestment, Value: decimal.NewNullDecimal(decimal.NewFromInt(2600))},
+		{AccountID: 3, Type: AccountTypeLoan, Value: decimal.NewNullDecimal(decimal.NewFromInt(-3000))},
+		// Счёт в валюте без курса
+		{AccountID: 4, Type: AccountTypeBank, Currency: "USD", Balance: decimal.NewFromInt(500)},
+	})
+
+	if netWorth.Assets.String() != "3600" || netWorth.Liabilities.String() != "3000" || netWorth.NetWorth.String() != "600" {
+		t.Errorf("net worth = assets %s, liabilities %s, net %s, want 3600, 3000, 600",
+			netWorth.Assets, netWorth.Liabilities, netWorth.NetWorth)
+	}
+	if len(netWorth.Accounts) != 4 {
+		t.Errorf("accounts = %d, want 4 including the account without rate", len(netWorth.Accounts))
+	}
+}
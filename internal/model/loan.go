@@ -0,0 +1,247 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxLoanMonths ограничивает график, если платёж не покрывает проценты.
+const maxLoanMonths = 1200
+
+// Loan — условия кредита или ипотеки, привязанные к счёту типа loan. Баланс
+// такого счёта отрицательный и равен остатку основного долга.
+type Loan struct {
+	AccountID uint64          `json:"account_id" db:"account_id"`
+	Principal decimal.Decimal `json:"principal" db:"principal"`
+	// Годовая ставка в процентах
+	InterestRate decimal.Decimal `json:"interest_rate" db:"interest_rate"`
+	TermMonths   int             `json:"term_months" db:"term_months"`
+	PaymentDay   int             `json:"payment_day" db:"payment_day"`
+	StartDate    time.Time       `json:"start_date" db:"start_date"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+type LoanTermsRequest struct {
+	Principal    decimal.Decimal `json:"principal" binding:"required"`
+	InterestRate decimal.Decimal `json:"interest_rate"`
+	TermMonths   int             `json:"term_months" binding:"required"`
+	PaymentDay   int             `json:"payment_day" binding:"required"`
+	StartDate    time.Time       `json:"start_date" binding:"required"`
+}
+
+func (r LoanTermsRequest) IsValid() bool {
+	return r.Principal.IsPositive() && !r.InterestRate.IsNegative() && r.TermMonths > 0 && r.PaymentDay >= 1 && r.PaymentDay <= 31
+}
+
+type LoanTermsRecord struct {
+	Principal    decimal.Decimal
+	InterestRate decimal.Decimal
+	TermMonths   int
+	PaymentDay   int
+	StartDate    time.Time
+}
+
+// UpdateLoanRequest меняет условия, например ставку после рефинансирования.
+// Уже проведённые платежи не пересчитываются.
+type UpdateLoanRequest struct {
+	InterestRate *decimal.Decimal `json:"interest_rate,omitempty"`
+	TermMonths   *int             `json:"term_months,omitempty"`
+	PaymentDay   *int             `json:"payment_day,omitempty"`
+}
+
+type UpdateLoanRecord struct {
+	InterestRate *decimal.Decimal
+	TermMonths   *int
+	PaymentDay   *int
+	UpdatedAt    time.Time
+}
+
+// LoanPaymentRequest — платёж по кредиту с другого счёта. Категория ставится на
+// бюджетную половину, как у перевода на внебюджетный счёт.
+type LoanPaymentRequest struct {
+	FromAccountID uint64          `json:"from_account_id" binding:"required"`
+	Amount        decimal.Decimal `json:"amount" binding:"required"`
+	Date          time.Time       `json:"date" binding:"required"`
+	Note          string          `json:"note"`
+	CategoryID    *uint64         `json:"category_id,omitempty"`
+	IsCleared     bool            `json:"is_cleared"`
+}
+
+type LoanPaymentRecord struct {
+	UserID        uint64
+	Loan          Loan
+	FromAccountID uint64
+	Amount        decimal.Decimal
+	Date          time.Time
+	Note          string
+	InterestNote  string
+	CategoryID    *uint64
+	IsCleared     bool
+	CreatedAt     time.Time
+}
+
+// LoanPayment — проведённый платёж, разделённый на основной долг и проценты.
+// Проценты проводятся отдельной транзакцией по счёту кредита.
+type LoanPayment struct {
+	TransactionID         uint64          `json:"transaction_id" db:"transaction_id"`
+	FromTransactionID     uint64          `json:"from_transaction_id" db:"from_transaction_id"`
+	InterestTransactionID *uint64         `json:"interest_transaction_id,omitempty" db:"interest_transaction_id"`
+	Date                  time.Time       `json:"date" db:"date"`
+	Amount                decimal.Decimal `json:"amount" db:"amount"`
+	Principal             decimal.Decimal `json:"principal" db:"principal"`
+	Interest              decimal.Decimal `json:"interest" db:"interest"`
+}
+
+// LoanDetails — условия кредита с текущим остатком долга.
+type LoanDetails struct {
+	Loan
+	Debt           decimal.Decimal `json:"debt"`
+	MonthlyPayment decimal.Decimal `json:"monthly_payment"`
+}
+
+type LoanScheduleRow struct {
+	Number    int             `json:"number"`
+	Date      time.Time       `json:"date"`
+	Payment   decimal.Decimal `json:"payment"`
+	Principal decimal.Decimal `json:"principal"`
+	Interest  decimal.Decimal `json:"interest"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+// LoanSchedule — график платежей. Если платёж не покрывает проценты, долг не
+// гасится и PayoffDate пустая.
+type LoanSchedule struct {
+	MonthlyPayment decimal.Decimal   `json:"monthly_payment"`
+	ExtraPayment   decimal.Decimal   `json:"extra_payment"`
+	TotalInterest  decimal.Decimal   `json:"total_interest"`
+	PayoffDate     *time.Time        `json:"payoff_date"`
+	Rows           []LoanScheduleRow `json:"rows"`
+}
+
+// LoanProjection — прогноз погашения от текущего долга: с обычным платежом и,
+// если задан, с ежемесячным досрочным платежом сверх него.
+type LoanProjection struct {
+	Debt          decimal.Decimal `json:"debt"`
+	Regular       LoanSchedule    `json:"regular"`
+	WithExtra     *LoanSchedule   `json:"with_extra,omitempty"`
+	MonthsSaved   int             `json:"months_saved"`
+	InterestSaved decimal.Decimal `json:"interest_saved"`
+}
+
+func (l Loan) monthlyRate() decimal.Decimal {
+	return l.InterestRate.Div(decimal.NewFromInt(1200))
+}
+
+// MonthlyPayment — аннуитетный платёж по исходным условиям.
+func (l Loan) MonthlyPayment() decimal.Decimal {
+	months := decimal.NewFromInt(int64(l.TermMonths))
+	rate := l.monthlyRate()
+	if rate.IsZero() {
+		return l.Principal.Div(months).RoundCeil(2)
+	}
+
+	factor := decimal.NewFromInt(1).Add(rate).Pow(months)
+	return l.Principal.Mul(rate).Mul(factor).Div(factor.Sub(decimal.NewFromInt(1))).Round(2)
+}
+
+// Interest — проценты за месяц на остаток долга.
+func (l Loan) Interest(debt decimal.Decimal) decimal.Decimal {
+	if !debt.IsPositive() {
+		return decimal.Zero
+	}
+
+	return debt.Mul(l.monthlyRate()).Round(2)
+}
+
+// SplitPayment делит платёж на проценты за месяц и погашение основного долга.
+func (l Loan) SplitPayment(debt decimal.Decimal, amount decimal.Decimal) (principal decimal.Decimal, interest decimal.Decimal) {
+	interest = decimal.Min(l.Interest(debt), amount)
+	return amount.Sub(interest), interest
+}
+
+// Schedule строит исходный график: первый платёж в месяце, следующем за выдачей.
+func (l Loan) Schedule() LoanSchedule {
+	return l.amortize(l.Principal, loanPaymentDate(l.StartDate, 1, l.PaymentDay), decimal.Zero)
+}
+
+// NextPaymentDate возвращает ближайшую дату платежа строго после after.
+func (l Loan) NextPaymentDate(after time.Time) time.Time {
+	next := loanPaymentDate(after, 0, l.PaymentDay)
+	if !next.After(truncateToDay(after)) {
+		next = loanPaymentDate(after, 1, l.PaymentDay)
+	}
+
+	return next
+}
+
+func NewLoanProjection(loan Loan, debt decimal.Decimal, after time.Time, extra decimal.Decimal) LoanProjection {
+	first := loan.NextPaymentDate(after)
+	projection := LoanProjection{
+		Debt:    debt,
+		Regular: loan.amortize(debt, first, decimal.Zero),
+	}
+
+	if !extra.IsPositive() {
+		return projection
+	}
+
+	withExtra := loan.amortize(debt, first, extra)
+	projection.WithExtra = &withExtra
+	projection.MonthsSaved = len(projection.Regular.Rows) - len(withExtra.Rows)
+	projection.InterestSaved = projection.Regular.TotalInterest.Sub(withExtra.TotalInterest)
+
+	return projection
+}
+
+// amortize гасит debt платежами начиная с first, последний платёж уменьшается
+// до остатка долга.
+func (l Loan) amortize(debt decimal.Decimal, first time.Time, extra decimal.Decimal) LoanSchedule {
+	schedule := LoanSchedule{
+		MonthlyPayment: l.MonthlyPayment(),
+		ExtraPayment:   extra,
+		Rows:           make([]LoanScheduleRow, 0, l.TermMonths),
+	}
+
+	balance := debt
+	for i := 0; balance.IsPositive() && i < maxLoanMonths; i++ {
+		interest := l.Interest(balance)
+		payment := schedule.MonthlyPayment.Add(extra)
+		principal := payment.Sub(interest)
+		if !principal.IsPositive() {
+			return schedule
+		}
+
+		if principal.GreaterThan(balance) {
+			principal = balance
+			payment = principal.Add(interest)
+		}
+		balance = balance.Sub(principal)
+
+		schedule.TotalInterest = schedule.TotalInterest.Add(interest)
+		schedule.Rows = append(schedule.Rows, LoanScheduleRow{
+			Number:    i + 1,
+			Date:      loanPaymentDate(first, i, l.PaymentDay),
+			Payment:   payment,
+			Principal: principal,
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+
+	if !balance.IsPositive() && len(schedule.Rows) > 0 {
+		schedule.PayoffDate = &schedule.Rows[len(schedule.Rows)-1].Date
+	}
+
+	return schedule
+}
+
+// loanPaymentDate возвращает день платежа в месяце, отстоящем от from на months.
+// В коротких месяцах платёж переносится на последний день.
+func loanPaymentDate(from time.Time, months int, day int) time.Time {
+	firstOfMonth := time.Date(from.Year(), from.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	return firstOfMonth.AddDate(0, 0, min(day, lastDay)-1)
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLoanMonthlyPayment(t *testing.T) {
+	tests := []struct {
+		name string
+		loan Loan
+		want string
+	}{
+		{
+			name: "аннуитет под 12% годовых на год",
+			loan: Loan{Principal: decimal.NewFromInt(100000), InterestRate: decimal.NewFromInt(12), TermMonths: 12},
+			want: "8884.88",
+		},
+		{
+			name: "беспроцентная рассрочка",
+			loan: Loan{Principal: decimal.NewFromInt(1000), TermMonths: 3},
+			want: "333.34",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.loan.MonthlyPayment(); got.String() != tt.want {
+				t.Errorf("MonthlyPayment() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoanSchedule(t *testing.T) {
+	loan := Loan{
+		Principal:    decimal.NewFromInt(100000),
+		InterestRate: decimal.NewFromInt(12),
+		TermMonths:   12,
+		PaymentDay:   31,
+		StartDate:    date(2025, time.January, 15),
+	}
+
+	schedule := loan.Schedule()
+
+	if len(schedule.Rows) != 12 {
+		t.Fatalf("got %d payments, want 12", len(schedule.Rows))
+	}
+
+	first := schedule.Rows[0]
+	if !first.Date.Equal(date(2025, time.February, 28)) {
+		t.Errorf("first payment date = %s, want 2025-02-28", first.Date)
+	}
+	if first.Interest.String() != "1000" || first.Principal.String() != "7884.88" {
+		t.Errorf("first payment split = %s + %s, want 7884.88 + 1000", first.Principal, first.Interest)
+	}
+
+	last := schedule.Rows[len(schedule.Rows)-1]
+	if !last.Balance.IsZero() {
+		t.Errorf("last balance = %s, want 0", last.Balance)
+	}
+	if schedule.PayoffDate == nil || !schedule.PayoffDate.Equal(date(2026, time.January, 31)) {
+		t.Errorf("payoff date = %v, want 2026-01-31", schedule.PayoffDate)
+	}
+
+	principal := decimal.Zero
+	for _, row := range schedule.Rows {
+		principal = principal.Add(row.Principal)
+	}
+	if !principal.Equal(loan.Principal) {
+		t.Errorf("principal paid = %s, want %s", principal, loan.Principal)
+	}
+}
+
+func TestLoanProjectionWithExtraPayment(t *testing.T) {
+	loan := Loan{
+		Principal:    decimal.NewFromInt(100000),
+		InterestRate: decimal.NewFromInt(12),
+		TermMonths:   12,
+		PaymentDay:   10,
+		StartDate:    date(2025, time.January, 10),
+	}
+
+	projection := NewLoanProjection(loan, decimal.NewFromInt(50000), date(2025, time.June, 10), decimal.NewFromInt(5000))
+
+	if !projection.Regular.Rows[0].Date.Equal(date(2025, time.July, 10)) {
+		t.Errorf("next payment date = %s, want 2025-07-10", projection.Regular.Rows[0].Date)
+	}
+
+	if projection.WithExtra == nil {
+		t.Fatal("projection with extra payment is empty")
+	}
+
+	if projection.MonthsSaved <= 0 || !projection.InterestSaved.IsPositive() {
+		t.Errorf("extra payment saved %d months and %s interest, want both positive", projection.MonthsSaved, projection.InterestSaved)
+	}
+
+	if projection.WithExtra.PayoffDate == nil || !projection.WithExtra.PayoffDate.Before(*projection.Regular.PayoffDate) {
+		t.Errorf("payoff with extra = %v, want before %v", projection.WithExtra.PayoffDate, projection.Regular.PayoffDate)
+	}
+}
+
+func TestLoanScheduleNeverPaidOff(t *testing.T) {
+	loan := Loan{Principal: decimal.NewFromInt(1000), InterestRate: decimal.NewFromInt(12), TermMonths: 12, PaymentDay: 1}
+
+	// Платёж меньше процентов на такой долг
+	projection := NewLoanProjection(loan, decimal.NewFromInt(1000000), date(2025, time.January, 1), decimal.Zero)
+
+	if projection.Regular.PayoffDate != nil || len(projection.Regular.Rows) != 0 {
+		t.Errorf("schedule = %d rows, payoff %v, want empty schedule", len(projection.Regular.Rows), projection.Regular.PayoffDate)
+	}
+}
+
+func TestLoanSplitPayment(t *testing.T) {
+	loan := Loan{InterestRate: decimal.NewFromInt(12)}
+
+	principal, interest := loan.SplitPayment(decimal.NewFromInt(100000), decimal.NewFromInt(5000))
+	if principal.String() != "4000" || interest.String() != "1000" {
+		t.Errorf("split = %s + %s, want 4000 + 1000", principal, interest)
+	}
+
+	// Платёж меньше процентов целиком уходит на проценты
+	principal, interest = loan.SplitPayment(decimal.NewFromInt(100000), decimal.NewFromInt(600))
+	if !principal.IsZero() || interest.String() != "600" {
+		t.Errorf("split = %s + %s, want 0 + 600", principal, interest)
+	}
+}
@@ -1,5 +1,13 @@
 package model
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
 type SortOrder string
 
 const (
@@ -21,6 +29,8 @@ type PaginationParams struct {
 	SortBy    *SortField
 	SortOrder *SortOrder
 	Search    *string
+	// Непустой указатель включает keyset-пагинацию, пустая строка — первая страница
+	Cursor *string
 }
 
 func NewPaginationParams(page, limit int) PaginationParams {
@@ -68,6 +78,84 @@ func NewPaginationMeta(total int, params PaginationParams) PaginationMeta {
 	}
 }
 
+// SortFieldOrDefault возвращает поле и направление сортировки с учётом значений по умолчанию.
+func (p PaginationParams) SortFieldOrDefault() (SortField, SortOrder) {
+	sortBy, sortOrder := SortFieldDate, SortOrderDESC
+	if p.SortBy != nil {
+		sortBy = *p.SortBy
+	}
+	if p.SortOrder != nil {
+		sortOrder = *p.SortOrder
+	}
+
+	return sortBy, sortOrder
+}
+
+// Cursor — позиция в выдаче для keyset-пагинации: значение ключа сортировки и id
+// граничной строки. Клиент получает его закодированным и передаёт обратно как есть.
+type Cursor struct {
+	SortBy    SortField `json:"s"`
+	SortOrder SortOrder `json:"o"`
+	Key       string    `json:"k"`
+	ID        uint64    `json:"i"`
+	// Курсор на предыдущую страницу, строки берутся перед граничной
+	Backward bool `json:"b,omitempty"`
+}
+
+// CursorDateLayout — формат ключа курсора при сортировке по дате.
+const CursorDateLayout = "2006-01-02"
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(raw string) (Cursor, error) {
+	var cursor Cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, err
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, err
+	}
+
+	if cursor.SortOrder != SortOrderASC && cursor.SortOrder != SortOrderDESC {
+		return cursor, errors.New("unknown sort order")
+	}
+
+	// Ключ сравнивается в базе с приведением к типу поля сортировки, поэтому
+	// проверяется здесь
+	switch cursor.SortBy {
+	case SortFieldDate:
+		if _, err := time.Parse(CursorDateLayout, cursor.Key); err != nil {
+			return cursor, errors.New("invalid date key")
+		}
+	case SortFieldCategory:
+		if _, err := strconv.ParseUint(cursor.Key, 10, 63); err != nil {
+			return cursor, errors.New("invalid category key")
+		}
+	case SortFieldDescription:
+	default:
+		return cursor, errors.New("unknown sort field")
+	}
+
+	return cursor, nil
+}
+
+type CursorMeta struct {
+	Limit      int     `json:"limit"`
+	NextCursor *string `json:"next_cursor,omitempty"`
+	PrevCursor *string `json:"prev_cursor,omitempty"`
+}
+
+type CursorPaginatedResponse[T any] struct {
+	Data []T        `json:"data"`
+	Meta CursorMeta `json:"meta"`
+}
+
 type PaginatedResponse[T any] struct {
 	Data []T            `json:"data"`
 	Meta PaginationMeta `json:"meta"`
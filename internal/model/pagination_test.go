@@ -0,0 +1,46 @@
+package model
+
+import "testing"
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{SortBy: SortFieldDescription, SortOrder: SortOrderASC, Key: "кофе, 2 шт.", ID: 42, Backward: true}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+
+	if decoded != cursor {
+		t.Errorf("DecodeCursor() = %+v, want %+v", decoded, cursor)
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	tests := map[string]string{
+		"не base64":                    "not-base64!",
+		"неизвестное поле":             Cursor{SortBy: "amount", SortOrder: SortOrderASC}.Encode(),
+		"неизвестное направление":      Cursor{SortBy: SortFieldDate, SortOrder: "up", Key: "2025-01-01"}.Encode(),
+		"ключ даты не дата":            Cursor{SortBy: SortFieldDate, SortOrder: SortOrderDESC, Key: "вчера"}.Encode(),
+		"ключ категории не число":      Cursor{SortBy: SortFieldCategory, SortOrder: SortOrderASC, Key: "1; DROP"}.Encode(),
+		"ключ категории вне bigint":    Cursor{SortBy: SortFieldCategory, SortOrder: SortOrderASC, Key: "18446744073709551615"}.Encode(),
+		"отрицательный ключ категории": Cursor{SortBy: SortFieldCategory, SortOrder: SortOrderASC, Key: "-1"}.Encode(),
+	}
+
+	for name, raw := range tests {
+		if _, err := DecodeCursor(raw); err == nil {
+			t.Errorf("%s: DecodeCursor(%q) expected error", name, raw)
+		}
+	}
+}
+
+func TestDecodeCursorKeys(t *testing.T) {
+	for _, cursor := range []Cursor{
+		{SortBy: SortFieldDate, SortOrder: SortOrderDESC, Key: "2025-03-31", ID: 1},
+		{SortBy: SortFieldCategory, SortOrder: SortOrderASC, Key: "0", ID: 2},
+		{SortBy: SortFieldDescription, SortOrder: SortOrderASC, Key: "", ID: 3},
+	} {
+		if _, err := DecodeCursor(cursor.Encode()); err != nil {
+			t.Errorf("DecodeCursor(%+v) error = %v", cursor, err)
+		}
+	}
+}
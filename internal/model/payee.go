@@ -0,0 +1,121 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Payee — получатель или плательщик транзакции. LastCategoryID — категория
+// последней транзакции с этим получателем, её предлагают для новых.
+type Payee struct {
+	ID             uint64    `json:"id" db:"id"`
+	UserID         uint64    `json:"user_id" db:"user_id"`
+	Name           string    `json:"name" db:"name"`
+	LastCategoryID *uint64   `json:"last_category_id,omitempty" db:"last_category_id"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PayeeRenameRule относит к получателю любой текст, содержащий Pattern, без учёта регистра.
+type PayeeRenameRule struct {
+	ID        uint64    `json:"id" db:"id"`
+	UserID    uint64    `json:"user_id" db:"user_id"`
+	PayeeID   uint64    `json:"payee_id" db:"payee_id"`
+	Pattern   string    `json:"pattern" db:"pattern"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type PayeeDetails struct {
+	Payee
+	Transactions int               `json:"transactions" db:"transactions"`
+	RenameRules  []PayeeRenameRule `json:"rename_rules" db:"-"`
+}
+
+// PayeeSuggestion — вариант автодополнения с предлагаемой категорией.
+type PayeeSuggestion struct {
+	ID                  uint64  `json:"id" db:"id"`
+	Name                string  `json:"name" db:"name"`
+	SuggestedCategoryID *uint64 `json:"suggested_category_id,omitempty" db:"last_category_id"`
+}
+
+type CreatePayeeRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type CreatePayeeRecord struct {
+	UserID    uint64
+	Name      string
+	CreatedAt time.Time
+}
+
+type UpdatePayeeRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type UpdatePayeeRecord struct {
+	Name      string
+	UpdatedAt time.Time
+}
+
+type MergePayeeRequest struct {
+	IntoPayeeID uint64 `json:"into_payee_id" binding:"required"`
+}
+
+// MergePayeeRecord — имя исходного получателя становится правилом переименования
+// целевого, чтобы новые транзакции с этим текстом попадали к нему.
+type MergePayeeRecord struct {
+	FromID    uint64
+	ToID      uint64
+	CreatedAt time.Time
+}
+
+type MergePayeeResult struct {
+	Transactions int `json:"transactions"`
+	RenameRules  int `json:"rename_rules"`
+}
+
+type CreatePayeeRenameRuleRequest struct {
+	Pattern string `json:"pattern" binding:"required"`
+}
+
+type CreatePayeeRenameRuleRecord struct {
+	UserID    uint64
+	PayeeID   uint64
+	Pattern   string
+	CreatedAt time.Time
+}
+
+type PayeeStatisticsRequest struct {
+	From      *time.Time `json:"from,omitempty" form:"from" time_format:"2006-01-02"`
+	To        *time.Time `json:"to,omitempty" form:"to" time_format:"2006-01-02"`
+	AccountID *uint64    `json:"account_id,omitempty" form:"account_id"`
+}
+
+func (r PayeeStatisticsRequest) Filter() StatisticsFilter {
+	return StatisticsFilter{
+		From:      r.From,
+		To:        r.To,
+		AccountID: r.AccountID,
+	}
+}
+
+type PayeeStatisticsItem struct {
+	PayeeID      uint64          `json:"payee_id" db:"payee_id"`
+	PayeeName    string          `json:"payee_name" db:"payee_name"`
+	Transactions int             `json:"transactions" db:"transactions"`
+	Income       decimal.Decimal `json:"income" db:"income"`
+	Expense      decimal.Decimal `json:"expense" db:"expense"`
+}
+
+type PayeeStatisticsResponse struct {
+	Currency     Currency              `json:"currency"`
+	Items        []PayeeStatisticsItem `json:"items"`
+	MissingRates []Currency            `json:"missing_rates,omitempty"`
+}
+
+// NormalizePayeeName убирает лишние пробелы в имени получателя.
+func NormalizePayeeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,19 @@
+package model
+
+import "testing"
+
+func TestNormalizePayeeName(t *testing.T) {
+	tests := map[string]string{
+		"Пятёрочка":          "Пятёрочка",
+		"  Яндекс   Такси  ": "Яндекс Такси",
+		"Coffee\tHouse\n":    "Coffee House",
+		"   ":                "",
+		"":                   "",
+	}
+
+	for name, want := range tests {
+		if got := NormalizePayeeName(name); got != want {
+			t.Errorf("NormalizePayeeName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
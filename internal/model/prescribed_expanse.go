@@ -0,0 +1,192 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PrescribedExpanseFrequency — единица повторения. Значения совпадают с клиентом.
+type PrescribedExpanseFrequency int16
+
+const (
+	FrequencyMonthly   PrescribedExpanseFrequency = 0
+	FrequencyDaily     PrescribedExpanseFrequency = 1
+	FrequencyWeekly    PrescribedExpanseFrequency = 2
+	FrequencyQuarterly PrescribedExpanseFrequency = 3
+	FrequencyYearly    PrescribedExpanseFrequency = 4
+)
+
+func (f PrescribedExpanseFrequency) IsValid() bool {
+	switch f {
+	case FrequencyMonthly, FrequencyDaily, FrequencyWeekly, FrequencyQuarterly, FrequencyYearly:
+		return true
+	default:
+		return false
+	}
+}
+
+// PrescribedExpanse — запланированная трата, повторяющаяся каждые Interval единиц Frequency
+// начиная с Date. Amount хранится положительным, оплата создаёт расходную транзакцию.
+type PrescribedExpanse struct {
+	ID          uint64                     `json:"id" db:"id"`
+	UserID      uint64                     `json:"user_id" db:"user_id"`
+	AccountID   *uint64                    `json:"account_id,omitempty" db:"account_id"`
+	CategoryID  *uint64                    `json:"category_id,omitempty" db:"category_id"`
+	Description string                     `json:"description" db:"description"`
+	Frequency   PrescribedExpanseFrequency `json:"frequency" db:"frequency"`
+	Interval    int                        `json:"interval" db:"repeat_every"`
+	Amount      decimal.Decimal            `json:"amount" db:"amount"`
+	Date        time.Time                  `json:"date" db:"date"`
+	EndDate     *time.Time                 `json:"end_date,omitempty" db:"end_date"`
+	CreatedAt   time.Time                  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time                  `json:"updated_at" db:"updated_at"`
+
+	// Повторения по эту дату включительно уже проведены фоновой задачей
+	PostedThrough *time.Time `json:"-" db:"posted_through"`
+}
+
+// Occurrences возвращает даты повторений в промежутке [from, to] включительно.
+// Если в месяце нет нужного числа, повторение переносится на последний день месяца.
+func (p PrescribedExpanse) Occurrences(from time.Time, to time.Time) []time.Time {
+	start := truncateToDay(p.Date)
+	from, to = truncateToDay(from), truncateToDay(to)
+	if p.EndDate != nil && truncateToDay(*p.EndDate).Before(to) {
+		to = truncateToDay(*p.EndDate)
+	}
+
+	interval := p.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	occurrences := make([]time.Time, 0)
+	for i := 0; ; i++ {
+		var date time.Time
+		switch p.Frequency {
+		case FrequencyDaily:
+			date = start.AddDate(0, 0, i*interval)
+		case FrequencyWeekly:
+			date = start.AddDate(0, 0, i*interval*7)
+		case FrequencyQuarterly:
+			date = addMonthsClamped(start, i*interval*3)
+		case FrequencyYearly:
+			date = addMonthsClamped(start, i*interval*12)
+		default:
+			date = addMonthsClamped(start, i*interval)
+		}
+
+		if date.After(to) {
+			break
+		}
+		if !date.Before(from) {
+			occurrences = append(occurrences, date)
+		}
+	}
+
+	return occurrences
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// Remaining возвращает, сколько осталось оплатить в повторении, по которому уже
+// оплачено paid, от нуля до суммы расписания. Переплата в следующие повторения
+// не переносится, а возвраты сверх оплаченного сумму повторения не увеличивают.
+func (p PrescribedExpanse) Remaining(paid decimal.Decimal) decimal.Decimal {
+	if paid.IsNegative() {
+		return p.Amount
+	}
+
+	remaining := p.Amount.Sub(paid)
+	if remaining.IsNegative() {
+		return decimal.Zero
+	}
+
+	return remaining
+}
+
+type PrescribedExpanseWithPaymentStatus struct {
+	PrescribedExpanse
+	DueDate       time.Time       `json:"due_date"`
+	IsPaid        bool            `json:"is_paid"`
+	PaidAmount    decimal.Decimal `json:"paid_amount"`
+	TransactionID *uint64         `json:"transaction_id,omitempty"`
+}
+
+// PrescribedExpansePayment — сумма оплат одного повторения расписания.
+type PrescribedExpansePayment struct {
+	PrescribedExpanseID uint64          `db:"prescribed_expanse_id"`
+	ScheduledDate       time.Time       `db:"scheduled_date"`
+	Amount              decimal.Decimal `db:"amount"`
+	TransactionID       uint64          `db:"transaction_id"`
+}
+
+type CreatePrescribedExpanseRequest struct {
+	AccountID   *uint64                    `json:"account_id,omitempty"`
+	CategoryID  *uint64                    `json:"category_id,omitempty"`
+	Description string                     `json:"description"`
+	Frequency   PrescribedExpanseFrequency `json:"frequency"`
+	Interval    int                        `json:"interval"`
+	Amount      decimal.Decimal            `json:"amount"`
+	Date        time.Time                  `json:"date"`
+	EndDate     *time.Time                 `json:"end_date,omitempty"`
+}
+
+type CreatePrescribedExpanseRecord struct {
+	UserID      uint64
+	AccountID   *uint64
+	CategoryID  *uint64
+	Description string
+	Frequency   PrescribedExpanseFrequency
+	Interval    int
+	Amount      decimal.Decimal
+	Date        time.Time
+	EndDate     *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type UpdatePrescribedExpanseRequest struct {
+	AccountID   *uint64                     `json:"account_id,omitempty"`
+	CategoryID  *uint64                     `json:"category_id,omitempty"`
+	Description *string                     `json:"description,omitempty"`
+	Frequency   *PrescribedExpanseFrequency `json:"frequency,omitempty"`
+	Interval    *int                        `json:"interval,omitempty"`
+	Amount      *decimal.Decimal            `json:"amount,omitempty"`
+	Date        *time.Time                  `json:"date,omitempty"`
+	EndDate     *time.Time                  `json:"end_date,omitempty"`
+}
+
+type UpdatePrescribedExpanseRecord struct {
+	AccountID   *uint64
+	CategoryID  *uint64
+	Description *string
+	Frequency   *PrescribedExpanseFrequency
+	Interval    *int
+	Amount      *decimal.Decimal
+	Date        *time.Time
+	EndDate     *time.Time
+	UpdatedAt   time.Time
+}
+
+// PayPrescribedExpanseRequest — оплата повторения. Без DueDate оплачивается ближайшее
+// неоплаченное повторение начиная с текущего месяца, без Amount — весь остаток.
+type PayPrescribedExpanseRequest struct {
+	Amount    *decimal.Decimal `json:"amount,omitempty"`
+	AccountID *uint64          `json:"account_id,omitempty"`
+	DueDate   *time.Time       `json:"due_date,omitempty"`
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestPrescribedExpanseOccurrences(t *testing.T) {
+	endDate := date(2025, time.March, 10)
+
+	tests := []struct {
+		name     string
+		expanse  PrescribedExpanse
+		from, to time.Time
+		want     []time.Time
+	}{
+		{
+			name:    "ежемесячно 31-го числа переносится на конец короткого месяца",
+			expanse: PrescribedExpanse{Frequency: FrequencyMonthly, Interval: 1, Date: date(2025, time.January, 31)},
+			from:    date(2025, time.February, 1),
+			to:      date(2025, time.April, 30),
+			want:    []time.Time{date(2025, time.February, 28), date(2025, time.March, 31), date(2025, time.April, 30)},
+		},
+		{
+			name:    "каждые две недели",
+			expanse: PrescribedExpanse{Frequency: FrequencyWeekly, Interval: 2, Date: date(2025, time.January, 1)},
+			from:    date(2025, time.January, 1),
+			to:      date(2025, time.January, 31),
+			want:    []time.Time{date(2025, time.January, 1), date(2025, time.January, 15), date(2025, time.January, 29)},
+		},
+		{
+			name:    "ежедневно до даты окончания",
+			expanse: PrescribedExpanse{Frequency: FrequencyDaily, Interval: 1, Date: date(2025, time.March, 8), EndDate: &endDate},
+			from:    date(2025, time.March, 1),
+			to:      date(2025, time.March, 31),
+			want:    []time.Time{date(2025, time.March, 8), date(2025, time.March, 9), date(2025, time.March, 10)},
+		},
+		{
+			name:    "ежеквартально через границу года",
+			expanse: PrescribedExpanse{Frequency: FrequencyQuarterly, Interval: 1, Date: date(2024, time.November, 15)},
+			from:    date(2025, time.January, 1),
+			to:      date(2025, time.June, 30),
+			want:    []time.Time{date(2025, time.February, 15), date(2025, time.May, 15)},
+		},
+		{
+			name:    "ежегодно 29 февраля в невисокосный год",
+			expanse: PrescribedExpanse{Frequency: FrequencyYearly, Interval: 1, Date: date(2024, time.February, 29)},
+			from:    date(2025, time.February, 1),
+			to:      date(2025, time.February, 28),
+			want:    []time.Time{date(2025, time.February, 28)},
+		},
+		{
+			name:    "расписание ещё не началось",
+			expanse: PrescribedExpanse{Frequency: FrequencyMonthly, Interval: 1, Date: date(2025, time.June, 1)},
+			from:    date(2025, time.January, 1),
+			to:      date(2025, time.January, 31),
+			want:    []time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.expanse.Occurrences(tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("occurrence %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPrescribedExpanseRemaining(t *testing.T) {
+	expanse := PrescribedExpanse{Amount: decimal.NewFromInt(1000)}
+
+	tests := map[string]struct {
+		paid int64
+		want int64
+	}{
+		"не оплачено":                 {paid: 0, want: 1000},
+		"оплачено частично":           {paid: 400, want: 600},
+		"оплачено полностью":          {paid: 1000, want: 0},
+		"переплата не уходит в минус": {paid: 1500, want: 0},
+		"возврат сверх оплаченного":   {paid: -200, want: 1000},
+	}
+
+	for name, tt := range tests {
+		if got := expanse.Remaining(decimal.NewFromInt(tt.paid)); !got.Equal(decimal.NewFromInt(tt.want)) {
+			t.Errorf("%s: Remaining(%d) = %s, want %d", name, tt.paid, got, tt.want)
+		}
+	}
+}
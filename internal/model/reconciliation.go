@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ClearedBalance — остатки счёта на дату по подтверждённым банком и остальным транзакциям.
+type ClearedBalance struct {
+	Cleared   decimal.Decimal `db:"cleared"`
+	Uncleared decimal.Decimal `db:"uncleared"`
+	// Подтверждённые транзакции, которые ещё не сверены
+	Unreconciled int `db:"unreconciled"`
+}
+
+type ReconciliationRequest struct {
+	Date             time.Time       `json:"date" binding:"required"`
+	StatementBalance decimal.Decimal `json:"statement_balance"`
+	// Провести расхождение корректирующей транзакцией
+	CreateAdjustment bool `json:"create_adjustment"`
+}
+
+type ReconcileAccountRecord struct {
+	AccountID        uint64
+	Date             time.Time
+	StatementBalance decimal.Decimal
+	CreateAdjustment bool
+	AdjustmentNote   string
+	ReconciledAt     time.Time
+}
+
+// AccountReconciliation — сравнение остатка по выписке с подтверждёнными транзакциями.
+type AccountReconciliation struct {
+	AccountID        uint64          `json:"account_id"`
+	Date             time.Time       `json:"date"`
+	StatementBalance decimal.Decimal `json:"statement_balance"`
+	ClearedBalance   decimal.Decimal `json:"cleared_balance"`
+	UnclearedBalance decimal.Decimal `json:"uncleared_balance"`
+	Difference       decimal.Decimal `json:"difference"`
+	Unreconciled     int             `json:"unreconciled"`
+}
+
+func NewAccountReconciliation(accountID uint64, date time.Time, statementBalance decimal.Decimal, balance ClearedBalance) AccountReconciliation {
+	return AccountReconciliation{
+		AccountID:        accountID,
+		Date:             date,
+		StatementBalance: statementBalance,
+		ClearedBalance:   balance.Cleared,
+		UnclearedBalance: balance.Uncleared,
+		Difference:       statementBalance.Sub(balance.Cleared),
+		Unreconciled:     balance.Unreconciled,
+	}
+}
+
+type ReconciliationResult struct {
+	AccountReconciliation
+	// Сколько транзакций отмечено сверенными
+	Reconciled              int     `json:"reconciled"`
+	AdjustmentTransactionID *uint64 `json:"adjustment_transaction_id,omitempty"`
+}
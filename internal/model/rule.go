@@ -0,0 +1,501 @@
+package model
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RuleMatchType — способ сравнения текстовых условий правила.
+type RuleMatchType string
+
+const (
+	// Текст содержит шаблон, без учёта регистра
+	RuleMatchContains RuleMatchType = "contains"
+	// Текст подходит под регулярное выражение, без учёта регистра
+	RuleMatchRegex RuleMatchType = "regex"
+)
+
+func (t RuleMatchType) IsValid() bool {
+	return t == RuleMatchContains || t == RuleMatchRegex
+}
+
+// Weekdays — набор дней недели, бит 0 — понедельник. Пустой набор подходит под
+// любой день. В JSON — номера дней от 1 (понедельник) до 7 (воскресенье).
+type Weekdays uint8
+
+// NewWeekdays собирает набор из номеров дней от 1 до 7.
+func NewWeekdays(days []int) (Weekdays, bool) {
+	var w Weekdays
+	for _, day := range days {
+		if day < 1 || day > 7 {
+			return 0, false
+		}
+		w |= 1 << (day - 1)
+	}
+
+	return w, true
+}
+
+func (w Weekdays) Contains(day time.Weekday) bool {
+	if w == 0 {
+		return true
+	}
+
+	// time.Weekday начинает неделю с воскресенья
+	index := (int(day) + 6) % 7
+	return w&(1<<index) != 0
+}
+
+func (w Weekdays) Days() []int {
+	days := make([]int, 0, 7)
+	for i := 0; i < 7; i++ {
+		if w&(1<<i) != 0 {
+			days = append(days, i+1)
+		}
+	}
+
+	return days
+}
+
+func (w Weekdays) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.Days())
+}
+
+// TransactionRule — правило разметки: условия (пустые подходят под всё) и
+// действия, которые применяются к подошедшей транзакции.
+type TransactionRule struct {
+	ID        uint64    `json:"id" db:"id"`
+	UserID    uint64    `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	SortOrder int       `json:"sort_order" db:"sort_order"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Условия
+	MatchType    RuleMatchType    `json:"match_type" db:"match_type"`
+	NotePattern  *string          `json:"note_pattern,omitempty" db:"note_pattern"`
+	PayeePattern *string          `json:"payee_pattern,omitempty" db:"payee_pattern"`
+	MinAmount    *decimal.Decimal `json:"min_amount,omitempty" db:"min_amount"`
+	MaxAmount    *decimal.Decimal `json:"max_amount,omitempty" db:"max_amount"`
+	AccountID    *uint64          `json:"account_id,omitempty" db:"account_id"`
+	Weekdays     Weekdays         `json:"weekdays" db:"weekdays"`
+
+	// Действия
+	SetCategoryID     *uint64 `json:"set_category_id,omitempty" db:"set_category_id"`
+	SetPayeeID        *uint64 `json:"set_payee_id,omitempty" db:"set_payee_id"`
+	SetNote           *string `json:"set_note,omitempty" db:"set_note"`
+	SetApproved       *bool   `json:"set_approved,omitempty" db:"set_approved"`
+	TransferAccountID *uint64 `json:"transfer_account_id,omitempty" db:"transfer_account_id"`
+
+	// Категория действия в корзине, правило её не назначает
+	SetCategoryDeleted bool `json:"set_category_deleted,omitempty" db:"set_category_deleted"`
+}
+
+// CreateTransactionRuleRequest задаёт правило целиком, при изменении тоже.
+// Суммы сравниваются по модулю, как в фильтре транзакций.
+type CreateTransactionRuleRequest struct {
+	Name      string `json:"name" binding:"required"`
+	SortOrder int    `json:"sort_order"`
+	IsActive  *bool  `json:"is_active,omitempty"`
+
+	MatchType    RuleMatchType    `json:"match_type"`
+	NotePattern  *string          `json:"note_pattern,omitempty"`
+	PayeePattern *string          `json:"payee_pattern,omitempty"`
+	MinAmount    *decimal.Decimal `json:"min_amount,omitempty"`
+	MaxAmount    *decimal.Decimal `json:"max_amount,omitempty"`
+	AccountID    *uint64          `json:"account_id,omitempty"`
+	Weekdays     []int            `json:"weekdays,omitempty"`
+
+	SetCategoryID     *uint64 `json:"set_category_id,omitempty"`
+	SetPayeeID        *uint64 `json:"set_payee_id,omitempty"`
+	SetNote           *string `json:"set_note,omitempty"`
+	SetApproved       *bool   `json:"set_approved,omitempty"`
+	TransferAccountID *uint64 `json:"transfer_account_id,omitempty"`
+}
+
+// IsValid проверяет условия и наличие хотя бы одного действия. Пустой тип
+// сравнения означает contains.
+func (r CreateTransactionRuleRequest) IsValid() bool {
+	matchType := r.MatchType
+	if matchType == "" {
+		matchType = RuleMatchContains
+	}
+	if !matchType.IsValid() {
+		return false
+	}
+
+	for _, pattern := range []*string{r.NotePattern, r.PayeePattern} {
+		if pattern == nil {
+			continue
+		}
+		if strings.TrimSpace(*pattern) == "" {
+			return false
+		}
+		if matchType == RuleMatchRegex {
+			if _, err := regexp.Compile(*pattern); err != nil {
+				return false
+			}
+		}
+	}
+
+	if r.MinAmount != nil && r.MinAmount.IsNegative() || r.MaxAmount != nil && r.MaxAmount.IsNegative() {
+		return false
+	}
+	if r.MinAmount != nil && r.MaxAmount != nil && r.MinAmount.GreaterThan(*r.MaxAmount) {
+		return false
+	}
+
+	if _, ok := NewWeekdays(r.Weekdays); !ok {
+		return false
+	}
+
+	// Перевод заменяет категорию и получателя
+	if r.TransferAccountID != nil && (r.SetCategoryID != nil || r.SetPayeeID != nil) {
+		return false
+	}
+
+	return r.SetCategoryID != nil || r.SetPayeeID != nil || r.SetNote != nil || r.SetApproved != nil || r.TransferAccountID != nil
+}
+
+type TransactionRuleRecord struct {
+	UserID    uint64
+	Name      string
+	SortOrder int
+	IsActive  bool
+
+	MatchType    RuleMatchType
+	NotePattern  *string
+	PayeePattern *string
+	MinAmount    *decimal.Decimal
+	MaxAmount    *decimal.Decimal
+	AccountID    *uint64
+	Weekdays     Weekdays
+
+	SetCategoryID     *uint64
+	SetPayeeID        *uint64
+	SetNote           *string
+	SetApproved       *bool
+	TransferAccountID *uint64
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// RuleSubject — то, с чем сравниваются условия правил.
+type RuleSubject struct {
+	AccountID uint64
+	Amount    decimal.Decimal
+	Date      time.Time
+	Note      string
+	Payee     string
+}
+
+// RuleOutcome — итог правил для одной транзакции, пустые поля правила не задали.
+type RuleOutcome struct {
+	RuleIDs           []uint64
+	CategoryID        *uint64
+	PayeeID           *uint64
+	Note              *string
+	IsApproved        *bool
+	TransferAccountID *uint64
+}
+
+func (o RuleOutcome) IsEmpty() bool {
+	return len(o.RuleIDs) == 0
+}
+
+// ApplyToRecord переносит действия на новую транзакцию. Категория и получатель,
+// заданные явно, остаются, заметка и отметка о проверке заменяются.
+func (o RuleOutcome) ApplyToRecord(record *CreateTransactionRecord) {
+	if o.CategoryID != nil && record.CategoryID == nil && len(record.Splits) == 0 {
+		record.CategoryID = o.CategoryID
+	}
+	if o.PayeeID != nil && record.PayeeID == nil {
+		record.PayeeID = o.PayeeID
+	}
+	if o.Note != nil {
+		record.Note = *o.Note
+	}
+	if o.IsApproved != nil {
+		record.IsApproved = *o.IsApproved
+	}
+}
+
+// RuleSet — активные правила пользователя в порядке проверки с разобранными
+// регулярными выражениями.
+type RuleSet struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	rule  TransactionRule
+	note  *regexp.Regexp
+	payee *regexp.Regexp
+}
+
+// NewRuleSet готовит правила к проверке. Выключенные правила и правила с
+// неразбираемым выражением пропускаются.
+func NewRuleSet(rules []TransactionRule) RuleSet {
+	set := RuleSet{rules: make([]compiledRule, 0, len(rules))}
+
+	for _, rule := range rules {
+		if !rule.IsActive {
+			continue
+		}
+
+		compiled := compiledRule{rule: rule}
+		if rule.MatchType == RuleMatchRegex {
+			var err error
+			if compiled.note, err = compileRulePattern(rule.NotePattern); err != nil {
+				continue
+			}
+			if compiled.payee, err = compileRulePattern(rule.PayeePattern); err != nil {
+				continue
+			}
+		}
+
+		set.rules = append(set.rules, compiled)
+	}
+
+	return set
+}
+
+func compileRulePattern(pattern *string) (*regexp.Regexp, error) {
+	if pattern == nil {
+		return nil, nil
+	}
+
+	return regexp.Compile("(?i)" + *pattern)
+}
+
+func (s RuleSet) IsEmpty() bool {
+	return len(s.rules) == 0
+}
+
+// Evaluate проверяет правила по порядку; каждое действие берётся из первого
+// подошедшего правила, которое его задаёт.
+func (s RuleSet) Evaluate(subject RuleSubject) RuleOutcome {
+	var outcome RuleOutcome
+
+	for _, compiled := range s.rules {
+		if !compiled.matches(subject) {
+			continue
+		}
+
+		rule := compiled.rule
+		applied := false
+		// Перевод и категория с получателем взаимоисключающие
+		if rule.TransferAccountID != nil && outcome.TransferAccountID == nil && outcome.CategoryID == nil && outcome.PayeeID == nil {
+			outcome.TransferAccountID = rule.TransferAccountID
+			applied = true
+		}
+		if rule.SetCategoryID != nil && !rule.SetCategoryDeleted && outcome.CategoryID == nil && outcome.TransferAccountID == nil {
+			outcome.CategoryID = rule.SetCategoryID
+			applied = true
+		}
+		if rule.SetPayeeID != nil && outcome.PayeeID == nil && outcome.TransferAccountID == nil {
+			outcome.PayeeID = rule.SetPayeeID
+			applied = true
+		}
+		if rule.SetNote != nil && outcome.Note == nil {
+			outcome.Note = rule.SetNote
+			applied = true
+		}
+		if rule.SetApproved != nil && outcome.IsApproved == nil {
+			outcome.IsApproved = rule.SetApproved
+			applied = true
+		}
+
+		if applied {
+			outcome.RuleIDs = append(outcome.RuleIDs, rule.ID)
+		}
+	}
+
+	return outcome
+}
+
+func (c compiledRule) matches(subject RuleSubject) bool {
+	rule := c.rule
+
+	if rule.AccountID != nil && *rule.AccountID != subject.AccountID {
+		return false
+	}
+
+	amount := subject.Amount.Abs()
+	if rule.MinAmount != nil && amount.LessThan(*rule.MinAmount) {
+		return false
+	}
+	if rule.MaxAmount != nil && amount.GreaterThan(*rule.MaxAmount) {
+		return false
+	}
+
+	if !rule.Weekdays.Contains(subject.Date.Weekday()) {
+		return false
+	}
+
+	return c.matchText(rule.NotePattern, c.note, subject.Note) &&
+		c.matchText(rule.PayeePattern, c.payee, subject.Payee)
+}
+
+func (c compiledRule) matchText(pattern *string, re *regexp.Regexp, text string) bool {
+	if pattern == nil {
+		return true
+	}
+
+	if re != nil {
+		return re.MatchString(text)
+	}
+
+	return strings.Contains(strings.ToLower(text), strings.ToLower(*pattern))
+}
+
+// ApplyRulesRequest ограничивает прогон правил по истории. RuleID проверяет одно
+// правило, Overwrite разрешает заменять уже заданные категорию и получателя.
+type ApplyRulesRequest struct {
+	RuleID    *uint64    `json:"rule_id,omitempty"`
+	From      *time.Time `json:"from,omitempty"`
+	To        *time.Time `json:"to,omitempty"`
+	AccountID *uint64    `json:"account_id,omitempty"`
+	Overwrite bool       `json:"overwrite"`
+}
+
+// RuleCandidate — транзакция из истории, к которой можно применить правила:
+// не перевод, без разбивки и не операция инвестиционного счёта.
+type RuleCandidate struct {
+	ID         uint64          `db:"id"`
+	AccountID  uint64          `db:"account_id"`
+	Amount     decimal.Decimal `db:"amount"`
+	Date       time.Time       `db:"date"`
+	Note       string          `db:"note"`
+	CategoryID *uint64         `db:"category_id"`
+	PayeeID    *uint64         `db:"payee_id"`
+	PayeeName  *string         `db:"payee_name"`
+	IsApproved bool            `db:"approved"`
+}
+
+func (c RuleCandidate) Subject() RuleSubject {
+	subject := RuleSubject{
+		AccountID: c.AccountID,
+		Amount:    c.Amount,
+		Date:      c.Date,
+		Note:      c.Note,
+	}
+	if c.PayeeName != nil {
+		subject.Payee = *c.PayeeName
+	}
+
+	return subject
+}
+
+// Change описывает, что изменят правила, и сообщает, изменится ли что-нибудь.
+func (c RuleCandidate) Change(outcome RuleOutcome, overwrite bool) (RuleChange, bool) {
+	before := RuleChangeValues{
+		CategoryID: c.CategoryID,
+		PayeeID:    c.PayeeID,
+		Note:       c.Note,
+		IsApproved: c.IsApproved,
+	}
+	after := before
+
+	if outcome.TransferAccountID != nil {
+		after.TransferAccountID = outcome.TransferAccountID
+		after.CategoryID = nil
+		after.PayeeID = nil
+	}
+	if outcome.CategoryID != nil && (after.CategoryID == nil || overwrite) {
+		after.CategoryID = outcome.CategoryID
+	}
+	if outcome.PayeeID != nil && (after.PayeeID == nil || overwrite) {
+		after.PayeeID = outcome.PayeeID
+	}
+	if outcome.Note != nil {
+		after.Note = *outcome.Note
+	}
+	if outcome.IsApproved != nil {
+		after.IsApproved = *outcome.IsApproved
+	}
+
+	change := RuleChange{
+		TransactionID: c.ID,
+		AccountID:     c.AccountID,
+		Amount:        c.Amount,
+		Date:          c.Date,
+		RuleIDs:       outcome.RuleIDs,
+		Before:        before,
+		After:         after,
+	}
+
+	return change, !before.Equal(after)
+}
+
+type RuleChangeValues struct {
+	CategoryID        *uint64 `json:"category_id,omitempty"`
+	PayeeID           *uint64 `json:"payee_id,omitempty"`
+	Note              string  `json:"note"`
+	IsApproved        bool    `json:"is_approved"`
+	TransferAccountID *uint64 `json:"transfer_account_id,omitempty"`
+}
+
+func (v RuleChangeValues) Equal(other RuleChangeValues) bool {
+	return equalIDs(v.CategoryID, other.CategoryID) &&
+		equalIDs(v.PayeeID, other.PayeeID) &&
+		v.Note == other.Note &&
+		v.IsApproved == other.IsApproved &&
+		equalIDs(v.TransferAccountID, other.TransferAccountID)
+}
+
+func equalIDs(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+type RuleChange struct {
+	TransactionID uint64           `json:"transaction_id"`
+	AccountID     uint64           `json:"account_id"`
+	Amount        decimal.Decimal  `json:"amount"`
+	Date          time.Time        `json:"date"`
+	RuleIDs       []uint64         `json:"rule_ids"`
+	Before        RuleChangeValues `json:"before"`
+	After         RuleChangeValues `json:"after"`
+}
+
+// UpdateRecord собирает правку транзакции из изменения. Перевод оформляется
+// отдельно, здесь у транзакции только снимаются категория и получатель.
+func (c RuleChange) UpdateRecord(updatedAt time.Time) UpdateTransactionRecord {
+	record := UpdateTransactionRecord{UpdatedAt: updatedAt}
+
+	if !equalIDs(c.Before.CategoryID, c.After.CategoryID) {
+		record.CategoryID = c.After.CategoryID
+		record.ClearCategory = c.After.CategoryID == nil
+	}
+	if !equalIDs(c.Before.PayeeID, c.After.PayeeID) {
+		record.PayeeID = c.After.PayeeID
+		record.ClearPayee = c.After.PayeeID == nil
+	}
+	if c.Before.Note != c.After.Note {
+		note := c.After.Note
+		record.Note = &note
+	}
+	if c.Before.IsApproved != c.After.IsApproved {
+		approved := c.After.IsApproved
+		record.IsApproved = &approved
+	}
+
+	return record
+}
+
+// ApplyRulesResult — итог прогона правил; при предпросмотре изменения только перечисляются.
+type ApplyRulesResult struct {
+	DryRun  bool         `json:"dry_run"`
+	Checked int          `json:"checked"`
+	Changes []RuleChange `json:"changes"`
+	Errors  []string     `json:"errors,omitempty"`
+}
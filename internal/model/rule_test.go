@@ -0,0 +1,192 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func ruleID(id uint64) *uint64 {
+	return &id
+}
+
+func ruleText(text string) *string {
+	return &text
+}
+
+func TestWeekdays(t *testing.T) {
+	weekdays, ok := NewWeekdays([]int{1, 6, 7})
+	if !ok {
+		t.Fatal("valid weekdays rejected")
+	}
+
+	if !weekdays.Contains(time.Monday) || !weekdays.Contains(time.Sunday) || weekdays.Contains(time.Wednesday) {
+		t.Errorf("weekdays %v contain wrong days", weekdays.Days())
+	}
+
+	if _, ok := NewWeekdays([]int{0}); ok {
+		t.Error("day 0 accepted")
+	}
+
+	if !Weekdays(0).Contains(time.Thursday) {
+		t.Error("empty weekdays must match any day")
+	}
+}
+
+func TestRuleSetEvaluate(t *testing.T) {
+	// 2025-03-03 — понедельник
+	monday := time.Date(2025, time.March, 3, 0, 0, 0, 0, time.UTC)
+	minAmount := decimal.NewFromInt(100)
+	maxAmount := decimal.NewFromInt(1000)
+	approved := true
+
+	rules := []TransactionRule{
+		{ID: 1, IsActive: false, MatchType: RuleMatchContains, NotePattern: ruleText("кофе"), SetCategoryID: ruleID(100)},
+		{ID: 2, IsActive: true, MatchType: RuleMatchContains, NotePattern: ruleText("КОФЕ"), SetCategoryID: ruleID(200)},
+		{ID: 3, IsActive: true, MatchType: RuleMatchRegex, PayeePattern: ruleText(`^star\w+`), SetCategoryID: ruleID(300), SetApproved: &approved},
+		{ID: 4, IsActive: true, MatchType: RuleMatchContains, MinAmount: &minAmount, MaxAmount: &maxAmount, Weekdays: 1, SetNote: ruleText("Будни")},
+		{ID: 5, IsActive: true, MatchType: RuleMatchRegex, NotePattern: ruleText(`(`), SetCategoryID: ruleID(500)},
+	}
+	set := NewRuleSet(rules)
+
+	tests := []struct {
+		name         string
+		subject      RuleSubject
+		wantRules    []uint64
+		wantCategory *uint64
+		wantNote     *string
+		wantApproved bool
+	}{
+		{
+			name:         "выключенное правило пропускается, текст без учёта регистра",
+			subject:      RuleSubject{Note: "Кофе с собой", Amount: decimal.NewFromInt(-50), Date: monday.AddDate(0, 0, 1)},
+			wantRules:    []uint64{2},
+			wantCategory: ruleID(200),
+		},
+		{
+			name:         "категорию задаёт первое подошедшее правило, остальные действия берутся дальше",
+			subject:      RuleSubject{Note: "кофе", Payee: "Starbucks", Amount: decimal.NewFromInt(-500), Date: monday},
+			wantRules:    []uint64{2, 3, 4},
+			wantCategory: ruleID(200),
+			wantNote:     ruleText("Будни"),
+			wantApproved: true,
+		},
+		{
+			name:    "сумма вне диапазона и другой день недели не подходят",
+			subject: RuleSubject{Note: "такси", Amount: decimal.NewFromInt(-5000), Date: monday},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := set.Evaluate(tt.subject)
+
+			if len(outcome.RuleIDs) != len(tt.wantRules) {
+				t.Fatalf("rules = %v, want %v", outcome.RuleIDs, tt.wantRules)
+			}
+			for i := range tt.wantRules {
+				if outcome.RuleIDs[i] != tt.wantRules[i] {
+					t.Fatalf("rules = %v, want %v", outcome.RuleIDs, tt.wantRules)
+				}
+			}
+
+			if !equalIDs(outcome.CategoryID, tt.wantCategory) {
+				t.Errorf("category = %v, want %v", outcome.CategoryID, tt.wantCategory)
+			}
+			if (outcome.Note == nil) != (tt.wantNote == nil) || outcome.Note != nil && *outcome.Note != *tt.wantNote {
+				t.Errorf("note = %v, want %v", outcome.Note, tt.wantNote)
+			}
+			if (outcome.IsApproved != nil && *outcome.IsApproved) != tt.wantApproved {
+				t.Errorf("approved = %v, want %v", outcome.IsApproved, tt.wantApproved)
+			}
+		})
+	}
+}
+
+func TestRuleSetTransferExcludesCategory(t *testing.T) {
+	set := NewRuleSet([]TransactionRule{
+		{ID: 1, IsActive: true, MatchType: RuleMatchContains, NotePattern: ruleText("перевод"), TransferAccountID: ruleID(7)},
+		{ID: 2, IsActive: true, MatchType: RuleMatchContains, SetCategoryID: ruleID(100), SetPayeeID: ruleID(5)},
+	})
+
+	outcome := set.Evaluate(RuleSubject{Note: "Перевод на накопительный"})
+	if !equalIDs(outcome.TransferAccountID, ruleID(7)) || outcome.CategoryID != nil || outcome.PayeeID != nil {
+		t.Errorf("outcome = %+v, want only transfer", outcome)
+	}
+}
+
+func TestRuleSetSkipsDeletedCategory(t *testing.T) {
+	set := NewRuleSet([]TransactionRule{
+		{ID: 1, IsActive: true, MatchType: RuleMatchContains, NotePattern: ruleText("такси"), SetCategoryID: ruleID(100), SetCategoryDeleted: true, SetNote: ruleText("Такси")},
+		{ID: 2, IsActive: true, MatchType: RuleMatchContains, SetCategoryID: ruleID(200)},
+	})
+
+	outcome := set.Evaluate(RuleSubject{Note: "Такси домой"})
+	if !equalIDs(outcome.CategoryID, ruleID(200)) || outcome.Note == nil {
+		t.Errorf("outcome = %+v, want category 200 and note from the first rule", outcome)
+	}
+}
+
+func TestRuleCandidateChange(t *testing.T) {
+	candidate := RuleCandidate{ID: 1, Note: "TAXI", CategoryID: ruleID(10)}
+	outcome := RuleOutcome{RuleIDs: []uint64{1}, CategoryID: ruleID(20), Note: ruleText("Такси")}
+
+	change, changed := candidate.Change(outcome, false)
+	if !changed {
+		t.Fatal("note change not detected")
+	}
+	if !equalIDs(change.After.CategoryID, ruleID(10)) {
+		t.Errorf("category overwritten without overwrite: %v", *change.After.CategoryID)
+	}
+
+	record := change.UpdateRecord(time.Now())
+	if record.CategoryID != nil || record.Note == nil || *record.Note != "Такси" {
+		t.Errorf("record = %+v, want only note", record)
+	}
+
+	change, _ = candidate.Change(outcome, true)
+	if !equalIDs(change.After.CategoryID, ruleID(20)) {
+		t.Errorf("category = %v, want 20 with overwrite", *change.After.CategoryID)
+	}
+
+	if _, changed := (RuleCandidate{ID: 2, Note: "Такси"}).Change(RuleOutcome{RuleIDs: []uint64{1}, Note: ruleText("Такси")}, false); changed {
+		t.Error("unchanged transaction reported as changed")
+	}
+}
+
+func TestCreateTransactionRuleRequestIsValid(t *testing.T) {
+	minAmount := decimal.NewFromInt(500)
+	maxAmount := decimal.NewFromInt(100)
+
+	tests := map[string]struct {
+		req  CreateTransactionRuleRequest
+		want bool
+	}{
+		"правило с условием и действием": {
+			req:  CreateTransactionRuleRequest{Name: "Кофе", NotePattern: ruleText("кофе"), SetCategoryID: ruleID(1)},
+			want: true,
+		},
+		"без действий": {
+			req: CreateTransactionRuleRequest{Name: "Пусто", NotePattern: ruleText("кофе")},
+		},
+		"неразбираемое выражение": {
+			req: CreateTransactionRuleRequest{Name: "Regex", MatchType: RuleMatchRegex, NotePattern: ruleText("("), SetNote: ruleText("x")},
+		},
+		"минимум больше максимума": {
+			req: CreateTransactionRuleRequest{Name: "Суммы", MinAmount: &minAmount, MaxAmount: &maxAmount, SetNote: ruleText("x")},
+		},
+		"перевод вместе с категорией": {
+			req: CreateTransactionRuleRequest{Name: "Перевод", TransferAccountID: ruleID(2), SetCategoryID: ruleID(1)},
+		},
+		"неизвестный день недели": {
+			req: CreateTransactionRuleRequest{Name: "Дни", Weekdays: []int{8}, SetNote: ruleText("x")},
+		},
+	}
+
+	for name, tt := range tests {
+		if got := tt.req.IsValid(); got != tt.want {
+			t.Errorf("%s: IsValid() = %v, want %v", name, got, tt.want)
+		}
+	}
+}
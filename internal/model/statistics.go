@@ -13,17 +13,52 @@ const (
 	PeriodTypeMonth PeriodType = "month"
 )
 
+func (p PeriodType) IsValid() bool {
+	switch p {
+	case PeriodTypeDay, PeriodTypeWeek, PeriodTypeMonth:
+		return true
+	default:
+		return false
+	}
+}
+
+// StatisticsFilter — общие фильтры отчётов. Границы периода включительные.
+type StatisticsFilter struct {
+	From          *time.Time
+	To            *time.Time
+	AccountID     *uint64
+	CategoryGroup *string
+}
+
 type CurrentBalanceStatistics struct {
+	Currency         Currency        `json:"currency"`
 	TotalExpense     decimal.Decimal `json:"total_expense"`
 	TotalIncome      decimal.Decimal `json:"total_income"`
 	TotalReserved    decimal.Decimal `json:"total_reserved"`
 	FreeToDistribute decimal.Decimal `json:"free_to_distribute"`
+	MissingRates     []Currency      `json:"missing_rates,omitempty"`
+}
+
+type CurrentBalanceStatisticsRequest struct {
+	Year  uint `form:"year"`
+	Month uint `form:"month"`
 }
 
 type CategoryStatisticsRequest struct {
-	Period PeriodType `json:"period" form:"period"`
-	From   *time.Time `json:"from,omitempty" form:"from"`
-	To     *time.Time `json:"to,omitempty" form:"to"`
+	Period        PeriodType `json:"period" form:"period"`
+	From          *time.Time `json:"from,omitempty" form:"from" time_format:"2006-01-02"`
+	To            *time.Time `json:"to,omitempty" form:"to" time_format:"2006-01-02"`
+	AccountID     *uint64    `json:"account_id,omitempty" form:"account_id"`
+	CategoryGroup *string    `json:"category_group,omitempty" form:"category_group"`
+}
+
+func (r CategoryStatisticsRequest) Filter() StatisticsFilter {
+	return StatisticsFilter{
+		From:          r.From,
+		To:            r.To,
+		AccountID:     r.AccountID,
+		CategoryGroup: r.CategoryGroup,
+	}
 }
 
 type CategoryStatisticsItem struct {
@@ -35,14 +70,27 @@ type CategoryStatisticsItem struct {
 }
 
 type CategoryStatisticsResponse struct {
-	Period PeriodType               `json:"period"`
-	Items  []CategoryStatisticsItem `json:"items"`
+	Period       PeriodType               `json:"period"`
+	Currency     Currency                 `json:"currency"`
+	Items        []CategoryStatisticsItem `json:"items"`
+	MissingRates []Currency               `json:"missing_rates,omitempty"`
 }
 
 type PeriodStatisticsRequest struct {
-	Period PeriodType `json:"period" form:"period"`
-	From   *time.Time `json:"from,omitempty" form:"from"`
-	To     *time.Time `json:"to,omitempty" form:"to"`
+	Period        PeriodType `json:"period" form:"period"`
+	From          *time.Time `json:"from,omitempty" form:"from" time_format:"2006-01-02"`
+	To            *time.Time `json:"to,omitempty" form:"to" time_format:"2006-01-02"`
+	AccountID     *uint64    `json:"account_id,omitempty" form:"account_id"`
+	CategoryGroup *string    `json:"category_group,omitempty" form:"category_group"`
+}
+
+func (r PeriodStatisticsRequest) Filter() StatisticsFilter {
+	return StatisticsFilter{
+		From:          r.From,
+		To:            r.To,
+		AccountID:     r.AccountID,
+		CategoryGroup: r.CategoryGroup,
+	}
 }
 
 type PeriodStatisticsItem struct {
@@ -53,6 +101,54 @@ type PeriodStatisticsItem struct {
 }
 
 type PeriodStatisticsResponse struct {
-	Period PeriodType             `json:"period"`
-	Items  []PeriodStatisticsItem `json:"items"`
+	Period       PeriodType             `json:"period"`
+	Currency     Currency               `json:"currency"`
+	Items        []PeriodStatisticsItem `json:"items"`
+	MissingRates []Currency             `json:"missing_rates,omitempty"`
+}
+
+// NetWorthAccount — вклад счёта в капитал: баланс и, у инвестиционного счёта,
+// оценка бумаг в валюте счёта. Value — их сумма в базовой валюте по курсу на сегодня,
+// пустая, если курса валюты счёта нет.
+type NetWorthAccount struct {
+	AccountID     uint64              `json:"account_id" db:"account_id"`
+	Name          string              `json:"name" db:"name"`
+	Type          AccountType         `json:"type" db:"type"`
+	OnBudget      bool                `json:"on_budget" db:"on_budget"`
+	Currency      Currency            `json:"currency" db:"currency"`
+	Balance       decimal.Decimal     `json:"balance" db:"balance"`
+	HoldingsValue decimal.Decimal     `json:"holdings_value" db:"holdings_value"`
+	Value         decimal.NullDecimal `json:"value" db:"value"`
+}
+
+// NetWorth — активы за вычетом обязательств по всем счетам, включая внебюджетные,
+// в базовой валюте.
+type NetWorth struct {
+	Currency     Currency          `json:"currency"`
+	Assets       decimal.Decimal   `json:"assets"`
+	Liabilities  decimal.Decimal   `json:"liabilities"`
+	NetWorth     decimal.Decimal   `json:"net_worth"`
+	Accounts     []NetWorthAccount `json:"accounts"`
+	MissingRates []Currency        `json:"missing_rates,omitempty"`
+}
+
+func NewNetWorth(currency Currency, accounts []NetWorthAccount) NetWorth {
+	netWorth := NetWorth{Currency: currency, Accounts: make([]NetWorthAccount, 0, len(accounts))}
+
+	for _, account := range accounts {
+		netWorth.Accounts = append(netWorth.Accounts, account)
+		// Счёт без курса в итоги не входит
+		if !account.Value.Valid {
+			continue
+		}
+
+		if account.Value.Decimal.IsNegative() {
+			netWorth.Liabilities = netWorth.Liabilities.Sub(account.Value.Decimal)
+		} else {
+			netWorth.Assets = netWorth.Assets.Add(account.Value.Decimal)
+		}
+	}
+	netWorth.NetWorth = netWorth.Assets.Sub(netWorth.Liabilities)
+
+	return netWorth
 }
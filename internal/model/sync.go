@@ -0,0 +1,197 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SyncEntity совпадает с именем таблицы, его же пишет триггер в sync_tombstones.
+type SyncEntity string
+
+const (
+	SyncEntityAccount          SyncEntity = "accounts"
+	SyncEntityCategory         SyncEntity = "categories"
+	SyncEntityCategoryGroup    SyncEntity = "category_groups"
+	SyncEntityTransaction      SyncEntity = "transactions"
+	SyncEntityBudgetAllocation SyncEntity = "budget_allocations"
+)
+
+func (e SyncEntity) IsValid() bool {
+	switch e {
+	case SyncEntityAccount, SyncEntityCategory, SyncEntityCategoryGroup, SyncEntityTransaction, SyncEntityBudgetAllocation:
+		return true
+	default:
+		return false
+	}
+}
+
+type SyncOperation string
+
+const (
+	SyncOperationUpsert SyncOperation = "upsert"
+	SyncOperationDelete SyncOperation = "delete"
+)
+
+func (o SyncOperation) IsValid() bool {
+	return o == SyncOperationUpsert || o == SyncOperationDelete
+}
+
+type SyncChangeStatus string
+
+const (
+	SyncChangeApplied SyncChangeStatus = "applied"
+	// На сервере запись новее base_version клиента, изменение не применено
+	SyncChangeConflict SyncChangeStatus = "conflict"
+	SyncChangeRejected SyncChangeStatus = "rejected"
+)
+
+// EncodeSyncToken упаковывает версию, до которой клиент получил изменения.
+func EncodeSyncToken(version int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("v1:" + strconv.FormatInt(version, 10)))
+}
+
+func DecodeSyncToken(raw string) (int64, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return 0, err
+	}
+
+	value, ok := strings.CutPrefix(string(data), "v1:")
+	if !ok {
+		return 0, errors.New("unknown sync token version")
+	}
+
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version < 0 {
+		return 0, errors.New("malformed sync token")
+	}
+
+	return version, nil
+}
+
+type SyncTombstone struct {
+	Entity    SyncEntity `json:"entity" db:"entity"`
+	EntityID  uint64     `json:"id" db:"entity_id"`
+	ClientID  *string    `json:"client_id,omitempty" db:"client_id"`
+	Version   int64      `json:"version" db:"version"`
+	DeletedAt time.Time  `json:"deleted_at" db:"deleted_at"`
+}
+
+type SyncChanges struct {
+	Accounts          []Account          `json:"accounts"`
+	Categories        []Category         `json:"categories"`
+	CategoryGroups    []CategoryGroup    `json:"category_groups"`
+	Transactions      []Transaction      `json:"transactions"`
+	BudgetAllocations []BudgetAllocation `json:"budget_allocations"`
+	Deleted           []SyncTombstone    `json:"deleted"`
+}
+
+type SyncPullRequest struct {
+	Token string `form:"token"`
+}
+
+type SyncPullResponse struct {
+	SyncChanges
+	Token string `json:"token"`
+}
+
+// SyncChange — одно изменение из офлайн-очереди клиента. Запись ищется по id,
+// а если его нет — по client_id; не найденная по client_id запись создаётся.
+type SyncChange struct {
+	Entity    SyncEntity    `json:"entity" binding:"required"`
+	Operation SyncOperation `json:"op" binding:"required"`
+	ID        *uint64       `json:"id,omitempty"`
+	ClientID  *string       `json:"client_id,omitempty"`
+	// Версия, от которой клиент начал правку; если запись менялась позже — конфликт
+	BaseVersion *int64          `json:"base_version,omitempty"`
+	Data        json.RawMessage `json:"data,omitempty"`
+}
+
+type SyncPushRequest struct {
+	Changes []SyncChange `json:"changes" binding:"required"`
+}
+
+type SyncChangeResult struct {
+	Entity   SyncEntity       `json:"entity"`
+	ID       uint64           `json:"id,omitempty"`
+	ClientID *string          `json:"client_id,omitempty"`
+	Status   SyncChangeStatus `json:"status"`
+	Version  int64            `json:"version,omitempty"`
+	Error    string           `json:"error,omitempty"`
+}
+
+type SyncPushResponse struct {
+	Results []SyncChangeResult `json:"results"`
+}
+
+// Данные upsert-изменений. Ссылки на другие записи можно задать серверным ID
+// или client_id, если запись создана в той же офлайн-сессии.
+
+type SyncAccountData struct {
+	Name       string      `json:"name"`
+	Type       AccountType `json:"type"`
+	IsArchived bool        `json:"is_archived"`
+	OrderNum   int         `json:"order_num"`
+	// Учитывается только при создании счёта, по умолчанию счёт бюджетный
+	OnBudget *bool `json:"on_budget,omitempty"`
+	// Учитывается только при создании счёта, по умолчанию — базовая валюта
+	Currency *Currency `json:"currency,omitempty"`
+}
+
+type SyncCategoryData struct {
+	Name          string          `json:"name"`
+	GroupID       *uint64         `json:"group_id,omitempty"`
+	GroupClientID *string         `json:"group_client_id,omitempty"`
+	SortOrder     int             `json:"sort_order"`
+	IsHidden      bool            `json:"is_hidden"`
+	Target        *CategoryTarget `json:"target,omitempty"`
+}
+
+type SyncCategoryGroupData struct {
+	Name      string `json:"name"`
+	SortOrder int    `json:"sort_order"`
+	IsHidden  bool   `json:"is_hidden"`
+}
+
+type SyncTransactionData struct {
+	AccountID        *uint64         `json:"account_id,omitempty"`
+	AccountClientID  *string         `json:"account_client_id,omitempty"`
+	CategoryID       *uint64         `json:"category_id,omitempty"`
+	CategoryClientID *string         `json:"category_client_id,omitempty"`
+	Amount           decimal.Decimal `json:"amount"`
+	Date             time.Time       `json:"date"`
+	Note             string          `json:"note"`
+	IsCleared        bool            `json:"is_cleared"`
+	IsApproved       bool            `json:"is_approved"`
+}
+
+type SyncBudgetAllocationData struct {
+	CategoryID       *uint64         `json:"category_id,omitempty"`
+	CategoryClientID *string         `json:"category_client_id,omitempty"`
+	Year             uint            `json:"year"`
+	Month            uint            `json:"month"`
+	Assigned         decimal.Decimal `json:"assigned"`
+}
+
+// SyncChangeRecord — проверенное изменение с разобранными данными нужного типа.
+type SyncChangeRecord struct {
+	Entity      SyncEntity
+	Operation   SyncOperation
+	ID          *uint64
+	ClientID    *string
+	BaseVersion *int64
+
+	Account          *SyncAccountData
+	Category         *SyncCategoryData
+	CategoryGroup    *SyncCategoryGroupData
+	Transaction      *SyncTransactionData
+	BudgetAllocation *SyncBudgetAllocationData
+
+	UpdatedAt time.Time
+}
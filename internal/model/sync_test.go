@@ -0,0 +1,24 @@
+package model
+
+import "testing"
+
+func TestSyncTokenRoundTrip(t *testing.T) {
+	for _, version := range []int64{0, 1, 9_000_000_000} {
+		decoded, err := DecodeSyncToken(EncodeSyncToken(version))
+		if err != nil {
+			t.Fatalf("DecodeSyncToken() error = %v", err)
+		}
+
+		if decoded != version {
+			t.Errorf("DecodeSyncToken() = %d, want %d", decoded, version)
+		}
+	}
+}
+
+func TestDecodeSyncTokenRejectsGarbage(t *testing.T) {
+	for _, raw := range []string{"%%%", "djI6MTA", "djE6LTE", "djE6YWJj"} {
+		if _, err := DecodeSyncToken(raw); err == nil {
+			t.Errorf("DecodeSyncToken(%q) expected error", raw)
+		}
+	}
+}
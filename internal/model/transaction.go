@@ -5,11 +5,35 @@ import (
 	"time"
 )
 
+// TransactionOrigin отличает служебные транзакции счёта от обычных.
+type TransactionOrigin string
+
+const (
+	TransactionOriginRegular TransactionOrigin = "regular"
+	// Остаток, с которым счёт заведён в приложение
+	TransactionOriginStartingBalance TransactionOrigin = "starting_balance"
+	// Разница, проведённая при выставлении баланса счёта вручную
+	TransactionOriginBalanceAdjustment TransactionOrigin = "balance_adjustment"
+	// Проценты, начисленные по кредиту при проведении платежа
+	TransactionOriginLoanInterest TransactionOrigin = "loan_interest"
+	// Покупка или продажа бумаг на инвестиционном счёте
+	TransactionOriginInvestmentTrade TransactionOrigin = "investment_trade"
+	// Дивиденды по бумагам инвестиционного счёта
+	TransactionOriginDividend TransactionOrigin = "dividend"
+)
+
+// IsInvestment сообщает, что транзакция проведена операцией инвестиционного
+// счёта и меняется только вместе с позициями.
+func (o TransactionOrigin) IsInvestment() bool {
+	return o == TransactionOriginInvestmentTrade || o == TransactionOriginDividend
+}
+
 type Transaction struct {
 	ID         uint64          `json:"id" db:"id"`
 	UserID     uint64          `json:"user_id" db:"user_id"`
-	CategoryID uint64          `json:"category_id,omitempty" db:"category_id"`
+	CategoryID *uint64         `json:"category_id,omitempty" db:"category_id"`
 	AccountID  uint64          `json:"account_id" db:"account_id"`
+	PayeeID    *uint64         `json:"payee_id,omitempty" db:"payee_id"`
 	Note       string          `json:"note" db:"note"`
 	Amount     decimal.Decimal `json:"amount" db:"amount"`
 	Date       time.Time       `json:"date" db:"date"`
@@ -17,6 +41,64 @@ type Transaction struct {
 	IsApproved bool            `json:"is_approved" db:"approved"`
 	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+
+	// Служебные транзакции счёта: начальный остаток, корректировка баланса
+	Origin TransactionOrigin `json:"origin" db:"origin"`
+
+	// Время сверки с выпиской; сумму, дату и счёт сверенной транзакции нельзя
+	// менять без явного снятия блокировки
+	ReconciledAt *time.Time `json:"reconciled_at,omitempty" db:"reconciled_at"`
+
+	// Для переводов — ID второй половины пары
+	TransferTransactionID *uint64 `json:"transfer_transaction_id,omitempty" db:"transfer_transaction_id"`
+
+	// Для оплат запланированных трат — расписание и дата оплаченного повторения
+	PrescribedExpanseID *uint64    `json:"prescribed_expanse_id,omitempty" db:"prescribed_expanse_id"`
+	ScheduledDate       *time.Time `json:"scheduled_date,omitempty" db:"scheduled_date"`
+
+	// Версия изменения и ID, выданный клиентом при офлайн-создании
+	Version  int64   `json:"version" db:"version"`
+	ClientID *string `json:"client_id,omitempty" db:"client_id"`
+
+	// Время переноса в корзину, у активных записей пусто
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+
+	// Разбивка суммы по категориям, у такой транзакции нет собственной категории
+	Splits []TransactionSplit `json:"splits,omitempty" db:"-"`
+}
+
+func (t Transaction) IsTransfer() bool {
+	return t.TransferTransactionID != nil
+}
+
+func (t Transaction) IsSplit() bool {
+	return len(t.Splits) > 0
+}
+
+func (t Transaction) IsReconciled() bool {
+	return t.ReconciledAt != nil
+}
+
+type TransactionSplit struct {
+	ID            uint64          `json:"id" db:"id"`
+	TransactionID uint64          `json:"transaction_id" db:"transaction_id"`
+	CategoryID    uint64          `json:"category_id" db:"category_id"`
+	Amount        decimal.Decimal `json:"amount" db:"amount"`
+	Note          string          `json:"note" db:"note"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+type CreateTransactionSplitRequest struct {
+	CategoryID uint64          `json:"category_id" binding:"required"`
+	Amount     decimal.Decimal `json:"amount"`
+	Note       string          `json:"note"`
+}
+
+type CreateTransactionSplitRecord struct {
+	CategoryID uint64
+	Amount     decimal.Decimal
+	Note       string
 }
 
 type CreateTransactionRequest struct {
@@ -27,19 +109,31 @@ type CreateTransactionRequest struct {
 	Date       time.Time       `json:"date"`
 	IsCleared  bool            `json:"is_cleared"`
 	IsApproved bool            `json:"is_approved"`
+
+	// Получатель задаётся ID или текстом: по тексту он находится через правила
+	// переименования и имя, а если не найден — создаётся
+	PayeeID   *uint64 `json:"payee_id,omitempty"`
+	PayeeName *string `json:"payee_name,omitempty"`
+
+	Splits []CreateTransactionSplitRequest `json:"splits,omitempty"`
 }
 
 type CreateTransactionRecord struct {
 	UserID     uint64
 	AccountID  uint64
 	CategoryID *uint64
+	PayeeID    *uint64
 	Amount     decimal.Decimal
 	Note       string
 	Date       time.Time
 	IsCleared  bool
 	IsApproved bool
+	Splits     []CreateTransactionSplitRecord
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+
+	PrescribedExpanseID *uint64
+	ScheduledDate       *time.Time
 }
 
 type UpdateTransactionRequest struct {
@@ -50,16 +144,102 @@ type UpdateTransactionRequest struct {
 	Note       *string          `json:"note,omitempty"`
 	IsCleared  *bool            `json:"is_cleared,omitempty"`
 	IsApproved *bool            `json:"is_approved,omitempty"`
+
+	// Получатель, как при создании; пустой payee_name убирает получателя
+	PayeeID   *uint64 `json:"payee_id,omitempty"`
+	PayeeName *string `json:"payee_name,omitempty"`
+
+	// Пустой массив убирает разбивку, nil оставляет её без изменений
+	Splits *[]CreateTransactionSplitRequest `json:"splits,omitempty"`
+
+	// Разрешает менять сумму, дату и счёт сверенной транзакции; сверка при этом снимается
+	UnlockReconciled bool `json:"unlock_reconciled,omitempty"`
+
+	// Сумма второй половины перевода между счетами в разных валютах, по модулю.
+	// У перевода в одной валюте вторая половина всегда равна первой.
+	TransferAmount *decimal.Decimal `json:"transfer_amount,omitempty"`
 }
 
 type UpdateTransactionRecord struct {
 	AccountID  *uint64
 	CategoryID *uint64
+	PayeeID    *uint64
+	ClearPayee bool
 	Amount     *decimal.Decimal
 	Date       *time.Time
 	Note       *string
 	IsCleared  *bool
 	IsApproved *bool
-	UpdatedAt  time.Time
+	Splits     *[]CreateTransactionSplitRecord
+	// Снять отметку о сверке
+	Unreconcile bool
+	// Убрать категорию, например с половины перевода между бюджетными счетами
+	ClearCategory bool
+	UpdatedAt     time.Time
+}
+
+type CreateTransferRequest struct {
+	FromAccountID uint64          `json:"from_account_id" binding:"required"`
+	ToAccountID   uint64          `json:"to_account_id" binding:"required"`
+	Amount        decimal.Decimal `json:"amount"`
+	Note          string          `json:"note"`
+	Date          time.Time       `json:"date"`
+	IsCleared     bool            `json:"is_cleared"`
+	IsApproved    bool            `json:"is_approved"`
+
+	// Перевод между бюджетным и внебюджетным счётом — трата или доход бюджета,
+	// категория ставится на бюджетную половину
+	CategoryID *uint64 `json:"category_id,omitempty"`
+
+	// Сумма зачисления в валюте счёта получателя, обязательна для перевода между
+	// счетами в разных валютах
+	ToAmount *decimal.Decimal `json:"to_amount,omitempty"`
+}
+
+type TransferResult struct {
+	FromTransactionID int `json:"from_transaction_id"`
+	ToTransactionID   int `json:"to_transaction_id"`
 }
-type PaginatedTransactionsResponse = PaginatedResponse[Transaction]
+
+type TransactionKind string
+
+const (
+	TransactionKindIncome  TransactionKind = "income"
+	TransactionKindExpense TransactionKind = "expense"
+)
+
+func (k TransactionKind) IsValid() bool {
+	return k == TransactionKindIncome || k == TransactionKindExpense
+}
+
+// TransactionFilter — фильтры списка транзакций. Пустое поле не ограничивает выборку.
+type TransactionFilter struct {
+	From        *time.Time
+	To          *time.Time
+	AccountIDs  []uint64
+	CategoryIDs []uint64
+	PayeeIDs    []uint64
+	// Границы суммы по модулю, чтобы одинаково работать с доходами и расходами
+	MinAmount  *decimal.Decimal
+	MaxAmount  *decimal.Decimal
+	IsCleared  *bool
+	IsApproved *bool
+	// Только транзакции без категории, кроме разбитых и переводов
+	Uncategorized bool
+	Kind          *TransactionKind
+}
+
+// TransactionTotals — суммы по всем транзакциям, попавшим под фильтр, а не только по странице.
+type TransactionTotals struct {
+	Income  decimal.Decimal `json:"income" db:"income"`
+	Expense decimal.Decimal `json:"expense" db:"expense"`
+	Balance decimal.Decimal `json:"balance" db:"balance"`
+}
+
+type PaginatedTransactionsResponse struct {
+	PaginatedResponse[Transaction]
+	Totals TransactionTotals `json:"totals"`
+}
+
+// CursorTransactionsResponse — страница keyset-пагинации, без общего количества и сумм.
+type CursorTransactionsResponse = CursorPaginatedResponse[Transaction]
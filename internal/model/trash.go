@@ -0,0 +1,14 @@
+package model
+
+// Trash — записи пользователя в корзине, от недавно удалённых к давним.
+type Trash struct {
+	Accounts     []Account     `json:"accounts"`
+	Categories   []Category    `json:"categories"`
+	Transactions []Transaction `json:"transactions"`
+}
+
+type PurgeResult struct {
+	Accounts     int `json:"accounts"`
+	Categories   int `json:"categories"`
+	Transactions int `json:"transactions"`
+}
@@ -20,12 +20,39 @@ func (u UserRole) String() string {
 	}
 }
 
+// OverspendingMode определяет, куда уходит перерасход категории в следующем месяце.
+type OverspendingMode string
+
+const (
+	// Перерасход уменьшает To-Be-Budgeted следующего месяца, категория начинает с нуля
+	OverspendingModeToBeBudgeted OverspendingMode = "to_be_budgeted"
+	// Перерасход остаётся долгом категории и переносится в следующий месяц
+	OverspendingModeCategoryDebt OverspendingMode = "category_debt"
+)
+
+func (m OverspendingMode) IsValid() bool {
+	return m == OverspendingModeToBeBudgeted || m == OverspendingModeCategoryDebt
+}
+
 type User struct {
-	ID           uint64    `json:"id" db:"id"`
-	Username     string    `json:"username" db:"username"`
-	Role         UserRole  `json:"role" db:"role"`
-	PasswordHash string    `json:"-" db:"password_hash"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ID               uint64           `json:"id" db:"id"`
+	Username         string           `json:"username" db:"username"`
+	Role             UserRole         `json:"role" db:"role"`
+	PasswordHash     string           `json:"-" db:"password_hash"`
+	OverspendingMode OverspendingMode `json:"overspending_mode" db:"overspending_mode"`
+	BaseCurrency     Currency         `json:"base_currency" db:"base_currency"`
+	CreatedAt        time.Time        `json:"created_at" db:"created_at"`
+}
+
+type UserSettings struct {
+	OverspendingMode OverspendingMode `json:"overspending_mode"`
+	// Валюта, в которую пересчитываются бюджет и статистика
+	BaseCurrency Currency `json:"base_currency"`
+}
+
+type UpdateUserSettingsRequest struct {
+	OverspendingMode *OverspendingMode `json:"overspending_mode,omitempty"`
+	BaseCurrency     *Currency         `json:"base_currency,omitempty"`
 }
 
 type RegisterRequest struct {
@@ -46,7 +73,9 @@ type CreateUserRecord struct {
 }
 
 type UpdateUserRecord struct {
-	Username     *string
-	Role         *UserRole
-	PasswordHash *string
+	Username         *string
+	Role             *UserRole
+	PasswordHash     *string
+	OverspendingMode *OverspendingMode
+	BaseCurrency     *Currency
 }
@@ -0,0 +1,73 @@
+package spreadsheet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// dateLayouts — ISO и форматы «день, месяц, год», как в выгрузках российских
+// банков. Форматы с месяцем впереди не поддерживаются: 03/04/2025 иначе
+// читалось бы по-разному в зависимости от порядка перебора.
+var dateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2.1.2006",
+	"2.1.2006 15:04:05",
+	"2.1.2006 15:04",
+	"2.1.06",
+	"2/1/2006",
+	"2/1/2006 15:04",
+	"2/1/06",
+	"2-1-2006",
+}
+
+// ParseDate разбирает дату из ячейки в одном из поддерживаемых форматов.
+func ParseDate(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return time.Time{}, errors.New("date is empty")
+	}
+
+	for _, layout := range dateLayouts {
+		date, err := time.Parse(layout, raw)
+		if err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", raw)
+}
+
+// ParseAmount разбирает ненулевую сумму, допуская пробелы между разрядами,
+// знак валюты и запятую как десятичный разделитель.
+func ParseAmount(raw string) (decimal.Decimal, error) {
+	if raw == "" {
+		return decimal.Zero, errors.New("amount is empty")
+	}
+
+	cleaned := strings.NewReplacer(" ", "", "\u00a0", "", "₽", "", "$", "", "€", "", "руб.", "", "руб", "").Replace(raw)
+	// Запятая — десятичный разделитель, если точки в числе нет
+	if strings.Contains(cleaned, ",") {
+		if strings.Contains(cleaned, ".") {
+			cleaned = strings.ReplaceAll(cleaned, ",", "")
+		} else {
+			cleaned = strings.ReplaceAll(cleaned, ",", ".")
+		}
+	}
+
+	amount, err := decimal.NewFromString(cleaned)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid amount %q", raw)
+	}
+
+	if amount.IsZero() {
+		return decimal.Zero, errors.New("amount is zero")
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,91 @@
+package spreadsheet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestParseDate(t *testing.T) {
+	april3 := time.Date(2025, time.April, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "ISO", raw: "2025-04-03", want: april3},
+		{name: "ISO со временем", raw: "2025-04-03 14:30:00", want: april3.Add(14*time.Hour + 30*time.Minute)},
+		{name: "RFC 3339", raw: "2025-04-03T00:00:00Z", want: april3},
+		{name: "через точку", raw: "03.04.2025", want: april3},
+		{name: "через точку без ведущих нулей", raw: "3.4.2025", want: april3},
+		{name: "через точку со временем", raw: "03.04.2025 14:30", want: april3.Add(14*time.Hour + 30*time.Minute)},
+		{name: "двузначный год", raw: "03.04.25", want: april3},
+		{name: "через косую черту день впереди", raw: "03/04/2025", want: april3},
+		{name: "через косую черту двузначный год", raw: "3/4/25", want: april3},
+		{name: "через дефис день впереди", raw: "03-04-2025", want: april3},
+		{name: "пробелы по краям", raw: " 03.04.2025 ", want: april3},
+		{name: "месяц впереди не читается", raw: "04/13/2025", wantErr: true},
+		{name: "пустая ячейка", raw: "", wantErr: true},
+		{name: "не дата", raw: "вчера", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDate(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseDate(%q) = %v, want error", tt.raw, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseDate(%q) error = %v", tt.raw, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "целое", raw: "1500", want: "1500"},
+		{name: "отрицательное с точкой", raw: "-250.75", want: "-250.75"},
+		{name: "запятая как десятичный разделитель", raw: "250,75", want: "250.75"},
+		{name: "запятая как разделитель разрядов", raw: "1,250.75", want: "1250.75"},
+		{name: "пробелы между разрядами и рубли", raw: "1 250,50 руб.", want: "1250.5"},
+		{name: "неразрывный пробел и знак валюты", raw: "-12\u00a0000 ₽", want: "-12000"},
+		{name: "ноль", raw: "0,00", wantErr: true},
+		{name: "пустая ячейка", raw: "", wantErr: true},
+		{name: "не число", raw: "сто", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAmount(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseAmount(%q) = %v, want error", tt.raw, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseAmount(%q) error = %v", tt.raw, err)
+			}
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("ParseAmount(%q) = %v, want %s", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
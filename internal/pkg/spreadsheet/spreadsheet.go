@@ -0,0 +1,106 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported file format")
+	ErrEmptyFile         = errors.New("file has no rows")
+)
+
+var (
+	zipSignature = []byte("PK\x03\x04")
+	utf8BOM      = []byte("\xEF\xBB\xBF")
+)
+
+// ReadRows возвращает строки первого листа XLSX-файла или CSV-файла.
+// Формат определяется по содержимому, первая строка считается заголовком.
+func ReadRows(data []byte) ([][]string, error) {
+	var (
+		rows [][]string
+		err  error
+	)
+
+	if bytes.HasPrefix(data, zipSignature) {
+		rows, err = readXLSX(data)
+	} else {
+		rows, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for len(rows) > 0 && IsEmptyRow(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return rows, nil
+}
+
+func readXLSX(data []byte) ([][]string, error) {
+	file, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, ErrEmptyFile
+	}
+
+	return file.GetRows(sheets[0])
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	return rows, nil
+}
+
+// detectDelimiter выбирает разделитель по первой строке: выгрузки из
+// русскоязычных банков обычно используют ';', остальные — ','.
+func detectDelimiter(data []byte) rune {
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+
+	best, bestCount := ',', 0
+	for _, delimiter := range []rune{',', ';', '\t'} {
+		count := strings.Count(string(firstLine), string(delimiter))
+		if count > bestCount {
+			best, bestCount = delimiter, count
+		}
+	}
+
+	return best
+}
+
+func IsEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+
+	return true
+}
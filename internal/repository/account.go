@@ -5,6 +5,8 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
 	"litespend-api/internal/model"
+	"litespend-api/internal/repository/databases"
+	"time"
 )
 
 type AccountRepositoryPostgres struct {
@@ -19,16 +21,47 @@ func NewAccountRepositoryPostgres(db *sqlx.DB) AccountRepositoryPostgres {
 	}
 }
 
+// Create заводит счёт и, если задан начальный остаток, проводит его транзакцией
+// в той же транзакции БД.
 func (r AccountRepositoryPostgres) Create(ctx context.Context, account model.CreateAccountRecord) (uint64, error) {
 	var createdID uint64
 
-	err := r.db.GetContext(ctx, &createdID, `
-			INSERT INTO accounts (user_id, name, type, is_archived, order_num, created_at, updated_at) 
-			VALUES ($1, $2, $3, $4, $5, $6, $7) 
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &createdID, `
+			INSERT INTO accounts (user_id, name, type, is_archived, order_num, on_budget, currency, created_at, updated_at) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) 
 			RETURNING id`,
-		account.UserID, account.Name, account.Type, account.IsArchived, account.OrderNum, account.CreatedAt, account.UpdatedAt,
-	)
+			account.UserID, account.Name, account.Type, account.IsArchived, account.OrderNum, account.OnBudget, account.Currency, account.CreatedAt, account.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if account.Type == model.AccountTypeCredit && account.OnBudget {
+			err = insertPaymentCategory(ctx, tx, account.UserID, createdID, account.Name, account.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		if account.Loan != nil {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO loans (account_id, principal, interest_rate, term_months, payment_day, start_date, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $7)`,
+				createdID, account.Loan.Principal, account.Loan.InterestRate, account.Loan.TermMonths, account.Loan.PaymentDay, account.Loan.StartDate, account.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
 
+		if account.StartingBalance == nil {
+			return nil
+		}
+
+		_, err = r.insertBalanceTransaction(ctx, tx, account.UserID, createdID, model.TransactionOriginStartingBalance, *account.StartingBalance, account.CreatedAt)
+		return err
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -36,6 +69,142 @@ func (r AccountRepositoryPostgres) Create(ctx context.Context, account model.Cre
 	return createdID, nil
 }
 
+// AdjustBalance проводит разницу между нужным и текущим балансом счёта. Строка
+// счёта блокируется, чтобы две корректировки подряд не посчитали разницу дважды.
+func (r AccountRepositoryPostgres) AdjustBalance(ctx context.Context, record model.AdjustAccountBalanceRecord) (model.AccountBalanceAdjustment, error) {
+	adjustment := model.AccountBalanceAdjustment{Balance: record.Balance}
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		var userID uint64
+		err := tx.GetContext(ctx, &userID, `SELECT user_id FROM accounts WHERE id = $1 FOR UPDATE`, record.AccountID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.GetContext(ctx, &adjustment.PreviousBalance, `
+			SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE account_id = $1 AND deleted_at IS NULL`, record.AccountID)
+		if err != nil {
+			return err
+		}
+
+		adjustment.Difference = record.Balance.Sub(adjustment.PreviousBalance)
+		if adjustment.Difference.IsZero() {
+			return nil
+		}
+
+		id, err := r.insertBalanceTransaction(ctx, tx, userID, record.AccountID, model.TransactionOriginBalanceAdjustment, model.AccountBalanceRecord{
+			Amount: adjustment.Difference,
+			Date:   record.Date,
+			Note:   record.Note,
+		}, record.CreatedAt)
+		if err != nil {
+			return err
+		}
+		adjustment.TransactionID = &id
+
+		return nil
+	})
+	if err != nil {
+		return model.AccountBalanceAdjustment{}, err
+	}
+
+	return adjustment, nil
+}
+
+// clearedBalanceQuery — остатки счёта $1 на дату $2 включительно.
+const clearedBalanceQuery = `
+	SELECT
+		COALESCE(SUM(amount) FILTER (WHERE cleared), 0) AS cleared,
+		COALESCE(SUM(amount) FILTER (WHERE NOT cleared), 0) AS uncleared,
+		COUNT(*) FILTER (WHERE cleared AND reconciled_at IS NULL) AS unreconciled
+	FROM transactions
+	WHERE account_id = $1 AND date <= $2 AND deleted_at IS NULL`
+
+func (r AccountRepositoryPostgres) GetClearedBalance(ctx context.Context, id uint64, date time.Time) (model.ClearedBalance, error) {
+	var balance model.ClearedBalance
+
+	err := r.db.GetContext(ctx, &balance, clearedBalanceQuery, id, date)
+	if err != nil {
+		return balance, err
+	}
+
+	return balance, nil
+}
+
+// Reconcile сверяет счёт с выпиской: подтверждённые транзакции по дату включительно
+// отмечаются сверенными. Если остаток не сходится, при CreateAdjustment разница
+// проводится подтверждённой корректировкой, иначе ничего не меняется и в результате
+// остаётся ненулевая разница.
+func (r AccountRepositoryPostgres) Reconcile(ctx context.Context, record model.ReconcileAccountRecord) (model.ReconciliationResult, error) {
+	var result model.ReconciliationResult
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		var userID uint64
+		err := tx.GetContext(ctx, &userID, `SELECT user_id FROM accounts WHERE id = $1 FOR UPDATE`, record.AccountID)
+		if err != nil {
+			return err
+		}
+
+		var balance model.ClearedBalance
+		err = tx.GetContext(ctx, &balance, clearedBalanceQuery, record.AccountID, record.Date)
+		if err != nil {
+			return err
+		}
+
+		result.AccountReconciliation = model.NewAccountReconciliation(record.AccountID, record.Date, record.StatementBalance, balance)
+		if !result.Difference.IsZero() {
+			if !record.CreateAdjustment {
+				return nil
+			}
+
+			id, err := r.insertBalanceTransaction(ctx, tx, userID, record.AccountID, model.TransactionOriginBalanceAdjustment, model.AccountBalanceRecord{
+				Amount: result.Difference,
+				Date:   record.Date,
+				Note:   record.AdjustmentNote,
+			}, record.ReconciledAt)
+			if err != nil {
+				return err
+			}
+			result.AdjustmentTransactionID = &id
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			UPDATE transactions SET reconciled_at = $3, updated_at = $3
+			WHERE account_id = $1 AND date <= $2 AND cleared AND reconciled_at IS NULL AND deleted_at IS NULL`,
+			record.AccountID, record.Date, record.ReconciledAt)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		result.Reconciled = int(affected)
+
+		return nil
+	})
+	if err != nil {
+		return model.ReconciliationResult{}, err
+	}
+
+	return result, nil
+}
+
+// insertBalanceTransaction создаёт служебную транзакцию без категории: деньги
+// попадают в To-Be-Budgeted и не считаются тратой или доходом категории.
+func (r AccountRepositoryPostgres) insertBalanceTransaction(ctx context.Context, tx *sqlx.Tx, userID uint64, accountID uint64, origin model.TransactionOrigin, balance model.AccountBalanceRecord, createdAt time.Time) (uint64, error) {
+	var id uint64
+
+	err := tx.GetContext(ctx, &id, `
+		INSERT INTO transactions (user_id, account_id, amount, date, note, cleared, approved, origin, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, true, true, $6, $7, $7)
+		RETURNING id`,
+		userID, accountID, balance.Amount, balance.Date, balance.Note, origin, createdAt,
+	)
+
+	return id, err
+}
+
 func (r AccountRepositoryPostgres) Update(ctx context.Context, id uint64, dto model.UpdateAccountRecord) error {
 	query := r.sq.Update("accounts").Where(sq.Eq{"id": id})
 
@@ -58,21 +227,122 @@ func (r AccountRepositoryPostgres) Update(ctx context.Context, id uint64, dto mo
 		return err
 	}
 
-	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
-	if err != nil {
+	return databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, sqlQuery, args...)
+		if err != nil {
+			return err
+		}
+
+		if dto.Name == nil {
+			return nil
+		}
+
+		// Категория платежа по карте называется так же, как карта
+		_, err = tx.ExecContext(ctx, `
+			UPDATE categories SET name = $2, updated_at = $3 WHERE payment_account_id = $1`,
+			id, *dto.Name, dto.UpdatedAt)
+		return err
+	})
+}
+
+// Delete переносит счёт в корзину. Транзакции в зависимости от режима уходят в
+// корзину с той же отметкой времени, чтобы восстановиться вместе со счётом,
+// либо переносятся на другой счёт.
+func (r AccountRepositoryPostgres) Delete(ctx context.Context, record model.DeleteAccountRecord) error {
+	return databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		switch record.Mode {
+		case model.AccountDeleteModeCascade:
+			// Вторые половины переводов с других счетов удаляются вместе с первыми
+			_, err := tx.ExecContext(ctx, `
+				UPDATE transactions SET deleted_at = $2
+				WHERE deleted_at IS NULL
+				  AND (account_id = $1 OR id IN (
+					SELECT transfer_transaction_id FROM transactions
+					WHERE account_id = $1 AND deleted_at IS NULL AND transfer_transaction_id IS NOT NULL
+				  ))`, record.ID, record.DeletedAt)
+			if err != nil {
+				return err
+			}
+		case model.AccountDeleteModeReassign:
+			_, err := tx.ExecContext(ctx, `
+				UPDATE transactions SET account_id = $2, updated_at = $3
+				WHERE account_id = $1 AND deleted_at IS NULL`, record.ID, *record.TargetAccountID, record.DeletedAt)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE prescribed_expanses SET account_id = $2 WHERE account_id = $1`, record.ID, *record.TargetAccountID)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE categories SET deleted_at = $2 WHERE payment_account_id = $1 AND deleted_at IS NULL`,
+			record.ID, record.DeletedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE accounts SET deleted_at = $2 WHERE id = $1`, record.ID, record.DeletedAt)
 		return err
+	})
+}
+
+// Restore возвращает счёт из корзины вместе с транзакциями, удалёнными каскадно.
+func (r AccountRepositoryPostgres) Restore(ctx context.Context, id uint64) error {
+	return databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE transactions t SET deleted_at = NULL
+			FROM accounts a
+			WHERE a.id = $1
+			  AND t.deleted_at = a.deleted_at
+			  AND (t.account_id = a.id OR t.id IN (
+				SELECT transfer_transaction_id FROM transactions
+				WHERE account_id = a.id AND deleted_at = a.deleted_at AND transfer_transaction_id IS NOT NULL
+			  ))`, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE categories c SET deleted_at = NULL
+			FROM accounts a
+			WHERE a.id = $1 AND c.payment_account_id = a.id AND c.deleted_at = a.deleted_at`, id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE accounts SET deleted_at = NULL WHERE id = $1`, id)
+		return err
+	})
+}
+
+// CountTransactions возвращает количество активных транзакций счёта.
+func (r AccountRepositoryPostgres) CountTransactions(ctx context.Context, id uint64) (int, error) {
+	var count int
+
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM transactions WHERE account_id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return 0, err
 	}
 
-	return nil
+	return count, nil
 }
 
-func (r AccountRepositoryPostgres) Delete(ctx context.Context, id uint64) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM accounts WHERE id = $1`, id)
+// CountTransfersWith считает переводы между счетами id и otherID.
+func (r AccountRepositoryPostgres) CountTransfersWith(ctx context.Context, id uint64, otherID uint64) (int, error) {
+	var count int
+
+	err := r.db.GetContext(ctx, &count, `
+		SELECT COUNT(*) FROM transactions t
+		JOIN transactions p ON p.id = t.transfer_transaction_id
+		WHERE t.account_id = $1 AND p.account_id = $2 AND t.deleted_at IS NULL`, id, otherID)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return count, nil
 }
 
 func (r AccountRepositoryPostgres) GetByID(ctx context.Context, id uint64) (model.Account, error) {
@@ -80,8 +350,23 @@ func (r AccountRepositoryPostgres) GetByID(ctx context.Context, id uint64) (mode
 
 	err := r.db.GetContext(ctx, &account, `
 		SELECT a.*, COALESCE(SUM(tr.amount), 0) as balance FROM accounts a
-		         LEFT JOIN transactions tr ON a.id = tr.account_id
-		WHERE a.id = $1 GROUP BY a.id`, id)
+		         LEFT JOIN transactions tr ON a.id = tr.account_id AND tr.deleted_at IS NULL
+		WHERE a.id = $1 AND a.deleted_at IS NULL GROUP BY a.id`, id)
+	if err != nil {
+		return account, err
+	}
+
+	return account, nil
+}
+
+// GetDeletedByID возвращает счёт из корзины.
+func (r AccountRepositoryPostgres) GetDeletedByID(ctx context.Context, id uint64) (model.Account, error) {
+	var account model.Account
+
+	err := r.db.GetContext(ctx, &account, `
+		SELECT a.*, COALESCE(SUM(tr.amount), 0) as balance FROM accounts a
+		         LEFT JOIN transactions tr ON a.id = tr.account_id AND tr.deleted_at = a.deleted_at
+		WHERE a.id = $1 AND a.deleted_at IS NOT NULL GROUP BY a.id`, id)
 	if err != nil {
 		return account, err
 	}
@@ -94,8 +379,8 @@ func (r AccountRepositoryPostgres) GetList(ctx context.Context, userID uint64) (
 
 	err := r.db.SelectContext(ctx, &accounts, `
 		SELECT a.*, COALESCE(SUM(tr.amount), 0) as balance FROM accounts a 
-		         LEFT JOIN transactions tr ON a.id = tr.account_id
-		WHERE a.user_id = $1 GROUP BY a.id
+		         LEFT JOIN transactions tr ON a.id = tr.account_id AND tr.deleted_at IS NULL
+		WHERE a.user_id = $1 AND a.deleted_at IS NULL GROUP BY a.id
 		ORDER BY a.order_num, a.name`, userID)
 	if err != nil {
 		return accounts, err
@@ -103,3 +388,16 @@ func (r AccountRepositoryPostgres) GetList(ctx context.Context, userID uint64) (
 
 	return accounts, nil
 }
+
+// insertPaymentCategory создаёт категорию платежа по кредитной карте в конце
+// категорий без группы.
+func insertPaymentCategory(ctx context.Context, tx *sqlx.Tx, userID uint64, accountID uint64, name string, createdAt time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO categories (user_id, name, payment_account_id, sort_order, created_at, updated_at)
+		VALUES ($1, $2, $3, (
+			SELECT COALESCE(MAX(c.sort_order) + 1, 0) FROM categories c
+			WHERE c.user_id = $1 AND c.group_id IS NULL AND c.deleted_at IS NULL
+		), $4, $4)`,
+		userID, name, accountID, createdAt)
+	return err
+}
@@ -2,6 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
 	"litespend-api/internal/model"
 	"litespend-api/internal/repository/databases"
@@ -11,6 +15,51 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// categoryAmountsQuery — суммы по категориям на бюджетных счетах: обычные транзакции
+// и строки разбивки. Категория у перевода бывает только на бюджетной половине
+// перевода на внебюджетный счёт или с него, такой перевод — трата или доход бюджета.
+// Суммы пересчитаны в базовую валюту по курсу на дату транзакции, суммы в валюте
+// без курса пропускаются.
+const categoryAmountsQuery = `
+	SELECT m.user_id, m.account_id, m.category_id, ROUND(m.amount * fx.rate, 2) AS amount, m.date
+	FROM (
+		SELECT t.user_id, t.account_id, t.category_id, a.currency, t.amount, t.date
+		FROM transactions t
+		JOIN accounts a ON a.id = t.account_id AND a.on_budget
+		WHERE t.category_id IS NOT NULL
+			AND t.deleted_at IS NULL
+		UNION ALL
+		SELECT t.user_id, t.account_id, s.category_id, a.currency, s.amount, t.date
+		FROM transaction_splits s
+		JOIN transactions t ON t.id = s.transaction_id
+		JOIN accounts a ON a.id = t.account_id AND a.on_budget
+		WHERE t.deleted_at IS NULL
+	) m
+	JOIN LATERAL (
+		SELECT base_currency_rate(m.user_id, m.currency, m.date) AS rate
+	) fx ON fx.rate IS NOT NULL`
+
+// paymentActivityQuery — движения категорий платежа по кредитным картам. Траты
+// по карте с категорией переносят деньги в категорию платежа (отрицательная
+// сумма увеличивает доступное), перевод на карту с бюджетного счёта — платёж,
+// он доступное расходует. Движения в валюте без курса пропускаются.
+const paymentActivityQuery = `
+	SELECT t.user_id, c.id AS category_id, ROUND(t.amount * fx.rate, 2) AS amount, t.date
+	FROM transactions t
+	JOIN accounts a ON a.id = t.account_id AND a.type = 'credit' AND a.on_budget
+	JOIN categories c ON c.payment_account_id = a.id AND c.deleted_at IS NULL
+	JOIN LATERAL (
+		SELECT base_currency_rate(t.user_id, a.currency, t.date) AS rate
+	) fx ON fx.rate IS NOT NULL
+	WHERE t.deleted_at IS NULL
+		AND (t.category_id IS NOT NULL
+			OR EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+			OR EXISTS (
+				SELECT 1 FROM transactions p
+				JOIN accounts pa ON pa.id = p.account_id AND pa.on_budget
+				WHERE p.id = t.transfer_transaction_id
+			))`
+
 type BudgetRepositoryPostgres struct {
 	db *sqlx.DB
 	sq sq.StatementBuilderType
@@ -23,15 +72,23 @@ func NewBudgetRepositoryPostgres(db *sqlx.DB) BudgetRepositoryPostgres {
 	}
 }
 
+// Create добавляет назначение и возвращает его id, 0 — если назначение категории
+// за этот месяц уже есть.
 func (r BudgetRepositoryPostgres) Create(ctx context.Context, record model.CreateBudgetAllocationRecord) (int, error) {
 	var createdID int
 
 	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
 		err := tx.GetContext(ctx, &createdID,
 			`INSERT INTO budget_allocations(user_id, category_id, year, month, assigned, created_at) 
-			 VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+			 VALUES ($1, $2, $3, $4, $5, $6)
+			 ON CONFLICT (user_id, category_id, year, month) DO NOTHING
+			 RETURNING id`,
 			record.UserID, record.CategoryID, record.Year, record.Month, record.Assigned, record.CreatedAt,
 		)
+		// Назначение за этот месяц уже есть
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
 		if err != nil {
 			return err
 		}
@@ -44,6 +101,147 @@ func (r BudgetRepositoryPostgres) Create(ctx context.Context, record model.Creat
 	return createdID, nil
 }
 
+// Assign прибавляет сумму к назначенному в категории за месяц, создавая запись при необходимости.
+func (r BudgetRepositoryPostgres) Assign(ctx context.Context, record model.AssignBudgetRecord) (model.BudgetAllocation, error) {
+	var allocation model.BudgetAllocation
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		var err error
+		allocation, err = r.assign(ctx, tx, record)
+		return err
+	})
+	if err != nil {
+		return allocation, err
+	}
+
+	return allocation, nil
+}
+
+func (r BudgetRepositoryPostgres) assign(ctx context.Context, tx *sqlx.Tx, record model.AssignBudgetRecord) (model.BudgetAllocation, error) {
+	var allocation model.BudgetAllocation
+
+	err := tx.GetContext(ctx, &allocation,
+		`INSERT INTO budget_allocations(user_id, category_id, year, month, assigned, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $6)
+		 ON CONFLICT (user_id, category_id, year, month)
+		 DO UPDATE SET assigned = budget_allocations.assigned + EXCLUDED.assigned, updated_at = EXCLUDED.updated_at
+		 RETURNING *`,
+		record.UserID, record.CategoryID, record.Year, record.Month, record.Amount, record.UpdatedAt,
+	)
+
+	return allocation, err
+}
+
+// MoveMoney переносит назначенную сумму из одной категории в другую и записывает перемещение в историю.
+func (r BudgetRepositoryPostgres) MoveMoney(ctx context.Context, record model.CreateBudgetMoveRecord) (model.BudgetMove, error) {
+	var move model.BudgetMove
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		_, err := r.assign(ctx, tx, model.AssignBudgetRecord{
+			UserID:     record.UserID,
+			CategoryID: record.FromCategoryID,
+			Year:       record.Year,
+			Month:      record.Month,
+			Amount:     record.Amount.Neg(),
+			UpdatedAt:  record.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = r.assign(ctx, tx, model.AssignBudgetRecord{
+			UserID:     record.UserID,
+			CategoryID: record.ToCategoryID,
+			Year:       record.Year,
+			Month:      record.Month,
+			Amount:     record.Amount,
+			UpdatedAt:  record.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return tx.GetContext(ctx, &move,
+			`INSERT INTO budget_moves(user_id, year, month, from_category_id, to_category_id, amount, note, created_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *`,
+			record.UserID, record.Year, record.Month, record.FromCategoryID, record.ToCategoryID, record.Amount, record.Note, record.CreatedAt,
+		)
+	})
+	if err != nil {
+		return move, err
+	}
+
+	return move, nil
+}
+
+func (r BudgetRepositoryPostgres) GetMoves(ctx context.Context, userID uint64, year uint, month uint) ([]model.BudgetMove, error) {
+	var moves []model.BudgetMove = make([]model.BudgetMove, 0)
+
+	err := r.db.SelectContext(ctx, &moves, `
+		SELECT * FROM budget_moves
+		WHERE user_id = $1 AND year = $2 AND month = $3
+		ORDER BY created_at DESC, id DESC`, userID, year, month)
+	if err != nil {
+		return moves, err
+	}
+
+	return moves, nil
+}
+
+// QuickAssign выставляет назначенные суммы месяца по назначенному или потраченному в исходном месяце.
+func (r BudgetRepositoryPostgres) QuickAssign(ctx context.Context, record model.QuickAssignRecord) (int, error) {
+	var source string
+
+	switch record.Source {
+	case model.QuickAssignSourceLastMonthAssigned:
+		source = `
+			SELECT ba.category_id, ba.assigned AS amount
+			FROM budget_allocations ba
+			WHERE ba.user_id = $1 AND ba.year = $4 AND ba.month = $5`
+	case model.QuickAssignSourceLastMonthSpent:
+		// Потраченное — чистая сумма за месяц с учётом возвратов, но не меньше нуля
+		source = `
+			SELECT ca.category_id, GREATEST(SUM(ca.amount) * -1, 0) AS amount
+			FROM (` + categoryAmountsQuery + `) ca
+			WHERE ca.user_id = $1
+				AND ca.date >= make_date($4::int, $5::int, 1)
+				AND ca.date < make_date($4::int, $5::int, 1) + interval '1 month'
+			GROUP BY ca.category_id`
+	default:
+		return 0, fmt.Errorf("unknown quick assign source %q", record.Source)
+	}
+
+	var updated int
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO budget_allocations(user_id, category_id, year, month, assigned, created_at, updated_at)
+			SELECT $1, src.category_id, $2, $3, src.amount, $6, $6
+			FROM (`+source+`) src
+			JOIN categories c ON c.id = src.category_id AND c.user_id = $1 AND c.deleted_at IS NULL
+			ON CONFLICT (user_id, category_id, year, month)
+			DO UPDATE SET assigned = EXCLUDED.assigned, updated_at = EXCLUDED.updated_at`,
+			record.UserID, record.Year, record.Month, record.SourceYear, record.SourceMonth, record.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		updated = int(affected)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return updated, nil
+}
+
 func (r BudgetRepositoryPostgres) Update(ctx context.Context, id int, dto model.UpdateBudgetAllocationRequest) error {
 	query := r.sq.Update("budget_allocations").Where(sq.Eq{"id": id})
 
@@ -99,147 +297,327 @@ func (r BudgetRepositoryPostgres) GetList(ctx context.Context, userID uint64) ([
 	return items, nil
 }
 
-func (r BudgetRepositoryPostgres) GetListDetailedByPeriod(ctx context.Context, userID uint64, year uint64, month uint64) (model.CategoryBudgetResponse, error) {
-	query := `
-		WITH target AS (
-			SELECT
-				$1::int AS target_year,
-				$2::int AS target_month,
-				$3::bigint AS user_id
-		),
-		month_bounds AS (
-			SELECT
-				make_date(t.target_year, t.target_month, 1)                                      AS month_start,
-				(make_date(t.target_year, t.target_month, 1) + interval '1 month' - interval '1 day') AS month_end
-			FROM target t
-		),
-		prev_month AS (
-			SELECT
-				CASE WHEN t.target_month = 1 THEN t.target_year - 1 ELSE t.target_year END      AS prev_year,
-				CASE WHEN t.target_month = 1 THEN 12 ELSE t.target_month - 1 END               AS prev_month
-			FROM target t
-		),
-		prev_bounds AS (
-			SELECT
-				make_date(pm.prev_year, pm.prev_month, 1)                                      AS prev_start,
-				(make_date(pm.prev_year, pm.prev_month, 1) + interval '1 month' - interval '1 day') AS prev_end
-			FROM prev_month pm
-		),
-		account_balances AS (
-			SELECT COALESCE(SUM(t.amount), 0)::numeric AS total_balance
-			FROM transactions t
-			WHERE t.user_id = (SELECT user_id FROM target)
-		),
-		total_assigned AS (
-			SELECT COALESCE(SUM(ba.assigned), 0)::numeric AS assigned_sum
-			FROM budget_allocations ba, target t
-			WHERE ba.user_id = t.user_id
-				AND ba.year = t.target_year
-				AND ba.month = t.target_month
-		),
-		category_data AS (
-			SELECT
-				c.id                                      AS category_id,
-				c.name                                    AS category_name,
-				COALESCE(c.group_name, '')                AS category_group_name,
-				COALESCE(ba.assigned, 0)::numeric         AS assigned,
-
-				-- Потрачено в текущем месяце
-				COALESCE((
-					SELECT SUM(t.amount) * -1
-					FROM transactions t
-					CROSS JOIN month_bounds mb
-					WHERE t.user_id = tar.user_id
-						AND t.category_id = c.id
-						AND t.amount < 0
-						AND t.date BETWEEN mb.month_start AND mb.month_end
-				), 0)::numeric AS spent,
-
-				-- Перенос с прошлого месяца (assigned - spent)
-				COALESCE((
-					SELECT COALESCE(SUM(prev_ba.assigned), 0) - COALESCE(SUM(prev_t.amount * -1), 0)
-					FROM budget_allocations prev_ba
-					LEFT JOIN transactions prev_t
-						ON prev_t.category_id = c.id
-						AND prev_t.user_id = tar.user_id
-						AND prev_t.amount < 0
-						AND prev_t.date BETWEEN pb.prev_start AND pb.prev_end
-					CROSS JOIN prev_bounds pb
-					WHERE prev_ba.user_id = tar.user_id
-						AND prev_ba.category_id = c.id
-						AND prev_ba.year = pm.prev_year
-						AND prev_ba.month = pm.prev_month
-				), 0)::numeric AS carried_over
-
-			FROM categories c
-			CROSS JOIN target tar
-			CROSS JOIN prev_month pm
-			CROSS JOIN prev_bounds pb
-			LEFT JOIN budget_allocations ba
-				ON ba.category_id = c.id
-				AND ba.user_id = tar.user_id
-				AND ba.year = tar.target_year
-				AND ba.month = tar.target_month
-			WHERE c.user_id = tar.user_id
-		)
+// categoryMonth — назначенное и потраченное по категории за один месяц.
+type categoryMonth struct {
+	CategoryID uint64          `db:"category_id"`
+	Year       int             `db:"year"`
+	Month      int             `db:"month"`
+	Assigned   decimal.Decimal `db:"assigned"`
+	Spent      decimal.Decimal `db:"spent"`
+}
+
+type budgetGroup struct {
+	ID       uint64 `db:"id"`
+	Name     string `db:"name"`
+	IsHidden bool   `db:"is_hidden"`
+}
+
+type budgetCategory struct {
+	ID               uint64                    `db:"id"`
+	Name             string                    `db:"name"`
+	GroupID          *uint64                   `db:"group_id"`
+	IsHidden         bool                      `db:"is_hidden"`
+	TargetType       *model.CategoryTargetType `db:"target_type"`
+	TargetAmount     *decimal.Decimal          `db:"target_amount"`
+	TargetDate       *time.Time                `db:"target_date"`
+	PaymentAccountID *uint64                   `db:"payment_account_id"`
+}
+
+// creditCard — бюджетная кредитная карта с балансом на конец месяца.
+type creditCard struct {
+	AccountID  uint64          `db:"account_id"`
+	Name       string          `db:"name"`
+	CategoryID uint64          `db:"category_id"`
+	Balance    decimal.Decimal `db:"balance"`
+}
+
+func (r BudgetRepositoryPostgres) GetListDetailedByPeriod(ctx context.Context, userID uint64, year uint64, month uint64, mode model.OverspendingMode) (model.CategoryBudgetResponse, error) {
+	var groups []budgetGroup
+	err := r.db.SelectContext(ctx, &groups, `
+		SELECT id, name, is_hidden FROM category_groups
+		WHERE user_id = $1
+		ORDER BY sort_order, id`, userID)
+	if err != nil {
+		return model.CategoryBudgetResponse{}, err
+	}
+
+	var categories []budgetCategory
+	err = r.db.SelectContext(ctx, &categories, `
+		SELECT id, name, group_id, is_hidden, target_type, target_amount, target_date, payment_account_id
+		FROM categories
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY sort_order, name`, userID)
+	if err != nil {
+		return model.CategoryBudgetResponse{}, err
+	}
+
+	// Назначения и траты агрегируются отдельно, чтобы несколько записей
+	// в одном месяце не размножали друг друга
+	var months []categoryMonth
+	err = r.db.SelectContext(ctx, &months, `
+		SELECT m.category_id, m.year, m.month, SUM(m.assigned) AS assigned, SUM(m.spent) AS spent
+		FROM (
+			SELECT ba.category_id, ba.year, ba.month, ba.assigned, 0::numeric AS spent
+			FROM budget_allocations ba
+			WHERE ba.user_id = $1
+			UNION ALL
+			SELECT ca.category_id,
+				EXTRACT(YEAR FROM ca.date)::int AS year,
+				EXTRACT(MONTH FROM ca.date)::int AS month,
+				0::numeric AS assigned,
+				ca.amount * -1 AS spent
+			FROM (`+categoryAmountsQuery+`) ca
+			WHERE ca.user_id = $1
+				AND ca.amount < 0
+			UNION ALL
+			SELECT pa.category_id,
+				EXTRACT(YEAR FROM pa.date)::int AS year,
+				EXTRACT(MONTH FROM pa.date)::int AS month,
+				0::numeric AS assigned,
+				pa.amount AS spent
+			FROM (`+paymentActivityQuery+`) pa
+			WHERE pa.user_id = $1
+		) m
+		WHERE (m.year, m.month) <= ($2::int, $3::int)
+		GROUP BY m.category_id, m.year, m.month
+		ORDER BY m.year, m.month`, userID, year, month)
+	if err != nil {
+		return model.CategoryBudgetResponse{}, err
+	}
+
+	// Долг по кредитным картам покрывается категориями платежа, поэтому баланс
+	// карт в To-Be-Budgeted не входит
+	var totals struct {
+		Balance        decimal.Decimal `db:"balance"`
+		FutureAssigned decimal.Decimal `db:"future_assigned"`
+	}
+	err = r.db.GetContext(ctx, &totals, `
 		SELECT
-			(ab.total_balance - ta.assigned_sum)::numeric AS tbb,
-			cd.category_id,
-			cd.category_name,
-			cd.category_group_name,
-			cd.assigned,
-			cd.spent,
-			(cd.assigned + cd.carried_over - cd.spent)::numeric AS available,
-			cd.carried_over
-		FROM category_data cd
-		CROSS JOIN account_balances ab
-		CROSS JOIN total_assigned ta
-		ORDER BY cd.category_name;
-	`
-
-	rows, err := r.db.QueryxContext(ctx, query, year, month, userID)
+			COALESCE((
+				SELECT SUM(to_base_currency(t.user_id, a.currency, t.amount, t.date))
+				FROM transactions t
+				JOIN accounts a ON a.id = t.account_id AND a.on_budget AND a.type <> 'credit'
+				WHERE t.user_id = $1
+					AND t.deleted_at IS NULL
+					AND t.date < make_date($2::int, $3::int, 1) + interval '1 month'
+			), 0)::numeric AS balance,
+			COALESCE((
+				SELECT SUM(ba.assigned)
+				FROM budget_allocations ba
+				JOIN categories c ON c.id = ba.category_id AND c.deleted_at IS NULL
+				WHERE ba.user_id = $1
+					AND (ba.year, ba.month) > ($2::int, $3::int)
+			), 0)::numeric AS future_assigned`, userID, year, month)
+	if err != nil {
+		return model.CategoryBudgetResponse{}, err
+	}
+
+	var cards []creditCard
+	err = r.db.SelectContext(ctx, &cards, `
+		SELECT a.id AS account_id, a.name, c.id AS category_id,
+			COALESCE(SUM(to_base_currency(t.user_id, a.currency, t.amount, t.date)), 0)::numeric AS balance
+		FROM accounts a
+		JOIN categories c ON c.payment_account_id = a.id AND c.deleted_at IS NULL
+		LEFT JOIN transactions t ON t.account_id = a.id
+			AND t.deleted_at IS NULL
+			AND t.date < make_date($2::int, $3::int, 1) + interval '1 month'
+		WHERE a.user_id = $1
+			AND a.type = 'credit'
+			AND a.on_budget
+			AND a.deleted_at IS NULL
+		GROUP BY a.id, a.name, c.id
+		ORDER BY a.order_num, a.id`, userID, year, month)
 	if err != nil {
 		return model.CategoryBudgetResponse{}, err
 	}
-	defer rows.Close()
-
-	response := model.CategoryBudgetResponse{}
-
-	first := true
-	for rows.Next() {
-		var cat model.CategoryBudget
-		var tbb decimal.Decimal
-
-		if first {
-			err = rows.Scan(
-				&tbb,
-				&cat.CategoryID,
-				&cat.Name,
-				&cat.GroupName,
-				&cat.Assigned,
-				&cat.Spent,
-				&cat.Available,
-				&cat.CarriedOver,
-			)
-			first = false
-		} else {
-			err = rows.Scan(
-				new(float64), // пропускаем tbb
-				&cat.CategoryID,
-				&cat.Name,
-				&cat.GroupName,
-				&cat.Assigned,
-				&cat.Spent,
-				&cat.Available,
-				&cat.CarriedOver,
-			)
+
+	return calculateBudget(groups, categories, months, cards, totals.Balance, totals.FutureAssigned, int(year), int(month), mode), nil
+}
+
+// calculateBudget считает накопительный остаток каждой категории: всё назначенное
+// минус всё потраченное до конца месяца. Перерасход в зависимости от mode либо
+// обнуляется в начале следующего месяца за счёт To-Be-Budgeted, либо остаётся
+// долгом категории. months должны быть отсортированы по году и месяцу.
+// Категории раскладываются по группам в порядке groups, категории без группы
+// идут последней группой. Для карт из cards, долг по которым больше доступного
+// в категории платежа, добавляется предупреждение.
+func calculateBudget(groups []budgetGroup, categories []budgetCategory, months []categoryMonth, cards []creditCard, balance decimal.Decimal, futureAssigned decimal.Decimal, year int, month int, mode model.OverspendingMode) model.CategoryBudgetResponse {
+	type state struct {
+		available decimal.Decimal
+		carried   decimal.Decimal
+		assigned  decimal.Decimal
+		spent     decimal.Decimal
+		started   bool
+	}
+
+	target := year*12 + month
+	states := make(map[uint64]*state, len(categories))
+	for _, category := range categories {
+		states[category.ID] = &state{}
+	}
+
+	// startMonth переносит остаток на новый месяц с учётом режима перерасхода
+	startMonth := func(st *state) {
+		if mode != model.OverspendingModeCategoryDebt && st.available.IsNegative() {
+			st.available = decimal.Zero
 		}
-		if err != nil {
-			return response, err
+		st.carried = st.available
+	}
+
+	for _, m := range months {
+		st, ok := states[m.CategoryID]
+		if !ok {
+			continue
+		}
+
+		startMonth(st)
+		st.available = st.available.Add(m.Assigned).Sub(m.Spent)
+
+		if m.Year*12+m.Month == target {
+			st.assigned = m.Assigned
+			st.spent = m.Spent
+			st.started = true
+		}
+	}
+
+	// Итоги групп копятся в decimal и переводятся в float64 в самом конце
+	type groupTotals struct {
+		budget    model.CategoryGroupBudget
+		assigned  decimal.Decimal
+		spent     decimal.Decimal
+		available decimal.Decimal
+		needed    decimal.Decimal
+	}
+
+	grouped := make([]*groupTotals, 0, len(groups)+1)
+	byGroup := make(map[uint64]*groupTotals, len(groups))
+	for _, group := range groups {
+		totals := &groupTotals{budget: model.CategoryGroupBudget{
+			GroupID:    &group.ID,
+			Name:       group.Name,
+			IsHidden:   group.IsHidden,
+			Categories: make([]model.CategoryBudget, 0),
+		}}
+		grouped = append(grouped, totals)
+		byGroup[group.ID] = totals
+	}
+	ungrouped := &groupTotals{budget: model.CategoryGroupBudget{Categories: make([]model.CategoryBudget, 0)}}
+
+	var response model.CategoryBudgetResponse
+
+	totalAvailable := decimal.Zero
+	for _, category := range categories {
+		st := states[category.ID]
+
+		// Если в целевом месяце движений не было, он ещё не начат
+		if !st.started {
+			startMonth(st)
+		}
+
+		target := model.Category{
+			TargetType:   category.TargetType,
+			TargetAmount: category.TargetAmount,
+			TargetDate:   category.TargetDate,
+		}.Target()
+
+		needed := decimal.Zero
+		if target != nil {
+			needed = targetNeeded(*target, st.carried, st.assigned, st.spent, year, month)
+		}
+
+		totalAvailable = totalAvailable.Add(st.available)
+		response.Underfunded = response.Underfunded.Add(needed)
+
+		group := ungrouped
+		if category.GroupID != nil {
+			if found, ok := byGroup[*category.GroupID]; ok {
+				group = found
+			}
+		}
+
+		group.assigned = group.assigned.Add(st.assigned)
+		group.spent = group.spent.Add(st.spent)
+		group.available = group.available.Add(st.available)
+		group.needed = group.needed.Add(needed)
+		group.budget.Categories = append(group.budget.Categories, model.CategoryBudget{
+			CategoryID:  int64(category.ID),
+			Name:        category.Name,
+			IsHidden:    category.IsHidden,
+			Assigned:    st.assigned.InexactFloat64(),
+			Spent:       st.spent.InexactFloat64(),
+			Available:   st.available.InexactFloat64(),
+			CarriedOver: st.carried.InexactFloat64(),
+			Target:      target,
+			Needed:      needed.InexactFloat64(),
+
+			PaymentAccountID: category.PaymentAccountID,
+		})
+	}
+
+	response.CreditCardWarnings = make([]model.CreditCardWarning, 0)
+	for _, card := range cards {
+		st, ok := states[card.CategoryID]
+		if !ok {
+			continue
+		}
+
+		debt := card.Balance.Neg()
+		if !debt.IsPositive() || debt.LessThanOrEqual(st.available) {
+			continue
 		}
 
-		response.ToBeBudgeted = tbb
-		response.Categories = append(response.Categories, cat)
+		response.CreditCardWarnings = append(response.CreditCardWarnings, model.CreditCardWarning{
+			AccountID:   card.AccountID,
+			AccountName: card.Name,
+			CategoryID:  card.CategoryID,
+			Debt:        debt,
+			Available:   st.available,
+			Shortfall:   debt.Sub(decimal.Max(st.available, decimal.Zero)),
+		})
+	}
+
+	if len(ungrouped.budget.Categories) > 0 {
+		grouped = append(grouped, ungrouped)
 	}
-	return response, nil
+
+	response.Groups = make([]model.CategoryGroupBudget, 0, len(grouped))
+	for _, group := range grouped {
+		group.budget.Assigned = group.assigned.InexactFloat64()
+		group.budget.Spent = group.spent.InexactFloat64()
+		group.budget.Available = group.available.InexactFloat64()
+		group.budget.Needed = group.needed.InexactFloat64()
+		response.Groups = append(response.Groups, group.budget)
+	}
+
+	response.ToBeBudgeted = balance.Sub(totalAvailable).Sub(futureAssigned)
+
+	return response
+}
+
+// targetNeeded возвращает, сколько ещё нужно назначить в месяце, чтобы идти по цели.
+// carried — остаток категории на начало месяца.
+func targetNeeded(target model.CategoryTarget, carried decimal.Decimal, assigned decimal.Decimal, spent decimal.Decimal, year int, month int) decimal.Decimal {
+	var needed decimal.Decimal
+
+	switch target.Type {
+	case model.CategoryTargetMonthlyFunding:
+		needed = target.Amount.Sub(assigned)
+	case model.CategoryTargetBalance:
+		needed = target.Amount.Sub(carried).Sub(assigned)
+	case model.CategoryTargetBalanceByDate:
+		monthsLeft := int64(1)
+		if target.Date != nil {
+			monthsLeft = max(int64(target.Date.Year()*12+int(target.Date.Month())-(year*12+month)+1), 1)
+		}
+		perMonth := target.Amount.Sub(carried).Div(decimal.NewFromInt(monthsLeft)).RoundCeil(2)
+		needed = perMonth.Sub(assigned)
+	case model.CategoryTargetSpendingCap:
+		needed = decimal.Min(spent, target.Amount).Sub(carried).Sub(assigned)
+	}
+
+	if needed.IsNegative() {
+		return decimal.Zero
+	}
+
+	return needed
 }
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"litespend-api/internal/model"
+
+	"github.com/shopspring/decimal"
+)
+
+func month(categoryID uint64, year int, m int, assigned int64, spent int64) categoryMonth {
+	return categoryMonth{
+		CategoryID: categoryID,
+		Year:       year,
+		Month:      m,
+		Assigned:   decimal.NewFromInt(assigned),
+		Spent:      decimal.NewFromInt(spent),
+	}
+}
+
+func TestCalculateBudget(t *testing.T) {
+	categories := []budgetCategory{
+		{ID: 1, Name: "Еда"},
+		{ID: 2, Name: "Транспорт"},
+	}
+
+	type expected struct {
+		assigned    float64
+		spent       float64
+		available   float64
+		carriedOver float64
+	}
+
+	tests := []struct {
+		name           string
+		months         []categoryMonth
+		balance        int64
+		futureAssigned int64
+		year           int
+		month          int
+		mode           model.OverspendingMode
+		want           map[uint64]expected
+		wantTBB        int64
+	}{
+		{
+			name: "остаток накапливается за несколько месяцев через границу года",
+			months: []categoryMonth{
+				month(1, 2024, 11, 100, 30),
+				month(1, 2024, 12, 100, 50),
+				month(1, 2025, 1, 100, 20),
+			},
+			balance: 1000,
+			year:    2025,
+			month:   1,
+			mode:    model.OverspendingModeToBeBudgeted,
+			want: map[uint64]expected{
+				1: {assigned: 100, spent: 20, available: 200, carriedOver: 120},
+				2: {},
+			},
+			wantTBB: 800,
+		},
+		{
+			name: "месяц без движений переносит остаток декабря в январь",
+			months: []categoryMonth{
+				month(1, 2024, 12, 100, 40),
+			},
+			balance: 1000,
+			year:    2025,
+			month:   1,
+			mode:    model.OverspendingModeToBeBudgeted,
+			want: map[uint64]expected{
+				1: {available: 60, carriedOver: 60},
+				2: {},
+			},
+			wantTBB: 940,
+		},
+		{
+			name: "перерасход декабря уменьшает To-Be-Budgeted января",
+			months: []categoryMonth{
+				month(1, 2024, 12, 100, 150),
+				month(1, 2025, 1, 50, 10),
+			},
+			balance: 850,
+			year:    2025,
+			month:   1,
+			mode:    model.OverspendingModeToBeBudgeted,
+			want: map[uint64]expected{
+				1: {assigned: 50, spent: 10, available: 40, carriedOver: 0},
+				2: {},
+			},
+			wantTBB: 810,
+		},
+		{
+			name: "перерасход декабря остаётся долгом категории в январе",
+			months: []categoryMonth{
+				month(1, 2024, 12, 100, 150),
+				month(1, 2025, 1, 50, 10),
+			},
+			balance: 850,
+			year:    2025,
+			month:   1,
+			mode:    model.OverspendingModeCategoryDebt,
+			want: map[uint64]expected{
+				1: {assigned: 50, spent: 10, available: -10, carriedOver: -50},
+				2: {},
+			},
+			wantTBB: 860,
+		},
+		{
+			name: "перерасход текущего месяца не переносится до его окончания",
+			months: []categoryMonth{
+				month(1, 2024, 12, 100, 150),
+			},
+			balance: 850,
+			year:    2024,
+			month:   12,
+			mode:    model.OverspendingModeToBeBudgeted,
+			want: map[uint64]expected{
+				1: {assigned: 100, spent: 150, available: -50, carriedOver: 0},
+				2: {},
+			},
+			wantTBB: 900,
+		},
+		{
+			name: "назначения будущих месяцев уменьшают To-Be-Budgeted",
+			months: []categoryMonth{
+				month(2, 2025, 12, 200, 0),
+			},
+			balance:        1000,
+			futureAssigned: 300,
+			year:           2025,
+			month:          12,
+			mode:           model.OverspendingModeToBeBudgeted,
+			want: map[uint64]expected{
+				1: {},
+				2: {assigned: 200, available: 200},
+			},
+			wantTBB: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateBudget(nil, categories, tt.months, nil, decimal.NewFromInt(tt.balance), decimal.NewFromInt(tt.futureAssigned), tt.year, tt.month, tt.mode)
+
+			if !got.ToBeBudgeted.Equal(decimal.NewFromInt(tt.wantTBB)) {
+				t.Errorf("to_be_budgeted = %s, want %d", got.ToBeBudgeted, tt.wantTBB)
+			}
+
+			if len(got.Groups) != 1 || len(got.Groups[0].Categories) != len(categories) {
+				t.Fatalf("got %+v, want one group with %d categories", got.Groups, len(categories))
+			}
+
+			for _, category := range got.Groups[0].Categories {
+				want := tt.want[uint64(category.CategoryID)]
+				gotValues := expected{
+					assigned:    category.Assigned,
+					spent:       category.Spent,
+					available:   category.Available,
+					carriedOver: category.CarriedOver,
+				}
+				if gotValues != want {
+					t.Errorf("category %d = %+v, want %+v", category.CategoryID, gotValues, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateBudgetGroups(t *testing.T) {
+	housing, daily, empty := uint64(10), uint64(20), uint64(30)
+	groups := []budgetGroup{
+		{ID: daily, Name: "Повседневные"},
+		{ID: housing, Name: "Жильё", IsHidden: true},
+		{ID: empty, Name: "Пустая"},
+	}
+	categories := []budgetCategory{
+		{ID: 1, Name: "Аренда", GroupID: &housing},
+		{ID: 2, Name: "Еда", GroupID: &daily},
+		{ID: 3, Name: "Коммуналка", GroupID: &housing},
+		{ID: 4, Name: "Разное"},
+	}
+	months := []categoryMonth{
+		month(1, 2025, 1, 500, 500),
+		month(2, 2025, 1, 300, 120),
+		month(3, 2025, 1, 100, 40),
+		month(4, 2025, 1, 50, 0),
+	}
+
+	got := calculateBudget(groups, categories, months, nil, decimal.NewFromInt(1000), decimal.Zero, 2025, 1, model.OverspendingModeToBeBudgeted)
+
+	type groupSummary struct {
+		name       string
+		categories []int64
+		assigned   float64
+		spent      float64
+		available  float64
+	}
+
+	want := []groupSummary{
+		{name: "Повседневные", categories: []int64{2}, assigned: 300, spent: 120, available: 180},
+		{name: "Жильё", categories: []int64{1, 3}, assigned: 600, spent: 540, available: 60},
+		{name: "Пустая", categories: []int64{}},
+		{name: "", categories: []int64{4}, assigned: 50, available: 50},
+	}
+
+	if len(got.Groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(got.Groups), len(want))
+	}
+
+	for i, group := range got.Groups {
+		ids := make([]int64, 0, len(group.Categories))
+		for _, category := range group.Categories {
+			ids = append(ids, category.CategoryID)
+		}
+
+		gotSummary := groupSummary{
+			name:       group.Name,
+			categories: ids,
+			assigned:   group.Assigned,
+			spent:      group.Spent,
+			available:  group.Available,
+		}
+		if fmt.Sprint(gotSummary) != fmt.Sprint(want[i]) {
+			t.Errorf("group %d = %+v, want %+v", i, gotSummary, want[i])
+		}
+	}
+
+	if !got.Groups[1].IsHidden {
+		t.Errorf("hidden group lost is_hidden flag")
+	}
+
+	if got.Groups[3].GroupID != nil {
+		t.Errorf("ungrouped categories have group_id %d", *got.Groups[3].GroupID)
+	}
+}
+
+func TestCalculateBudgetCreditCards(t *testing.T) {
+	visa, mastercard := uint64(100), uint64(200)
+	categories := []budgetCategory{
+		{ID: 1, Name: "Еда"},
+		{ID: 2, Name: "Visa", PaymentAccountID: &visa},
+		{ID: 3, Name: "Mastercard", PaymentAccountID: &mastercard},
+	}
+	// По Visa потрачено 200 из категории «Еда» и 150 погашено с бюджетного счёта,
+	// долг по Mastercard остался с открытия карты
+	months := []categoryMonth{
+		month(1, 2025, 1, 300, 200),
+		month(2, 2025, 1, 0, -50),
+	}
+	cards := []creditCard{
+		{AccountID: visa, Name: "Visa", CategoryID: 2, Balance: decimal.NewFromInt(-50)},
+		{AccountID: mastercard, Name: "Mastercard", CategoryID: 3, Balance: decimal.NewFromInt(-300)},
+	}
+
+	got := calculateBudget(nil, categories, months, cards, decimal.NewFromInt(850), decimal.Zero, 2025, 1, model.OverspendingModeToBeBudgeted)
+
+	payment := got.Groups[0].Categories[1]
+	if payment.Available != 50 || payment.PaymentAccountID == nil || *payment.PaymentAccountID != visa {
+		t.Errorf("payment category = %+v, want available 50 for account %d", payment, visa)
+	}
+
+	if !got.ToBeBudgeted.Equal(decimal.NewFromInt(700)) {
+		t.Errorf("to be budgeted = %s, want 700", got.ToBeBudgeted)
+	}
+
+	if len(got.CreditCardWarnings) != 1 {
+		t.Fatalf("got %d warnings, want 1", len(got.CreditCardWarnings))
+	}
+
+	warning := got.CreditCardWarnings[0]
+	if warning.AccountID != mastercard || !warning.Debt.Equal(decimal.NewFromInt(300)) || !warning.Shortfall.Equal(decimal.NewFromInt(300)) {
+		t.Errorf("warning = %+v, want mastercard debt 300 shortfall 300", warning)
+	}
+}
+
+func TestTargetNeeded(t *testing.T) {
+	date := time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		target   model.CategoryTarget
+		carried  int64
+		assigned int64
+		spent    int64
+		year     int
+		month    int
+		want     string
+	}{
+		{
+			name:     "ежемесячное пополнение недобрано",
+			target:   model.CategoryTarget{Type: model.CategoryTargetMonthlyFunding, Amount: decimal.NewFromInt(300)},
+			carried:  500,
+			assigned: 100,
+			year:     2025,
+			month:    1,
+			want:     "200",
+		},
+		{
+			name:     "целевой остаток учитывает перенос",
+			target:   model.CategoryTarget{Type: model.CategoryTargetBalance, Amount: decimal.NewFromInt(1000)},
+			carried:  700,
+			assigned: 100,
+			year:     2025,
+			month:    1,
+			want:     "200",
+		},
+		{
+			name:    "накопление к дате делится на оставшиеся месяцы через границу года",
+			target:  model.CategoryTarget{Type: model.CategoryTargetBalanceByDate, Amount: decimal.NewFromInt(1000), Date: &date},
+			carried: 100,
+			year:    2024,
+			month:   12,
+			want:    "225",
+		},
+		{
+			name:    "просроченная дата требует всю оставшуюся сумму",
+			target:  model.CategoryTarget{Type: model.CategoryTargetBalanceByDate, Amount: decimal.NewFromInt(1000), Date: &date},
+			carried: 400,
+			year:    2025,
+			month:   6,
+			want:    "600",
+		},
+		{
+			name:     "лимит трат покрывает траты не выше лимита",
+			target:   model.CategoryTarget{Type: model.CategoryTargetSpendingCap, Amount: decimal.NewFromInt(500)},
+			assigned: 100,
+			spent:    800,
+			year:     2025,
+			month:    1,
+			want:     "400",
+		},
+		{
+			name:     "перевыполненная цель не требует денег",
+			target:   model.CategoryTarget{Type: model.CategoryTargetMonthlyFunding, Amount: decimal.NewFromInt(300)},
+			assigned: 500,
+			year:     2025,
+			month:    1,
+			want:     "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := targetNeeded(tt.target, decimal.NewFromInt(tt.carried), decimal.NewFromInt(tt.assigned), decimal.NewFromInt(tt.spent), tt.year, tt.month)
+			if !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("needed = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
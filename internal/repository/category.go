@@ -4,10 +4,17 @@ import (
 	"context"
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
 	"litespend-api/internal/model"
 	"litespend-api/internal/repository/databases"
+	"time"
 )
 
+// nextCategorySortOrder — позиция в конце группы $3 пользователя $1.
+const nextCategorySortOrder = `(
+	SELECT COALESCE(MAX(c.sort_order) + 1, 0) FROM categories c
+	WHERE c.user_id = $1 AND c.group_id IS NOT DISTINCT FROM $3 AND c.deleted_at IS NULL)`
+
 type CategoryRepositoryPostgres struct {
 	db *sqlx.DB
 	sq sq.StatementBuilderType
@@ -24,7 +31,17 @@ func (r CategoryRepositoryPostgres) Create(ctx context.Context, category model.C
 	var createdID int
 
 	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
-		err := tx.GetContext(ctx, &createdID, `INSERT INTO categories(user_id, name, group_name, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`, category.UserID, category.Name, category.GroupName, category.CreatedAt, category.UpdatedAt)
+		var targetType *model.CategoryTargetType
+		var targetAmount *decimal.Decimal
+		var targetDate *time.Time
+		if category.Target != nil {
+			targetType, targetAmount, targetDate = &category.Target.Type, &category.Target.Amount, category.Target.Date
+		}
+
+		err := tx.GetContext(ctx, &createdID, `
+			INSERT INTO categories(user_id, name, group_id, sort_order, is_hidden, target_type, target_amount, target_date, created_at, updated_at)
+			VALUES ($1, $2, $3, `+nextCategorySortOrder+`, $4, $5, $6, $7, $8, $9) RETURNING id`,
+			category.UserID, category.Name, category.GroupID, category.IsHidden, targetType, targetAmount, targetDate, category.CreatedAt, category.UpdatedAt)
 		if err != nil {
 			return err
 		}
@@ -45,8 +62,24 @@ func (r CategoryRepositoryPostgres) Update(ctx context.Context, id int, dto mode
 		query = query.Set("name", *dto.Name)
 	}
 
-	if dto.GroupName != nil {
-		query = query.Set("group_name", *dto.GroupName)
+	// Перенесённая в другую группу категория встаёт в её конец
+	if dto.GroupID != nil || dto.RemoveGroup {
+		query = query.Set("group_id", dto.GroupID).
+			Set("sort_order", sq.Expr(`(
+				SELECT COALESCE(MAX(c.sort_order) + 1, 0) FROM categories c
+				WHERE c.user_id = categories.user_id AND c.group_id IS NOT DISTINCT FROM ? AND c.deleted_at IS NULL)`, dto.GroupID))
+	}
+
+	if dto.IsHidden != nil {
+		query = query.Set("is_hidden", *dto.IsHidden)
+	}
+
+	if dto.RemoveTarget {
+		query = query.Set("target_type", nil).Set("target_amount", nil).Set("target_date", nil)
+	} else if dto.Target != nil {
+		query = query.Set("target_type", dto.Target.Type).
+			Set("target_amount", dto.Target.Amount).
+			Set("target_date", dto.Target.Date)
 	}
 
 	query = query.Set("updated_at", dto.UpdatedAt)
@@ -64,8 +97,18 @@ func (r CategoryRepositoryPostgres) Update(ctx context.Context, id int, dto mode
 	return nil
 }
 
-func (r CategoryRepositoryPostgres) Delete(ctx context.Context, id int) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+// Delete переносит категорию в корзину, назначения и история остаются на месте.
+func (r CategoryRepositoryPostgres) Delete(ctx context.Context, id int, deletedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE categories SET deleted_at = $2 WHERE id = $1`, id, deletedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r CategoryRepositoryPostgres) Restore(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE categories SET deleted_at = NULL WHERE id = $1`, id)
 	if err != nil {
 		return err
 	}
@@ -76,7 +119,19 @@ func (r CategoryRepositoryPostgres) Delete(ctx context.Context, id int) error {
 func (r CategoryRepositoryPostgres) GetByID(ctx context.Context, id int) (model.Category, error) {
 	var category model.Category
 
-	err := r.db.GetContext(ctx, &category, `SELECT * FROM categories WHERE id = $1`, id)
+	err := r.db.GetContext(ctx, &category, `SELECT * FROM categories WHERE id = $1 AND deleted_at IS NULL`, id)
+	if err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+// GetDeletedByID возвращает категорию из корзины.
+func (r CategoryRepositoryPostgres) GetDeletedByID(ctx context.Context, id int) (model.Category, error) {
+	var category model.Category
+
+	err := r.db.GetContext(ctx, &category, `SELECT * FROM categories WHERE id = $1 AND deleted_at IS NOT NULL`, id)
 	if err != nil {
 		return category, err
 	}
@@ -87,10 +142,118 @@ func (r CategoryRepositoryPostgres) GetByID(ctx context.Context, id int) (model.
 func (r CategoryRepositoryPostgres) GetList(ctx context.Context, userID uint64) ([]model.Category, error) {
 	var categories []model.Category = make([]model.Category, 0)
 
-	err := r.db.SelectContext(ctx, &categories, `SELECT * FROM categories WHERE user_id = $1 ORDER BY name`, userID)
+	err := r.db.SelectContext(ctx, &categories, `
+		SELECT c.* FROM categories c
+		LEFT JOIN category_groups g ON g.id = c.group_id
+		WHERE c.user_id = $1 AND c.deleted_at IS NULL
+		ORDER BY g.sort_order NULLS LAST, g.id, c.sort_order, c.name`, userID)
 	if err != nil {
 		return categories, err
 	}
 
 	return categories, nil
 }
+
+// Reorder расставляет категории в группе в переданном порядке.
+func (r CategoryRepositoryPostgres) Reorder(ctx context.Context, record model.ReorderCategoriesRecord) error {
+	return databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		for i, id := range record.CategoryIDs {
+			_, err := tx.ExecContext(ctx, `
+				UPDATE categories SET group_id = $2, sort_order = $3, updated_at = $4 WHERE id = $1`,
+				id, record.GroupID, i, record.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// categoryUsageQuery считает активные транзакции, назначения, правила и получателей,
+// ссылающихся на категорию $1.
+const categoryUsageQuery = `
+	SELECT
+		(SELECT COUNT(*) FROM transactions t
+		 WHERE t.deleted_at IS NULL
+		   AND (t.category_id = $1 OR EXISTS(
+			SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id AND s.category_id = $1
+		   ))) AS transactions,
+		(SELECT COUNT(*) FROM budget_allocations ba WHERE ba.category_id = $1) AS allocations,
+		(SELECT COUNT(*) FROM transaction_rules tr WHERE tr.set_category_id = $1) AS rules,
+		(SELECT COUNT(*) FROM payees p WHERE p.last_category_id = $1) AS payees`
+
+func (r CategoryRepositoryPostgres) GetUsage(ctx context.Context, id int) (model.CategoryUsage, error) {
+	var usage model.CategoryUsage
+
+	err := r.db.GetContext(ctx, &usage, categoryUsageQuery, id)
+	if err != nil {
+		return usage, err
+	}
+
+	return usage, nil
+}
+
+// Reassign переносит всю историю категории в другую и отправляет её в корзину.
+// Назначения за один месяц складываются, так что доступный остаток сохраняется.
+// Возвращает, сколько записей было перенесено.
+func (r CategoryRepositoryPostgres) Reassign(ctx context.Context, record model.ReassignCategoryRecord) (model.CategoryUsage, error) {
+	var usage model.CategoryUsage
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &usage, categoryUsageQuery, record.FromID)
+		if err != nil {
+			return err
+		}
+
+		steps := []struct {
+			query string
+			args  []any
+		}{
+			// Родительские транзакции разбивок отмечаются изменёнными до переноса строк
+			{`UPDATE transactions SET updated_at = $2
+			  WHERE id IN (SELECT transaction_id FROM transaction_splits WHERE category_id = $1)`,
+				[]any{record.FromID, record.DeletedAt}},
+			{`UPDATE transaction_splits SET category_id = $2, updated_at = $3 WHERE category_id = $1`,
+				[]any{record.FromID, record.ToID, record.DeletedAt}},
+			{`UPDATE transactions SET category_id = $2, updated_at = $3 WHERE category_id = $1`,
+				[]any{record.FromID, record.ToID, record.DeletedAt}},
+			{`INSERT INTO budget_allocations (user_id, category_id, year, month, assigned, created_at, updated_at)
+			  SELECT user_id, $2, year, month, assigned, $3, $3 FROM budget_allocations WHERE category_id = $1
+			  ON CONFLICT (user_id, category_id, year, month)
+			  DO UPDATE SET assigned = budget_allocations.assigned + EXCLUDED.assigned, updated_at = EXCLUDED.updated_at`,
+				[]any{record.FromID, record.ToID, record.DeletedAt}},
+			{`DELETE FROM budget_allocations WHERE category_id = $1`,
+				[]any{record.FromID}},
+			{`UPDATE budget_moves SET from_category_id = $2 WHERE from_category_id = $1`,
+				[]any{record.FromID, record.ToID}},
+			{`UPDATE budget_moves SET to_category_id = $2 WHERE to_category_id = $1`,
+				[]any{record.FromID, record.ToID}},
+			// Перемещения между объединёнными категориями уже учтены в назначениях
+			{`DELETE FROM budget_moves WHERE from_category_id = $1 AND to_category_id = $1`,
+				[]any{record.ToID}},
+			{`UPDATE prescribed_expanses SET category_id = $2, updated_at = $3 WHERE category_id = $1`,
+				[]any{record.FromID, record.ToID, record.DeletedAt}},
+			{`UPDATE payees SET last_category_id = $2, updated_at = $3 WHERE last_category_id = $1`,
+				[]any{record.FromID, record.ToID, record.DeletedAt}},
+			{`UPDATE transaction_rules SET set_category_id = $2, updated_at = $3 WHERE set_category_id = $1`,
+				[]any{record.FromID, record.ToID, record.DeletedAt}},
+			{`UPDATE categories SET deleted_at = $2 WHERE id = $1`,
+				[]any{record.FromID, record.DeletedAt}},
+		}
+
+		for _, step := range steps {
+			_, err = tx.ExecContext(ctx, step.query, step.args...)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return model.CategoryUsage{}, err
+	}
+
+	return usage, nil
+}
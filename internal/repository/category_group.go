@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository/databases"
+)
+
+type CategoryGroupRepositoryPostgres struct {
+	db *sqlx.DB
+	sq sq.StatementBuilderType
+}
+
+func NewCategoryGroupRepositoryPostgres(db *sqlx.DB) CategoryGroupRepositoryPostgres {
+	return CategoryGroupRepositoryPostgres{
+		db: db,
+		sq: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create добавляет группу в конец списка групп пользователя.
+func (r CategoryGroupRepositoryPostgres) Create(ctx context.Context, record model.CreateCategoryGroupRecord) (uint64, error) {
+	var createdID uint64
+
+	err := r.db.GetContext(ctx, &createdID, `
+		INSERT INTO category_groups (user_id, name, sort_order, is_hidden, created_at, updated_at)
+		VALUES ($1, $2, (SELECT COALESCE(MAX(sort_order) + 1, 0) FROM category_groups WHERE user_id = $1), $3, $4, $5)
+		RETURNING id`,
+		record.UserID, record.Name, record.IsHidden, record.CreatedAt, record.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return createdID, nil
+}
+
+func (r CategoryGroupRepositoryPostgres) Update(ctx context.Context, id uint64, dto model.UpdateCategoryGroupRecord) error {
+	query := r.sq.Update("category_groups").Where(sq.Eq{"id": id})
+
+	if dto.Name != nil {
+		query = query.Set("name", *dto.Name)
+	}
+
+	if dto.IsHidden != nil {
+		query = query.Set("is_hidden", *dto.IsHidden)
+	}
+
+	query = query.Set("updated_at", dto.UpdatedAt)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete удаляет группу, её категории остаются без группы.
+func (r CategoryGroupRepositoryPostgres) Delete(ctx context.Context, id uint64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM category_groups WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r CategoryGroupRepositoryPostgres) GetByID(ctx context.Context, id uint64) (model.CategoryGroup, error) {
+	var group model.CategoryGroup
+
+	err := r.db.GetContext(ctx, &group, `SELECT * FROM category_groups WHERE id = $1`, id)
+	if err != nil {
+		return group, err
+	}
+
+	return group, nil
+}
+
+func (r CategoryGroupRepositoryPostgres) GetList(ctx context.Context, userID uint64) ([]model.CategoryGroup, error) {
+	var groups []model.CategoryGroup = make([]model.CategoryGroup, 0)
+
+	err := r.db.SelectContext(ctx, &groups, `
+		SELECT * FROM category_groups WHERE user_id = $1 ORDER BY sort_order, id`, userID)
+	if err != nil {
+		return groups, err
+	}
+
+	return groups, nil
+}
+
+// Reorder расставляет группы в переданном порядке.
+func (r CategoryGroupRepositoryPostgres) Reorder(ctx context.Context, record model.ReorderCategoryGroupsRecord) error {
+	return databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		for i, id := range record.GroupIDs {
+			_, err := tx.ExecContext(ctx, `
+				UPDATE category_groups SET sort_order = $2, updated_at = $3 WHERE id = $1`,
+				id, i, record.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"litespend-api/internal/model"
+)
+
+type ExchangeRateRepositoryPostgres struct {
+	db *sqlx.DB
+	sq sq.StatementBuilderType
+}
+
+func NewExchangeRateRepositoryPostgres(db *sqlx.DB) ExchangeRateRepositoryPostgres {
+	return ExchangeRateRepositoryPostgres{
+		db: db,
+		sq: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create сохраняет курс, курс той же пары на ту же дату заменяется.
+func (r ExchangeRateRepositoryPostgres) Create(ctx context.Context, record model.CreateExchangeRateRecord) (model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+
+	err := r.db.GetContext(ctx, &rate, `
+		INSERT INTO exchange_rates (user_id, from_currency, to_currency, rate, date, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, from_currency, to_currency, date)
+		DO UPDATE SET rate = EXCLUDED.rate, created_at = EXCLUDED.created_at
+		RETURNING *`,
+		record.UserID, record.FromCurrency, record.ToCurrency, record.Rate, record.Date, record.CreatedAt,
+	)
+	if err != nil {
+		return rate, err
+	}
+
+	return rate, nil
+}
+
+// GetList возвращает курсы пользователя, currency ограничивает выборку парами с этой валютой.
+func (r ExchangeRateRepositoryPostgres) GetList(ctx context.Context, userID uint64, currency *model.Currency) ([]model.ExchangeRate, error) {
+	var rates []model.ExchangeRate = make([]model.ExchangeRate, 0)
+
+	query := r.sq.Select("*").
+		From("exchange_rates").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("from_currency", "to_currency", "date DESC")
+	if currency != nil {
+		query = query.Where(sq.Or{sq.Eq{"from_currency": *currency}, sq.Eq{"to_currency": *currency}})
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return rates, err
+	}
+
+	err = r.db.SelectContext(ctx, &rates, sqlQuery, args...)
+	if err != nil {
+		return rates, err
+	}
+
+	return rates, nil
+}
+
+// GetMissingCurrencies возвращает валюты счетов, для которых нет ни одного курса к
+// базовой валюте пользователя. Суммы в этих валютах в отчёты и бюджет не попадают.
+// Удалённые счета учитываются, пока на них остаются транзакции.
+func (r ExchangeRateRepositoryPostgres) GetMissingCurrencies(ctx context.Context, userID uint64) ([]model.Currency, error) {
+	var currencies []model.Currency = make([]model.Currency, 0)
+
+	err := r.db.SelectContext(ctx, &currencies, `
+		SELECT DISTINCT a.currency
+		FROM accounts a
+		JOIN users u ON u.id = a.user_id
+		WHERE a.user_id = $1
+			AND a.currency <> u.base_currency
+			AND (a.deleted_at IS NULL OR EXISTS (
+				SELECT 1 FROM transactions t
+				WHERE t.account_id = a.id AND t.deleted_at IS NULL
+			))
+			AND NOT EXISTS (
+				SELECT 1 FROM exchange_rates er
+				WHERE er.user_id = a.user_id
+					AND ((er.from_currency = a.currency AND er.to_currency = u.base_currency)
+						OR (er.from_currency = u.base_currency AND er.to_currency = a.currency))
+			)
+		ORDER BY a.currency`, userID)
+	if err != nil {
+		return currencies, err
+	}
+
+	return currencies, nil
+}
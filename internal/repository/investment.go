@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository/databases"
+)
+
+// holdingValuationsQuery — открытые позиции с последним снимком цены не позже сегодня.
+const holdingValuationsQuery = `
+	SELECT h.account_id, h.ticker, h.quantity, h.cost_basis, p.price, p.date AS price_date
+	FROM investment_holdings h
+	JOIN accounts a ON a.id = h.account_id
+	LEFT JOIN LATERAL (
+		SELECT sp.price, sp.date FROM security_prices sp
+		WHERE sp.user_id = a.user_id AND sp.ticker = h.ticker AND sp.date <= CURRENT_DATE
+		ORDER BY sp.date DESC
+		LIMIT 1
+	) p ON TRUE
+	WHERE h.quantity > 0`
+
+type InvestmentRepositoryPostgres struct {
+	db *sqlx.DB
+	sq sq.StatementBuilderType
+}
+
+func NewInvestmentRepositoryPostgres(db *sqlx.DB) InvestmentRepositoryPostgres {
+	return InvestmentRepositoryPostgres{
+		db: db,
+		sq: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// RecordActivity проводит движение денег по счёту и обновляет позицию. Счёт
+// блокируется, чтобы одновременные сделки считали себестоимость от одной позиции.
+func (r InvestmentRepositoryPostgres) RecordActivity(ctx context.Context, record model.CreateInvestmentActivityRecord) (model.InvestmentActivityResult, error) {
+	var result model.InvestmentActivityResult
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `SELECT id FROM accounts WHERE id = $1 FOR UPDATE`, record.AccountID)
+		if err != nil {
+			return err
+		}
+
+		holding := model.InvestmentHolding{AccountID: record.AccountID, Ticker: record.Ticker}
+		err = tx.GetContext(ctx, &holding, `
+			SELECT account_id, ticker, quantity, cost_basis FROM investment_holdings
+			WHERE account_id = $1 AND ticker = $2`, record.AccountID, record.Ticker)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		updated, amount, realizedGain, ok := holding.Apply(record)
+		result.Holding = updated
+		if !ok {
+			return nil
+		}
+
+		origin := model.TransactionOriginInvestmentTrade
+		if record.Kind == model.InvestmentActivityDividend {
+			origin = model.TransactionOriginDividend
+		}
+
+		var transactionID uint64
+		err = tx.GetContext(ctx, &transactionID, `
+			INSERT INTO transactions (user_id, account_id, amount, date, note, cleared, approved, origin, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, true, true, $6, $7, $7)
+			RETURNING id`,
+			record.UserID, record.AccountID, amount, record.Date, record.Note, origin, record.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if record.Kind != model.InvestmentActivityDividend {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO investment_holdings (account_id, ticker, quantity, cost_basis, updated_at)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (account_id, ticker)
+				DO UPDATE SET quantity = EXCLUDED.quantity, cost_basis = EXCLUDED.cost_basis, updated_at = EXCLUDED.updated_at`,
+				record.AccountID, record.Ticker, updated.Quantity, updated.CostBasis, record.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		var activity model.InvestmentActivity
+		err = tx.GetContext(ctx, &activity, `
+			INSERT INTO investment_activities (user_id, account_id, transaction_id, ticker, kind, quantity, price, fee, amount, realized_gain, date, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING *`,
+			record.UserID, record.AccountID, transactionID, record.Ticker, record.Kind, record.Quantity, record.Price, record.Fee, amount, realizedGain, record.Date, record.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		result.Activity = &activity
+
+		return nil
+	})
+	if err != nil {
+		return model.InvestmentActivityResult{}, err
+	}
+
+	return result, nil
+}
+
+func (r InvestmentRepositoryPostgres) GetActivities(ctx context.Context, accountID uint64) ([]model.InvestmentActivity, error) {
+	var activities []model.InvestmentActivity = make([]model.InvestmentActivity, 0)
+
+	err := r.db.SelectContext(ctx, &activities, `
+		SELECT * FROM investment_activities WHERE account_id = $1 ORDER BY date DESC, id DESC`, accountID)
+	if err != nil {
+		return activities, err
+	}
+
+	return activities, nil
+}
+
+func (r InvestmentRepositoryPostgres) GetHoldings(ctx context.Context, accountID uint64) ([]model.HoldingValuation, error) {
+	var holdings []model.HoldingValuation = make([]model.HoldingValuation, 0)
+
+	err := r.db.SelectContext(ctx, &holdings, `
+		SELECT * FROM (`+holdingValuationsQuery+`) hv
+		WHERE hv.account_id = $1
+		ORDER BY hv.ticker`, accountID)
+	if err != nil {
+		return holdings, err
+	}
+
+	return holdings, nil
+}
+
+func (r InvestmentRepositoryPostgres) GetIncome(ctx context.Context, accountID uint64) (model.InvestmentIncome, error) {
+	var income model.InvestmentIncome
+
+	err := r.db.GetContext(ctx, &income, `
+		SELECT
+			COALESCE(SUM(realized_gain), 0) AS realized_gain,
+			COALESCE(SUM(amount) FILTER (WHERE kind = 'dividend'), 0) AS dividends
+		FROM investment_activities
+		WHERE account_id = $1`, accountID)
+	if err != nil {
+		return income, err
+	}
+
+	return income, nil
+}
+
+// CreatePrice сохраняет снимок цены, снимок на ту же дату заменяется.
+func (r InvestmentRepositoryPostgres) CreatePrice(ctx context.Context, record model.CreateSecurityPriceRecord) (model.SecurityPrice, error) {
+	var price model.SecurityPrice
+
+	err := r.db.GetContext(ctx, &price, `
+		INSERT INTO security_prices (user_id, ticker, price, date, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, ticker, date)
+		DO UPDATE SET price = EXCLUDED.price, created_at = EXCLUDED.created_at
+		RETURNING *`,
+		record.UserID, record.Ticker, record.Price, record.Date, record.CreatedAt,
+	)
+	if err != nil {
+		return price, err
+	}
+
+	return price, nil
+}
+
+func (r InvestmentRepositoryPostgres) GetPrices(ctx context.Context, userID uint64, ticker *string) ([]model.SecurityPrice, error) {
+	var prices []model.SecurityPrice = make([]model.SecurityPrice, 0)
+
+	query := r.sq.Select("*").
+		From("security_prices").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("ticker", "date DESC")
+	if ticker != nil {
+		query = query.Where(sq.Eq{"ticker": *ticker})
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return prices, err
+	}
+
+	err = r.db.SelectContext(ctx, &prices, sqlQuery, args...)
+	if err != nil {
+		return prices, err
+	}
+
+	return prices, nil
+}
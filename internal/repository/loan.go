@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository/databases"
+	"time"
+)
+
+// loanDebtQuery — остаток долга по счёту кредита на дату $2.
+const loanDebtQuery = `
+	SELECT COALESCE(-SUM(amount), 0) FROM transactions
+	WHERE account_id = $1 AND deleted_at IS NULL AND date <= $2`
+
+type LoanRepositoryPostgres struct {
+	db *sqlx.DB
+	sq sq.StatementBuilderType
+}
+
+func NewLoanRepositoryPostgres(db *sqlx.DB) LoanRepositoryPostgres {
+	return LoanRepositoryPostgres{
+		db: db,
+		sq: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r LoanRepositoryPostgres) GetByAccountID(ctx context.Context, accountID uint64) (model.Loan, error) {
+	var loan model.Loan
+
+	err := r.db.GetContext(ctx, &loan, `SELECT * FROM loans WHERE account_id = $1`, accountID)
+	if err != nil {
+		return loan, err
+	}
+
+	return loan, nil
+}
+
+func (r LoanRepositoryPostgres) Update(ctx context.Context, accountID uint64, dto model.UpdateLoanRecord) error {
+	query := r.sq.Update("loans").Where(sq.Eq{"account_id": accountID})
+
+	if dto.InterestRate != nil {
+		query = query.Set("interest_rate", *dto.InterestRate)
+	}
+
+	if dto.TermMonths != nil {
+		query = query.Set("term_months", *dto.TermMonths)
+	}
+
+	if dto.PaymentDay != nil {
+		query = query.Set("payment_day", *dto.PaymentDay)
+	}
+
+	query = query.Set("updated_at", dto.UpdatedAt)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetDebt возвращает остаток долга по счёту кредита на дату.
+func (r LoanRepositoryPostgres) GetDebt(ctx context.Context, accountID uint64, date time.Time) (decimal.Decimal, error) {
+	var debt decimal.Decimal
+
+	err := r.db.GetContext(ctx, &debt, loanDebtQuery, accountID, date)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return debt, nil
+}
+
+// RecordPayment проводит платёж переводом на счёт кредита и начисляет проценты
+// на остаток долга отдельной транзакцией. Счёт кредита блокируется, чтобы
+// одновременные платежи считали проценты от согласованного остатка.
+func (r LoanRepositoryPostgres) RecordPayment(ctx context.Context, record model.LoanPaymentRecord) (model.LoanPayment, error) {
+	payment := model.LoanPayment{Date: record.Date, Amount: record.Amount}
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, `SELECT id FROM accounts WHERE id = $1 FOR UPDATE`, record.Loan.AccountID)
+		if err != nil {
+			return err
+		}
+
+		var debt decimal.Decimal
+		err = tx.GetContext(ctx, &debt, loanDebtQuery, record.Loan.AccountID, record.Date)
+		if err != nil {
+			return err
+		}
+		payment.Principal, payment.Interest = record.Loan.SplitPayment(debt, record.Amount)
+
+		for _, leg := range []struct {
+			accountID  uint64
+			categoryID *uint64
+			amount     decimal.Decimal
+			id         *uint64
+		}{
+			{record.FromAccountID, record.CategoryID, record.Amount.Neg(), &payment.FromTransactionID},
+			{record.Loan.AccountID, nil, record.Amount, &payment.TransactionID},
+		} {
+			err = tx.GetContext(ctx, leg.id, `
+				INSERT INTO transactions (user_id, account_id, category_id, amount, date, note, approved, cleared, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, true, $7, $8, $8)
+				RETURNING id`,
+				record.UserID, leg.accountID, leg.categoryID, leg.amount, record.Date, record.Note, record.IsCleared, record.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE transactions SET transfer_transaction_id = CASE id WHEN $1 THEN $2 ELSE $1 END
+			WHERE id IN ($1, $2)`, payment.FromTransactionID, payment.TransactionID)
+		if err != nil {
+			return err
+		}
+
+		if payment.Interest.IsPositive() {
+			var interestID uint64
+			err = tx.GetContext(ctx, &interestID, `
+				INSERT INTO transactions (user_id, account_id, amount, date, note, cleared, approved, origin, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, true, true, $6, $7, $7)
+				RETURNING id`,
+				record.UserID, record.Loan.AccountID, payment.Interest.Neg(), record.Date, record.InterestNote, model.TransactionOriginLoanInterest, record.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+			payment.InterestTransactionID = &interestID
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO loan_payments (transaction_id, account_id, interest_transaction_id, principal, interest)
+			VALUES ($1, $2, $3, $4, $5)`,
+			payment.TransactionID, record.Loan.AccountID, payment.InterestTransactionID, payment.Principal, payment.Interest,
+		)
+		return err
+	})
+	if err != nil {
+		return model.LoanPayment{}, err
+	}
+
+	return payment, nil
+}
+
+// GetPayments возвращает платежи по кредиту, начиная с последнего.
+func (r LoanRepositoryPostgres) GetPayments(ctx context.Context, accountID uint64) ([]model.LoanPayment, error) {
+	var payments []model.LoanPayment = make([]model.LoanPayment, 0)
+
+	err := r.db.SelectContext(ctx, &payments, `
+		SELECT lp.transaction_id,
+			t.transfer_transaction_id AS from_transaction_id,
+			lp.interest_transaction_id,
+			t.date,
+			t.amount,
+			lp.principal,
+			lp.interest
+		FROM loan_payments lp
+		JOIN transactions t ON t.id = lp.transaction_id AND t.deleted_at IS NULL
+		WHERE lp.account_id = $1
+		ORDER BY t.date DESC, t.id DESC`, accountID)
+	if err != nil {
+		return payments, err
+	}
+
+	return payments, nil
+}
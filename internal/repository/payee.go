@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository/databases"
+	"strings"
+	"time"
+)
+
+type PayeeRepositoryPostgres struct {
+	db *sqlx.DB
+	sq sq.StatementBuilderType
+}
+
+func NewPayeeRepositoryPostgres(db *sqlx.DB) PayeeRepositoryPostgres {
+	return PayeeRepositoryPostgres{
+		db: db,
+		sq: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create заводит получателя, а если получатель с таким именем уже есть, возвращает его.
+func (r PayeeRepositoryPostgres) Create(ctx context.Context, record model.CreatePayeeRecord) (model.Payee, error) {
+	var payee model.Payee
+
+	err := r.db.GetContext(ctx, &payee, `
+		INSERT INTO payees (user_id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (user_id, lower(name)) DO UPDATE SET name = payees.name
+		RETURNING *`,
+		record.UserID, record.Name, record.CreatedAt,
+	)
+	if err != nil {
+		return payee, err
+	}
+
+	return payee, nil
+}
+
+// Resolve находит получателя по тексту: сначала по самому длинному подходящему
+// правилу переименования, затем по имени. Не найденный получатель создаётся.
+func (r PayeeRepositoryPostgres) Resolve(ctx context.Context, userID uint64, text string, createdAt time.Time) (model.Payee, error) {
+	var payee model.Payee
+
+	err := r.db.GetContext(ctx, &payee, `
+		SELECT p.* FROM payee_rename_rules pr
+		JOIN payees p ON p.id = pr.payee_id
+		WHERE pr.user_id = $1 AND strpos(lower($2), lower(pr.pattern)) > 0
+		ORDER BY length(pr.pattern) DESC, pr.id
+		LIMIT 1`, userID, text)
+	if err == nil {
+		return payee, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return payee, err
+	}
+
+	return r.Create(ctx, model.CreatePayeeRecord{
+		UserID:    userID,
+		Name:      text,
+		CreatedAt: createdAt,
+	})
+}
+
+func (r PayeeRepositoryPostgres) GetByID(ctx context.Context, id uint64) (model.Payee, error) {
+	var payee model.Payee
+
+	err := r.db.GetContext(ctx, &payee, `SELECT * FROM payees WHERE id = $1`, id)
+	if err != nil {
+		return payee, err
+	}
+
+	return payee, nil
+}
+
+func (r PayeeRepositoryPostgres) GetByName(ctx context.Context, userID uint64, name string) (model.Payee, error) {
+	var payee model.Payee
+
+	err := r.db.GetContext(ctx, &payee, `SELECT * FROM payees WHERE user_id = $1 AND lower(name) = lower($2)`, userID, name)
+	if err != nil {
+		return payee, err
+	}
+
+	return payee, nil
+}
+
+// GetDetails возвращает получателя с числом транзакций и правилами переименования.
+func (r PayeeRepositoryPostgres) GetDetails(ctx context.Context, id uint64) (model.PayeeDetails, error) {
+	var details model.PayeeDetails
+
+	err := r.db.GetContext(ctx, &details, `
+		SELECT p.*, (SELECT COUNT(*) FROM transactions t WHERE t.payee_id = p.id AND t.deleted_at IS NULL) AS transactions
+		FROM payees p
+		WHERE p.id = $1`, id)
+	if err != nil {
+		return details, err
+	}
+
+	details.RenameRules = make([]model.PayeeRenameRule, 0)
+	err = r.db.SelectContext(ctx, &details.RenameRules, `
+		SELECT * FROM payee_rename_rules WHERE payee_id = $1 ORDER BY pattern`, id)
+	if err != nil {
+		return details, err
+	}
+
+	return details, nil
+}
+
+// GetList возвращает получателей пользователя, search ищет по части имени.
+func (r PayeeRepositoryPostgres) GetList(ctx context.Context, userID uint64, search *string) ([]model.Payee, error) {
+	var payees []model.Payee = make([]model.Payee, 0)
+
+	query := r.sq.Select("*").
+		From("payees").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("lower(name)")
+	if search != nil && strings.TrimSpace(*search) != "" {
+		query = query.Where(sq.ILike{"name": "%" + escapeLike(strings.TrimSpace(*search)) + "%"})
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return payees, err
+	}
+
+	err = r.db.SelectContext(ctx, &payees, sqlQuery, args...)
+	if err != nil {
+		return payees, err
+	}
+
+	return payees, nil
+}
+
+// Autocomplete подбирает получателей по части имени: сначала те, чьё имя
+// начинается с введённого, затем самые используемые. Категория из корзины не
+// предлагается.
+func (r PayeeRepositoryPostgres) Autocomplete(ctx context.Context, userID uint64, term string, limit int) ([]model.PayeeSuggestion, error) {
+	var suggestions []model.PayeeSuggestion = make([]model.PayeeSuggestion, 0)
+
+	escaped := escapeLike(term)
+	err := r.db.SelectContext(ctx, &suggestions, `
+		SELECT p.id, p.name, c.id AS last_category_id
+		FROM payees p
+		LEFT JOIN categories c ON c.id = p.last_category_id AND c.deleted_at IS NULL
+		WHERE p.user_id = $1 AND p.name ILIKE $2
+		ORDER BY p.name ILIKE $3 DESC,
+			(SELECT COUNT(*) FROM transactions t WHERE t.payee_id = p.id AND t.deleted_at IS NULL) DESC,
+			lower(p.name)
+		LIMIT $4`, userID, "%"+escaped+"%", escaped+"%", limit)
+	if err != nil {
+		return suggestions, err
+	}
+
+	return suggestions, nil
+}
+
+func (r PayeeRepositoryPostgres) Update(ctx context.Context, id uint64, record model.UpdatePayeeRecord) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE payees SET name = $2, updated_at = $3 WHERE id = $1`, id, record.Name, record.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete удаляет получателя, у его транзакций получатель очищается.
+func (r PayeeRepositoryPostgres) Delete(ctx context.Context, id uint64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM payees WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Merge переносит транзакции и правила получателя FromID к ToID, имя исходного
+// получателя становится правилом переименования целевого, исходный удаляется.
+func (r PayeeRepositoryPostgres) Merge(ctx context.Context, record model.MergePayeeRecord) (model.MergePayeeResult, error) {
+	var result model.MergePayeeResult
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE transactions SET payee_id = $2, updated_at = $3 WHERE payee_id = $1`,
+			record.FromID, record.ToID, record.CreatedAt)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		result.Transactions = int(affected)
+
+		_, err = tx.ExecContext(ctx, `UPDATE payee_rename_rules SET payee_id = $2 WHERE payee_id = $1`, record.FromID, record.ToID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO payee_rename_rules (user_id, payee_id, pattern, created_at)
+			SELECT user_id, $2, name, $3 FROM payees WHERE id = $1
+			ON CONFLICT (user_id, lower(pattern)) DO UPDATE SET payee_id = EXCLUDED.payee_id`,
+			record.FromID, record.ToID, record.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		err = tx.GetContext(ctx, &result.RenameRules, `SELECT COUNT(*) FROM payee_rename_rules WHERE payee_id = $1`, record.ToID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE payees SET
+				last_category_id = COALESCE(last_category_id, (SELECT last_category_id FROM payees WHERE id = $1)),
+				updated_at = $3
+			WHERE id = $2`,
+			record.FromID, record.ToID, record.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM payees WHERE id = $1`, record.FromID)
+		return err
+	})
+	if err != nil {
+		return model.MergePayeeResult{}, err
+	}
+
+	return result, nil
+}
+
+// CreateRenameRule добавляет правило; правило с тем же текстом переходит к новому получателю.
+func (r PayeeRepositoryPostgres) CreateRenameRule(ctx context.Context, record model.CreatePayeeRenameRuleRecord) (model.PayeeRenameRule, error) {
+	var rule model.PayeeRenameRule
+
+	err := r.db.GetContext(ctx, &rule, `
+		INSERT INTO payee_rename_rules (user_id, payee_id, pattern, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, lower(pattern)) DO UPDATE SET payee_id = EXCLUDED.payee_id
+		RETURNING *`,
+		record.UserID, record.PayeeID, record.Pattern, record.CreatedAt,
+	)
+	if err != nil {
+		return rule, err
+	}
+
+	return rule, nil
+}
+
+// DeleteRenameRule удаляет правило получателя и сообщает, было ли оно.
+func (r PayeeRepositoryPostgres) DeleteRenameRule(ctx context.Context, payeeID uint64, ruleID uint64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM payee_rename_rules WHERE id = $1 AND payee_id = $2`, ruleID, payeeID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
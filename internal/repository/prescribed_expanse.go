@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository/databases"
+	"time"
+)
+
+type PrescribedExpanseRepositoryPostgres struct {
+	db *sqlx.DB
+	sq sq.StatementBuilderType
+}
+
+func NewPrescribedExpanseRepositoryPostgres(db *sqlx.DB) PrescribedExpanseRepositoryPostgres {
+	return PrescribedExpanseRepositoryPostgres{
+		db: db,
+		sq: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r PrescribedExpanseRepositoryPostgres) Create(ctx context.Context, record model.CreatePrescribedExpanseRecord) (int, error) {
+	var createdID int
+
+	err := r.db.GetContext(ctx, &createdID, `
+		INSERT INTO prescribed_expanses (user_id, account_id, category_id, description, frequency, repeat_every, amount, date, end_date, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`,
+		record.UserID,
+		record.AccountID,
+		record.CategoryID,
+		record.Description,
+		record.Frequency,
+		record.Interval,
+		record.Amount,
+		record.Date,
+		record.EndDate,
+		record.CreatedAt,
+		record.UpdatedAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return createdID, nil
+}
+
+func (r PrescribedExpanseRepositoryPostgres) Update(ctx context.Context, id int, dto model.UpdatePrescribedExpanseRecord) error {
+	query := r.sq.Update("prescribed_expanses").Where(sq.Eq{"id": id})
+
+	if dto.AccountID != nil {
+		query = query.Set("account_id", *dto.AccountID)
+	}
+
+	if dto.CategoryID != nil {
+		query = query.Set("category_id", *dto.CategoryID)
+	}
+
+	if dto.Description != nil {
+		query = query.Set("description", *dto.Description)
+	}
+
+	if dto.Frequency != nil {
+		query = query.Set("frequency", *dto.Frequency)
+	}
+
+	if dto.Interval != nil {
+		query = query.Set("repeat_every", *dto.Interval)
+	}
+
+	if dto.Amount != nil {
+		query = query.Set("amount", *dto.Amount)
+	}
+
+	if dto.Date != nil {
+		query = query.Set("date", *dto.Date)
+	}
+
+	if dto.EndDate != nil {
+		query = query.Set("end_date", *dto.EndDate)
+	}
+
+	query = query.Set("updated_at", dto.UpdatedAt)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r PrescribedExpanseRepositoryPostgres) Delete(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM prescribed_expanses WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r PrescribedExpanseRepositoryPostgres) GetByID(ctx context.Context, id int) (model.PrescribedExpanse, error) {
+	var prescribedExpanse model.PrescribedExpanse
+
+	err := r.db.GetContext(ctx, &prescribedExpanse, `SELECT * FROM prescribed_expanses WHERE id = $1`, id)
+	if err != nil {
+		return prescribedExpanse, err
+	}
+
+	return prescribedExpanse, nil
+}
+
+func (r PrescribedExpanseRepositoryPostgres) GetList(ctx context.Context, userID uint64) ([]model.PrescribedExpanse, error) {
+	var prescribedExpanses []model.PrescribedExpanse = make([]model.PrescribedExpanse, 0)
+
+	err := r.db.SelectContext(ctx, &prescribedExpanses, `SELECT * FROM prescribed_expanses WHERE user_id = $1 ORDER BY date, id`, userID)
+	if err != nil {
+		return prescribedExpanses, err
+	}
+
+	return prescribedExpanses, nil
+}
+
+// GetPayments возвращает суммы оплат повторений, приходящихся на промежуток [from, to].
+func (r PrescribedExpanseRepositoryPostgres) GetPayments(ctx context.Context, userID uint64, from time.Time, to time.Time) ([]model.PrescribedExpansePayment, error) {
+	var payments []model.PrescribedExpansePayment = make([]model.PrescribedExpansePayment, 0)
+
+	err := r.db.SelectContext(ctx, &payments, `
+		SELECT prescribed_expanse_id, scheduled_date, SUM(-amount) AS amount, MAX(id) AS transaction_id
+		FROM transactions
+		WHERE user_id = $1 AND prescribed_expanse_id IS NOT NULL AND deleted_at IS NULL
+		  AND scheduled_date BETWEEN $2 AND $3
+		GROUP BY prescribed_expanse_id, scheduled_date`, userID, from, to)
+	if err != nil {
+		return payments, err
+	}
+
+	return payments, nil
+}
+
+// postDueLockKey — ключ advisory-блокировки, под которой проводятся повторения расписаний.
+const postDueLockKey = 7_300_001
+
+// postingExpanse — расписание вместе со счётом, на который проводятся его повторения.
+type postingExpanse struct {
+	model.PrescribedExpanse
+	PostingAccountID *uint64 `db:"posting_account_id"`
+}
+
+// PostDue проводит наступившие к today повторения всех расписаний неподтверждёнными
+// транзакциями и возвращает их количество. Advisory-блокировка не даёт нескольким
+// репликам работать одновременно, а отметка posted_through — провести повторение дважды.
+func (r PrescribedExpanseRepositoryPostgres) PostDue(ctx context.Context, today time.Time) (int, error) {
+	var posted int
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		var locked bool
+		err := tx.GetContext(ctx, &locked, `SELECT pg_try_advisory_xact_lock($1)`, postDueLockKey)
+		if err != nil {
+			return err
+		}
+
+		// Задачу уже выполняет другая реплика
+		if !locked {
+			return nil
+		}
+
+		// Без явного, с удалённым или внебюджетным счётом повторения проводятся по первому
+		// неархивному бюджетному счёту пользователя: у транзакции повторения есть категория
+		var expanses []postingExpanse
+		err = tx.SelectContext(ctx, &expanses, `
+			SELECT pe.*, COALESCE((
+				SELECT a.id FROM accounts a
+				WHERE a.id = pe.account_id AND a.on_budget AND a.deleted_at IS NULL
+			), (
+				SELECT a.id FROM accounts a
+				WHERE a.user_id = pe.user_id AND a.on_budget AND NOT a.is_archived AND a.deleted_at IS NULL
+				ORDER BY a.order_num, a.name
+				LIMIT 1
+			)) AS posting_account_id
+			FROM prescribed_expanses pe
+			WHERE pe.date <= $1
+			  AND (pe.posted_through IS NULL OR pe.posted_through < $1)`, today)
+		if err != nil {
+			return err
+		}
+
+		for _, expanse := range expanses {
+			if expanse.PostingAccountID == nil {
+				continue
+			}
+
+			// Прошлые повторения до создания расписания не проводятся
+			from := expanse.CreatedAt
+			if expanse.PostedThrough != nil {
+				from = expanse.PostedThrough.AddDate(0, 0, 1)
+			}
+
+			for _, date := range expanse.Occurrences(from, today) {
+				var paid decimal.Decimal
+				err = tx.GetContext(ctx, &paid, `
+					SELECT COALESCE(SUM(-amount), 0) FROM transactions
+					WHERE prescribed_expanse_id = $1 AND scheduled_date = $2 AND deleted_at IS NULL`,
+					expanse.ID, date)
+				if err != nil {
+					return err
+				}
+
+				// После частичной оплаты вручную проводится только остаток
+				remaining := expanse.Remaining(paid)
+				if remaining.IsZero() {
+					continue
+				}
+
+				_, err = tx.ExecContext(ctx, `
+					INSERT INTO transactions(user_id, account_id, category_id, amount, date, note, approved, cleared, created_at, updated_at, prescribed_expanse_id, scheduled_date)
+					VALUES ($1, $2, $3, $4, $5, $6, false, false, now(), now(), $7, $5)`,
+					expanse.UserID,
+					*expanse.PostingAccountID,
+					expanse.CategoryID,
+					remaining.Neg(),
+					date,
+					expanse.Description,
+					expanse.ID,
+				)
+				if err != nil {
+					return err
+				}
+
+				posted++
+			}
+
+			_, err = tx.ExecContext(ctx, `UPDATE prescribed_expanses SET posted_through = $1 WHERE id = $2`, today, expanse.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return posted, nil
+}
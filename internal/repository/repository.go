@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
 	"litespend-api/internal/model"
+	"time"
 )
 
 type UserRepository interface {
@@ -17,43 +19,160 @@ type UserRepository interface {
 type TransactionRepository interface {
 	Create(ctx context.Context, transaction model.CreateTransactionRecord) (int, error)
 	Update(ctx context.Context, id int, dto model.UpdateTransactionRecord) error
-	Delete(ctx context.Context, id int) error
+	CreateTransfer(ctx context.Context, from model.CreateTransactionRecord, to model.CreateTransactionRecord) (int, int, error)
+	UpdateTransfer(ctx context.Context, id int, dto model.UpdateTransactionRecord, pairID int, pairDto model.UpdateTransactionRecord) error
+	ConvertToTransfer(ctx context.Context, id int, dto model.UpdateTransactionRecord, pair model.CreateTransactionRecord) (int, error)
+	Delete(ctx context.Context, id int, deletedAt time.Time) error
+	Restore(ctx context.Context, id int) error
 	GetByID(ctx context.Context, id int) (model.Transaction, error)
+	GetDeletedByID(ctx context.Context, id int) (model.Transaction, error)
 	GetList(ctx context.Context, userID uint64) ([]model.Transaction, error)
-	GetListPaginated(ctx context.Context, userID uint64, accountID *uint64, params model.PaginationParams) ([]model.Transaction, int, error)
+	GetListPaginated(ctx context.Context, userID uint64, filter model.TransactionFilter, params model.PaginationParams) ([]model.Transaction, int, model.TransactionTotals, error)
+	GetListByCursor(ctx context.Context, userID uint64, filter model.TransactionFilter, params model.PaginationParams, cursor *model.Cursor) ([]model.Transaction, bool, error)
 }
 
 type CategoryRepository interface {
 	Create(ctx context.Context, category model.CreateCategoryRecord) (int, error)
 	Update(ctx context.Context, id int, dto model.UpdateCategoryRecord) error
-	Delete(ctx context.Context, id int) error
+	Delete(ctx context.Context, id int, deletedAt time.Time) error
+	Restore(ctx context.Context, id int) error
 	GetByID(ctx context.Context, id int) (model.Category, error)
+	GetDeletedByID(ctx context.Context, id int) (model.Category, error)
 	GetList(ctx context.Context, userID uint64) ([]model.Category, error)
+	GetUsage(ctx context.Context, id int) (model.CategoryUsage, error)
+	Reassign(ctx context.Context, record model.ReassignCategoryRecord) (model.CategoryUsage, error)
+	Reorder(ctx context.Context, record model.ReorderCategoriesRecord) error
+}
+
+type CategoryGroupRepository interface {
+	Create(ctx context.Context, record model.CreateCategoryGroupRecord) (uint64, error)
+	Update(ctx context.Context, id uint64, dto model.UpdateCategoryGroupRecord) error
+	Delete(ctx context.Context, id uint64) error
+	GetByID(ctx context.Context, id uint64) (model.CategoryGroup, error)
+	GetList(ctx context.Context, userID uint64) ([]model.CategoryGroup, error)
+	Reorder(ctx context.Context, record model.ReorderCategoryGroupsRecord) error
 }
 
 type BudgetRepository interface {
 	Create(ctx context.Context, record model.CreateBudgetAllocationRecord) (int, error)
+	Assign(ctx context.Context, record model.AssignBudgetRecord) (model.BudgetAllocation, error)
+	QuickAssign(ctx context.Context, record model.QuickAssignRecord) (int, error)
+	MoveMoney(ctx context.Context, record model.CreateBudgetMoveRecord) (model.BudgetMove, error)
+	GetMoves(ctx context.Context, userID uint64, year uint, month uint) ([]model.BudgetMove, error)
 	Update(ctx context.Context, id int, dto model.UpdateBudgetAllocationRequest) error
 	Delete(ctx context.Context, id int) error
 	GetByID(ctx context.Context, id int) (model.BudgetAllocation, error)
 	GetList(ctx context.Context, userID uint64) ([]model.BudgetAllocation, error)
-	GetListDetailedByPeriod(ctx context.Context, userID uint64, year uint64, month uint64) (model.CategoryBudgetResponse, error)
+	GetListDetailedByPeriod(ctx context.Context, userID uint64, year uint64, month uint64, mode model.OverspendingMode) (model.CategoryBudgetResponse, error)
 }
 
 type AccountRepository interface {
 	Create(ctx context.Context, account model.CreateAccountRecord) (uint64, error)
 	Update(ctx context.Context, id uint64, dto model.UpdateAccountRecord) error
-	Delete(ctx context.Context, id uint64) error
+	Delete(ctx context.Context, record model.DeleteAccountRecord) error
+	Restore(ctx context.Context, id uint64) error
+	AdjustBalance(ctx context.Context, record model.AdjustAccountBalanceRecord) (model.AccountBalanceAdjustment, error)
+	GetClearedBalance(ctx context.Context, id uint64, date time.Time) (model.ClearedBalance, error)
+	Reconcile(ctx context.Context, record model.ReconcileAccountRecord) (model.ReconciliationResult, error)
+	CountTransactions(ctx context.Context, id uint64) (int, error)
+	CountTransfersWith(ctx context.Context, id uint64, otherID uint64) (int, error)
 	GetByID(ctx context.Context, id uint64) (model.Account, error)
+	GetDeletedByID(ctx context.Context, id uint64) (model.Account, error)
 	GetList(ctx context.Context, userID uint64) ([]model.Account, error)
 }
 
+type ExchangeRateRepository interface {
+	Create(ctx context.Context, record model.CreateExchangeRateRecord) (model.ExchangeRate, error)
+	GetList(ctx context.Context, userID uint64, currency *model.Currency) ([]model.ExchangeRate, error)
+	GetMissingCurrencies(ctx context.Context, userID uint64) ([]model.Currency, error)
+}
+
+type InvestmentRepository interface {
+	RecordActivity(ctx context.Context, record model.CreateInvestmentActivityRecord) (model.InvestmentActivityResult, error)
+	GetActivities(ctx context.Context, accountID uint64) ([]model.InvestmentActivity, error)
+	GetHoldings(ctx context.Context, accountID uint64) ([]model.HoldingValuation, error)
+	GetIncome(ctx context.Context, accountID uint64) (model.InvestmentIncome, error)
+	CreatePrice(ctx context.Context, record model.CreateSecurityPriceRecord) (model.SecurityPrice, error)
+	GetPrices(ctx context.Context, userID uint64, ticker *string) ([]model.SecurityPrice, error)
+}
+
+type LoanRepository interface {
+	GetByAccountID(ctx context.Context, accountID uint64) (model.Loan, error)
+	Update(ctx context.Context, accountID uint64, dto model.UpdateLoanRecord) error
+	GetDebt(ctx context.Context, accountID uint64, date time.Time) (decimal.Decimal, error)
+	RecordPayment(ctx context.Context, record model.LoanPaymentRecord) (model.LoanPayment, error)
+	GetPayments(ctx context.Context, accountID uint64) ([]model.LoanPayment, error)
+}
+
+type PayeeRepository interface {
+	Create(ctx context.Context, record model.CreatePayeeRecord) (model.Payee, error)
+	Resolve(ctx context.Context, userID uint64, text string, createdAt time.Time) (model.Payee, error)
+	GetByID(ctx context.Context, id uint64) (model.Payee, error)
+	GetByName(ctx context.Context, userID uint64, name string) (model.Payee, error)
+	GetDetails(ctx context.Context, id uint64) (model.PayeeDetails, error)
+	GetList(ctx context.Context, userID uint64, search *string) ([]model.Payee, error)
+	Autocomplete(ctx context.Context, userID uint64, term string, limit int) ([]model.PayeeSuggestion, error)
+	Update(ctx context.Context, id uint64, record model.UpdatePayeeRecord) error
+	Delete(ctx context.Context, id uint64) error
+	Merge(ctx context.Context, record model.MergePayeeRecord) (model.MergePayeeResult, error)
+	CreateRenameRule(ctx context.Context, record model.CreatePayeeRenameRuleRecord) (model.PayeeRenameRule, error)
+	DeleteRenameRule(ctx context.Context, payeeID uint64, ruleID uint64) (bool, error)
+}
+
+type RuleRepository interface {
+	Create(ctx context.Context, record model.TransactionRuleRecord) (model.TransactionRule, error)
+	Update(ctx context.Context, id uint64, record model.TransactionRuleRecord) error
+	Delete(ctx context.Context, id uint64) error
+	GetByID(ctx context.Context, id uint64) (model.TransactionRule, error)
+	GetList(ctx context.Context, userID uint64) ([]model.TransactionRule, error)
+	GetCandidates(ctx context.Context, userID uint64, req model.ApplyRulesRequest) ([]model.RuleCandidate, error)
+}
+
+type PrescribedExpanseRepository interface {
+	Create(ctx context.Context, record model.CreatePrescribedExpanseRecord) (int, error)
+	Update(ctx context.Context, id int, dto model.UpdatePrescribedExpanseRecord) error
+	Delete(ctx context.Context, id int) error
+	GetByID(ctx context.Context, id int) (model.PrescribedExpanse, error)
+	GetList(ctx context.Context, userID uint64) ([]model.PrescribedExpanse, error)
+	GetPayments(ctx context.Context, userID uint64, from time.Time, to time.Time) ([]model.PrescribedExpansePayment, error)
+	PostDue(ctx context.Context, today time.Time) (int, error)
+}
+
+type StatisticsRepository interface {
+	GetTotals(ctx context.Context, userID uint64, from time.Time, to time.Time) (model.CurrentBalanceStatistics, error)
+	GetPeriodStatistics(ctx context.Context, userID uint64, period model.PeriodType, filter model.StatisticsFilter) ([]model.PeriodStatisticsItem, error)
+	GetCategoryStatistics(ctx context.Context, userID uint64, period model.PeriodType, filter model.StatisticsFilter) ([]model.CategoryStatisticsItem, error)
+	GetPayeeStatistics(ctx context.Context, userID uint64, filter model.StatisticsFilter) ([]model.PayeeStatisticsItem, error)
+	GetNetWorth(ctx context.Context, userID uint64) ([]model.NetWorthAccount, error)
+}
+
+type TrashRepository interface {
+	GetList(ctx context.Context, userID uint64) (model.Trash, error)
+	Purge(ctx context.Context, before time.Time) (model.PurgeResult, error)
+}
+
+type SyncRepository interface {
+	GetChanges(ctx context.Context, userID uint64, since int64) (model.SyncChanges, int64, error)
+	ApplyChanges(ctx context.Context, userID uint64, changes []model.SyncChangeRecord) ([]model.SyncChangeResult, error)
+}
+
 type Repository struct {
 	UserRepository        UserRepository
 	TransactionRepository TransactionRepository
 	CategoryRepository    CategoryRepository
 	BudgetRepository      BudgetRepository
 	AccountRepository     AccountRepository
+
+	CategoryGroupRepository     CategoryGroupRepository
+	ExchangeRateRepository      ExchangeRateRepository
+	InvestmentRepository        InvestmentRepository
+	LoanRepository              LoanRepository
+	PayeeRepository             PayeeRepository
+	PrescribedExpanseRepository PrescribedExpanseRepository
+	RuleRepository              RuleRepository
+	StatisticsRepository        StatisticsRepository
+	SyncRepository              SyncRepository
+	TrashRepository             TrashRepository
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
@@ -63,5 +182,16 @@ func NewRepository(db *sqlx.DB) *Repository {
 		CategoryRepository:    NewCategoryRepositoryPostgres(db),
 		BudgetRepository:      NewBudgetRepositoryPostgres(db),
 		AccountRepository:     NewAccountRepositoryPostgres(db),
+
+		CategoryGroupRepository:     NewCategoryGroupRepositoryPostgres(db),
+		ExchangeRateRepository:      NewExchangeRateRepositoryPostgres(db),
+		InvestmentRepository:        NewInvestmentRepositoryPostgres(db),
+		LoanRepository:              NewLoanRepositoryPostgres(db),
+		PayeeRepository:             NewPayeeRepositoryPostgres(db),
+		PrescribedExpanseRepository: NewPrescribedExpanseRepositoryPostgres(db),
+		RuleRepository:              NewRuleRepositoryPostgres(db),
+		StatisticsRepository:        NewStatisticsRepositoryPostgres(db),
+		SyncRepository:              NewSyncRepositoryPostgres(db),
+		TrashRepository:             NewTrashRepositoryPostgres(db),
 	}
 }
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"litespend-api/internal/model"
+)
+
+// ruleColumns — поля правила вместе с отметкой, что категория действия в корзине.
+// Нужен LEFT JOIN categories c по set_category_id.
+const ruleColumns = `tr.*, c.deleted_at IS NOT NULL AS set_category_deleted`
+
+type RuleRepositoryPostgres struct {
+	db *sqlx.DB
+	sq sq.StatementBuilderType
+}
+
+func NewRuleRepositoryPostgres(db *sqlx.DB) RuleRepositoryPostgres {
+	return RuleRepositoryPostgres{
+		db: db,
+		sq: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (r RuleRepositoryPostgres) Create(ctx context.Context, record model.TransactionRuleRecord) (model.TransactionRule, error) {
+	var rule model.TransactionRule
+
+	err := r.db.GetContext(ctx, &rule, `
+		INSERT INTO transaction_rules (user_id, name, sort_order, is_active, match_type, note_pattern, payee_pattern,
+			min_amount, max_amount, account_id, weekdays, set_category_id, set_payee_id, set_note, set_approved,
+			transfer_account_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING *`,
+		record.UserID, record.Name, record.SortOrder, record.IsActive, record.MatchType, record.NotePattern, record.PayeePattern,
+		record.MinAmount, record.MaxAmount, record.AccountID, int16(record.Weekdays), record.SetCategoryID, record.SetPayeeID,
+		record.SetNote, record.SetApproved, record.TransferAccountID, record.CreatedAt, record.UpdatedAt,
+	)
+	if err != nil {
+		return rule, err
+	}
+
+	return rule, nil
+}
+
+// Update заменяет условия и действия правила целиком.
+func (r RuleRepositoryPostgres) Update(ctx context.Context, id uint64, record model.TransactionRuleRecord) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE transaction_rules SET name = $2, sort_order = $3, is_active = $4, match_type = $5, note_pattern = $6,
+			payee_pattern = $7, min_amount = $8, max_amount = $9, account_id = $10, weekdays = $11, set_category_id = $12,
+			set_payee_id = $13, set_note = $14, set_approved = $15, transfer_account_id = $16, updated_at = $17
+		WHERE id = $1`,
+		id, record.Name, record.SortOrder, record.IsActive, record.MatchType, record.NotePattern, record.PayeePattern,
+		record.MinAmount, record.MaxAmount, record.AccountID, int16(record.Weekdays), record.SetCategoryID, record.SetPayeeID,
+		record.SetNote, record.SetApproved, record.TransferAccountID, record.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r RuleRepositoryPostgres) Delete(ctx context.Context, id uint64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM transaction_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r RuleRepositoryPostgres) GetByID(ctx context.Context, id uint64) (model.TransactionRule, error) {
+	var rule model.TransactionRule
+
+	err := r.db.GetContext(ctx, &rule, `
+		SELECT `+ruleColumns+` FROM transaction_rules tr
+		LEFT JOIN categories c ON c.id = tr.set_category_id
+		WHERE tr.id = $1`, id)
+	if err != nil {
+		return rule, err
+	}
+
+	return rule, nil
+}
+
+// GetList возвращает правила пользователя в порядке проверки.
+func (r RuleRepositoryPostgres) GetList(ctx context.Context, userID uint64) ([]model.TransactionRule, error) {
+	var rules []model.TransactionRule = make([]model.TransactionRule, 0)
+
+	err := r.db.SelectContext(ctx, &rules, `
+		SELECT `+ruleColumns+` FROM transaction_rules tr
+		LEFT JOIN categories c ON c.id = tr.set_category_id
+		WHERE tr.user_id = $1
+		ORDER BY tr.sort_order, tr.id`, userID)
+	if err != nil {
+		return rules, err
+	}
+
+	return rules, nil
+}
+
+// GetCandidates возвращает транзакции, к которым можно применить правила: без
+// переводов, разбитых транзакций и операций инвестиционных счетов.
+func (r RuleRepositoryPostgres) GetCandidates(ctx context.Context, userID uint64, req model.ApplyRulesRequest) ([]model.RuleCandidate, error) {
+	var candidates []model.RuleCandidate = make([]model.RuleCandidate, 0)
+
+	query := r.sq.Select("t.id", "t.account_id", "t.amount", "t.date", "t.note", "t.category_id", "t.payee_id",
+		"p.name AS payee_name", "t.approved").
+		From("transactions t").
+		LeftJoin("payees p ON p.id = t.payee_id").
+		Where(sq.Eq{"t.user_id": userID, "t.deleted_at": nil, "t.transfer_transaction_id": nil}).
+		Where(sq.NotEq{"t.origin": []model.TransactionOrigin{model.TransactionOriginInvestmentTrade, model.TransactionOriginDividend}}).
+		Where("NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)").
+		OrderBy("t.date", "t.id")
+
+	if req.From != nil {
+		query = query.Where(sq.GtOrEq{"t.date": *req.From})
+	}
+	if req.To != nil {
+		query = query.Where(sq.LtOrEq{"t.date": *req.To})
+	}
+	if req.AccountID != nil {
+		query = query.Where(sq.Eq{"t.account_id": *req.AccountID})
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return candidates, err
+	}
+
+	err = r.db.SelectContext(ctx, &candidates, sqlQuery, args...)
+	if err != nil {
+		return candidates, err
+	}
+
+	return candidates, nil
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"litespend-api/internal/model"
+	"time"
+)
+
+// periodBuckets — выражения группировки по дате для каждого типа периода.
+// Неделя обозначается датой её понедельника.
+var periodBuckets = map[model.PeriodType]string{
+	model.PeriodTypeDay:   `to_char(src.date, 'YYYY-MM-DD')`,
+	model.PeriodTypeWeek:  `to_char(date_trunc('week', src.date), 'YYYY-MM-DD')`,
+	model.PeriodTypeMonth: `to_char(src.date, 'YYYY-MM')`,
+}
+
+const (
+	incomeColumn  = `COALESCE(SUM(src.amount) FILTER (WHERE src.amount > 0), 0) AS income`
+	expenseColumn = `COALESCE(SUM(src.amount) FILTER (WHERE src.amount < 0), 0) AS expense`
+)
+
+// transactionAmountsQuery — движения по счетам без переводов между ними. Перевод с
+// категорией (на внебюджетный счёт или с него) — трата или доход, он учитывается.
+// Начальный остаток счёта не доход, поэтому в отчёты тоже не попадает. Проценты по
+// кредиту уже входят в платёж, вторично они не учитываются. Покупка и продажа бумаг
+// меняют форму вложений, а не доход или расход. Суммы пересчитаны в базовую валюту
+// по курсу на дату транзакции, суммы в валюте без курса пропускаются.
+const transactionAmountsQuery = `
+	SELECT t.user_id, t.account_id, t.payee_id, ROUND(t.amount * fx.rate, 2) AS amount, t.date
+	FROM transactions t
+	JOIN accounts a ON a.id = t.account_id
+	JOIN LATERAL (
+		SELECT base_currency_rate(t.user_id, a.currency, t.date) AS rate
+	) fx ON fx.rate IS NOT NULL
+	WHERE (t.transfer_transaction_id IS NULL OR t.category_id IS NOT NULL)
+		AND t.origin NOT IN ('starting_balance', 'loan_interest', 'investment_trade')
+		AND t.deleted_at IS NULL`
+
+// groupedCategoryAmountsQuery — суммы по категориям вместе с группой категории.
+const groupedCategoryAmountsQuery = `
+	SELECT ca.user_id, ca.account_id, ca.category_id, ca.amount, ca.date, c.name AS category_name, g.name AS group_name
+	FROM (` + categoryAmountsQuery + `) ca
+	JOIN categories c ON c.id = ca.category_id AND c.deleted_at IS NULL
+	LEFT JOIN category_groups g ON g.id = c.group_id`
+
+type StatisticsRepositoryPostgres struct {
+	db *sqlx.DB
+	sq sq.StatementBuilderType
+}
+
+func NewStatisticsRepositoryPostgres(db *sqlx.DB) StatisticsRepositoryPostgres {
+	return StatisticsRepositoryPostgres{
+		db: db,
+		sq: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// GetTotals возвращает доходы и расходы (со знаком минус) за промежуток [from, to].
+func (r StatisticsRepositoryPostgres) GetTotals(ctx context.Context, userID uint64, from time.Time, to time.Time) (model.CurrentBalanceStatistics, error) {
+	var totals model.CurrentBalanceStatistics
+
+	sqlQuery, args, err := r.sq.Select(incomeColumn, expenseColumn).
+		From("(" + transactionAmountsQuery + ") src").
+		Where(sq.Eq{"src.user_id": userID}).
+		Where(sq.GtOrEq{"src.date": from}).
+		Where(sq.LtOrEq{"src.date": to}).
+		ToSql()
+	if err != nil {
+		return totals, err
+	}
+
+	row := r.db.QueryRowxContext(ctx, sqlQuery, args...)
+	err = row.Scan(&totals.TotalIncome, &totals.TotalExpense)
+	if err != nil {
+		return totals, err
+	}
+
+	return totals, nil
+}
+
+func (r StatisticsRepositoryPostgres) GetPeriodStatistics(ctx context.Context, userID uint64, period model.PeriodType, filter model.StatisticsFilter) ([]model.PeriodStatisticsItem, error) {
+	var items []model.PeriodStatisticsItem = make([]model.PeriodStatisticsItem, 0)
+
+	bucket, ok := periodBuckets[period]
+	if !ok {
+		return items, fmt.Errorf("unknown period %q", period)
+	}
+
+	// Фильтр по группе возможен только для транзакций с категорией
+	source := transactionAmountsQuery
+	if filter.CategoryGroup != nil {
+		source = groupedCategoryAmountsQuery
+	}
+
+	query := r.sq.Select(
+		bucket+" AS period",
+		incomeColumn,
+		expenseColumn,
+		`COALESCE(SUM(src.amount), 0) AS balance`,
+	).
+		From("(" + source + ") src").
+		Where(sq.Eq{"src.user_id": userID}).
+		GroupBy("period").
+		OrderBy("period")
+	query = applyStatisticsFilter(query, filter)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return items, err
+	}
+
+	err = r.db.SelectContext(ctx, &items, sqlQuery, args...)
+	if err != nil {
+		return items, err
+	}
+
+	return items, nil
+}
+
+func (r StatisticsRepositoryPostgres) GetCategoryStatistics(ctx context.Context, userID uint64, period model.PeriodType, filter model.StatisticsFilter) ([]model.CategoryStatisticsItem, error) {
+	var items []model.CategoryStatisticsItem = make([]model.CategoryStatisticsItem, 0)
+
+	bucket, ok := periodBuckets[period]
+	if !ok {
+		return items, fmt.Errorf("unknown period %q", period)
+	}
+
+	query := r.sq.Select(
+		"src.category_id",
+		"src.category_name",
+		bucket+" AS period",
+		incomeColumn,
+		expenseColumn,
+	).
+		From("("+groupedCategoryAmountsQuery+") src").
+		Where(sq.Eq{"src.user_id": userID}).
+		GroupBy("src.category_id", "src.category_name", "period").
+		OrderBy("period", "src.category_name")
+	query = applyStatisticsFilter(query, filter)
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return items, err
+	}
+
+	err = r.db.SelectContext(ctx, &items, sqlQuery, args...)
+	if err != nil {
+		return items, err
+	}
+
+	return items, nil
+}
+
+// GetPayeeStatistics возвращает доходы и расходы по получателям, больше всего
+// потраченное — первым.
+func (r StatisticsRepositoryPostgres) GetPayeeStatistics(ctx context.Context, userID uint64, filter model.StatisticsFilter) ([]model.PayeeStatisticsItem, error) {
+	var items []model.PayeeStatisticsItem = make([]model.PayeeStatisticsItem, 0)
+
+	query := r.sq.Select("src.payee_id", "p.name AS payee_name", "COUNT(*) AS transactions", incomeColumn, expenseColumn).
+		From("(" + transactionAmountsQuery + ") src").
+		Join("payees p ON p.id = src.payee_id").
+		Where(sq.Eq{"src.user_id": userID})
+	query = applyStatisticsFilter(query, filter).
+		GroupBy("src.payee_id", "p.name").
+		OrderBy("expense", "p.name")
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return items, err
+	}
+
+	err = r.db.SelectContext(ctx, &items, sqlQuery, args...)
+	if err != nil {
+		return items, err
+	}
+
+	return items, nil
+}
+
+// GetNetWorth возвращает балансы всех счетов пользователя, у инвестиционных счетов
+// вместе с оценкой бумаг. Стоимость счёта в базовой валюте считается по курсу на
+// сегодня: это оценка того, что есть сейчас, а не сумма прошлых движений.
+func (r StatisticsRepositoryPostgres) GetNetWorth(ctx context.Context, userID uint64) ([]model.NetWorthAccount, error) {
+	var accounts []model.NetWorthAccount = make([]model.NetWorthAccount, 0)
+
+	err := r.db.SelectContext(ctx, &accounts, `
+		SELECT nw.account_id, nw.name, nw.type, nw.on_budget, nw.currency, nw.balance, nw.holdings_value,
+			to_base_currency(nw.user_id, nw.currency, nw.balance + nw.holdings_value, CURRENT_DATE) AS value
+		FROM (
+			SELECT a.id AS account_id, a.user_id, a.name, a.type, a.on_budget, a.currency, a.order_num,
+				COALESCE((SELECT SUM(tr.amount) FROM transactions tr
+					WHERE tr.account_id = a.id AND tr.deleted_at IS NULL), 0) AS balance,
+				COALESCE((SELECT SUM(COALESCE(ROUND(hv.quantity * hv.price, 2), hv.cost_basis))
+					FROM (`+holdingValuationsQuery+`) hv WHERE hv.account_id = a.id), 0) AS holdings_value
+			FROM accounts a
+			WHERE a.user_id = $1 AND a.deleted_at IS NULL
+		) nw
+		ORDER BY nw.order_num, nw.name`, userID)
+	if err != nil {
+		return accounts, err
+	}
+
+	return accounts, nil
+}
+
+func applyStatisticsFilter(query sq.SelectBuilder, filter model.StatisticsFilter) sq.SelectBuilder {
+	if filter.From != nil {
+		query = query.Where(sq.GtOrEq{"src.date": *filter.From})
+	}
+
+	if filter.To != nil {
+		query = query.Where(sq.LtOrEq{"src.date": *filter.To})
+	}
+
+	if filter.AccountID != nil {
+		query = query.Where(sq.Eq{"src.account_id": *filter.AccountID})
+	}
+
+	if filter.CategoryGroup != nil {
+		query = query.Where(sq.Eq{"src.group_name": *filter.CategoryGroup})
+	}
+
+	return query
+}
@@ -0,0 +1,669 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/shopspring/decimal"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository/databases"
+	"time"
+)
+
+// syncRow — общие для синхронизируемых таблиц поля, нужные для поиска записи.
+type syncRow struct {
+	ID      uint64 `db:"id"`
+	Version int64  `db:"version"`
+	Deleted bool   `db:"deleted"`
+}
+
+// softDeletedEntities удаляются в корзину, остальные — окончательно.
+var softDeletedEntities = map[model.SyncEntity]bool{
+	model.SyncEntityAccount:     true,
+	model.SyncEntityCategory:    true,
+	model.SyncEntityTransaction: true,
+}
+
+// syncTrashQuery — записи из корзины, клиент получает их как удалённые.
+const syncTrashQuery = `
+	SELECT 'accounts' AS entity, id AS entity_id, client_id, version, deleted_at
+	FROM accounts WHERE user_id = $1 AND version > $2 AND deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'categories', id, client_id, version, deleted_at
+	FROM categories WHERE user_id = $1 AND version > $2 AND deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'transactions', id, client_id, version, deleted_at
+	FROM transactions WHERE user_id = $1 AND version > $2 AND deleted_at IS NOT NULL`
+
+type SyncRepositoryPostgres struct {
+	db           *sqlx.DB
+	sq           sq.StatementBuilderType
+	transactions TransactionRepositoryPostgres
+}
+
+func NewSyncRepositoryPostgres(db *sqlx.DB) SyncRepositoryPostgres {
+	return SyncRepositoryPostgres{
+		db:           db,
+		sq:           sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		transactions: NewTransactionRepositoryPostgres(db),
+	}
+}
+
+// GetChanges возвращает записи с версией больше since и текущую версию пользователя.
+// Всё читается из одного снимка, поэтому изменения после него попадут в следующий
+// запрос. При since < 0 отдаётся полная выгрузка без следов удалений.
+func (r SyncRepositoryPostgres) GetChanges(ctx context.Context, userID uint64, since int64) (model.SyncChanges, int64, error) {
+	changes := model.SyncChanges{
+		Accounts:          make([]model.Account, 0),
+		Categories:        make([]model.Category, 0),
+		CategoryGroups:    make([]model.CategoryGroup, 0),
+		Transactions:      make([]model.Transaction, 0),
+		BudgetAllocations: make([]model.BudgetAllocation, 0),
+		Deleted:           make([]model.SyncTombstone, 0),
+	}
+	var version int64
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return changes, 0, err
+	}
+	defer tx.Rollback()
+
+	err = tx.GetContext(ctx, &version, `SELECT COALESCE((SELECT version FROM sync_sequences WHERE user_id = $1), 0)`, userID)
+	if err != nil {
+		return changes, 0, err
+	}
+
+	err = tx.SelectContext(ctx, &changes.Accounts, `
+		SELECT a.*, COALESCE(SUM(tr.amount), 0) as balance FROM accounts a
+		         LEFT JOIN transactions tr ON a.id = tr.account_id AND tr.deleted_at IS NULL
+		WHERE a.user_id = $1 AND a.version > $2 AND a.deleted_at IS NULL GROUP BY a.id
+		ORDER BY a.version`, userID, since)
+	if err != nil {
+		return changes, 0, err
+	}
+
+	err = tx.SelectContext(ctx, &changes.Categories, `
+		SELECT * FROM categories WHERE user_id = $1 AND version > $2 AND deleted_at IS NULL ORDER BY version`, userID, since)
+	if err != nil {
+		return changes, 0, err
+	}
+
+	err = tx.SelectContext(ctx, &changes.CategoryGroups, `
+		SELECT * FROM category_groups WHERE user_id = $1 AND version > $2 ORDER BY version`, userID, since)
+	if err != nil {
+		return changes, 0, err
+	}
+
+	err = tx.SelectContext(ctx, &changes.Transactions, `
+		SELECT * FROM transactions WHERE user_id = $1 AND version > $2 AND deleted_at IS NULL ORDER BY version`, userID, since)
+	if err != nil {
+		return changes, 0, err
+	}
+
+	err = r.transactions.attachSplits(ctx, tx, changes.Transactions)
+	if err != nil {
+		return changes, 0, err
+	}
+
+	err = tx.SelectContext(ctx, &changes.BudgetAllocations, `
+		SELECT * FROM budget_allocations WHERE user_id = $1 AND version > $2 ORDER BY version`, userID, since)
+	if err != nil {
+		return changes, 0, err
+	}
+
+	if since >= 0 {
+		err = tx.SelectContext(ctx, &changes.Deleted, `
+			SELECT * FROM (
+				SELECT entity, entity_id, client_id, version, deleted_at FROM sync_tombstones
+				WHERE user_id = $1 AND version > $2
+				UNION ALL `+syncTrashQuery+`
+			) d
+			ORDER BY d.version`, userID, since)
+		if err != nil {
+			return changes, 0, err
+		}
+	}
+
+	return changes, version, nil
+}
+
+// ApplyChanges применяет пачку изменений клиента в одной транзакции. Каждое
+// изменение выполняется в своей точке сохранения: ошибка одного не отменяет остальные.
+func (r SyncRepositoryPostgres) ApplyChanges(ctx context.Context, userID uint64, changes []model.SyncChangeRecord) ([]model.SyncChangeResult, error) {
+	results := make([]model.SyncChangeResult, 0, len(changes))
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		for _, change := range changes {
+			_, err := tx.ExecContext(ctx, `SAVEPOINT sync_change`)
+			if err != nil {
+				return err
+			}
+
+			result, err := r.applyChange(ctx, tx, userID, change)
+			if err != nil {
+				_, rollbackErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT sync_change`)
+				if rollbackErr != nil {
+					return rollbackErr
+				}
+
+				result = rejectSyncChange(change, err.Error())
+			}
+
+			_, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT sync_change`)
+			if err != nil {
+				return err
+			}
+
+			results = append(results, result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (r SyncRepositoryPostgres) applyChange(ctx context.Context, tx *sqlx.Tx, userID uint64, change model.SyncChangeRecord) (model.SyncChangeResult, error) {
+	row, err := r.findRow(ctx, tx, userID, change)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+
+	if row == nil || row.Deleted {
+		if change.Operation == model.SyncOperationDelete {
+			// Удаление уже применено раньше — повтор из очереди клиента не ошибка
+			result := model.SyncChangeResult{Entity: change.Entity, ClientID: change.ClientID, Status: model.SyncChangeApplied}
+			if change.ID != nil {
+				result.ID = *change.ID
+			}
+			return result, nil
+		}
+
+		if row != nil {
+			return rejectSyncChange(change, "record is in trash"), nil
+		}
+
+		if change.ID != nil {
+			return rejectSyncChange(change, "record not found"), nil
+		}
+	}
+
+	if row != nil && change.BaseVersion != nil && row.Version > *change.BaseVersion {
+		return model.SyncChangeResult{
+			Entity:   change.Entity,
+			ID:       row.ID,
+			ClientID: change.ClientID,
+			Status:   model.SyncChangeConflict,
+			Version:  row.Version,
+		}, nil
+	}
+
+	if change.Operation == model.SyncOperationDelete {
+		return r.deleteRow(ctx, tx, userID, change, row.ID)
+	}
+
+	switch change.Entity {
+	case model.SyncEntityAccount:
+		return r.upsertAccount(ctx, tx, userID, change, row)
+	case model.SyncEntityCategory:
+		return r.upsertCategory(ctx, tx, userID, change, row)
+	case model.SyncEntityCategoryGroup:
+		return r.upsertCategoryGroup(ctx, tx, userID, change, row)
+	case model.SyncEntityTransaction:
+		return r.upsertTransaction(ctx, tx, userID, change, row)
+	case model.SyncEntityBudgetAllocation:
+		return r.upsertBudgetAllocation(ctx, tx, userID, change, row)
+	default:
+		return rejectSyncChange(change, "unknown entity"), nil
+	}
+}
+
+// findRow ищет запись пользователя по ID или client_id и блокирует её до конца транзакции.
+func (r SyncRepositoryPostgres) findRow(ctx context.Context, tx *sqlx.Tx, userID uint64, change model.SyncChangeRecord) (*syncRow, error) {
+	deleted := "false AS deleted"
+	if softDeletedEntities[change.Entity] {
+		deleted = "deleted_at IS NOT NULL AS deleted"
+	}
+
+	query := r.sq.Select("id", "version", deleted).From(string(change.Entity)).
+		Where(sq.Eq{"user_id": userID}).
+		Suffix("FOR UPDATE")
+
+	switch {
+	case change.ID != nil:
+		query = query.Where(sq.Eq{"id": *change.ID})
+	case change.ClientID != nil:
+		query = query.Where(sq.Eq{"client_id": *change.ClientID})
+	default:
+		return nil, nil
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var row syncRow
+	err = tx.GetContext(ctx, &row, sqlQuery, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &row, nil
+}
+
+func (r SyncRepositoryPostgres) deleteRow(ctx context.Context, tx *sqlx.Tx, userID uint64, change model.SyncChangeRecord, id uint64) (model.SyncChangeResult, error) {
+	sqlQuery, args := `DELETE FROM budget_allocations WHERE id = $1`, []any{id}
+	switch change.Entity {
+	case model.SyncEntityAccount:
+		// Выбрать судьбу транзакций счёта можно только через API счетов
+		var hasTransactions bool
+		err := tx.GetContext(ctx, &hasTransactions, `
+			SELECT EXISTS(SELECT 1 FROM transactions WHERE account_id = $1 AND deleted_at IS NULL)`, id)
+		if err != nil {
+			return model.SyncChangeResult{}, err
+		}
+		if hasTransactions {
+			return rejectSyncChange(change, "account has transactions"), nil
+		}
+
+		sqlQuery, args = `UPDATE accounts SET deleted_at = $2 WHERE id = $1`, []any{id, change.UpdatedAt}
+	case model.SyncEntityCategory:
+		// Перенести историю категории можно только через API категорий
+		var usage model.CategoryUsage
+		err := tx.GetContext(ctx, &usage, categoryUsageQuery, id)
+		if err != nil {
+			return model.SyncChangeResult{}, err
+		}
+		if usage.IsUsed() {
+			return rejectSyncChange(change, "category is in use"), nil
+		}
+
+		var payment bool
+		err = tx.GetContext(ctx, &payment, `SELECT payment_account_id IS NOT NULL FROM categories WHERE id = $1`, id)
+		if err != nil {
+			return model.SyncChangeResult{}, err
+		}
+		if payment {
+			return rejectSyncChange(change, "credit card payment category is deleted with its account"), nil
+		}
+
+		sqlQuery, args = `UPDATE categories SET deleted_at = $2 WHERE id = $1`, []any{id, change.UpdatedAt}
+	case model.SyncEntityCategoryGroup:
+		// Категории группы остаются без группы, как и при удалении через API
+		sqlQuery = `DELETE FROM category_groups WHERE id = $1`
+	case model.SyncEntityTransaction:
+		// Сделка с бумагами меняет позицию, отменить её офлайн нельзя
+		var investment bool
+		err := tx.GetContext(ctx, &investment, `
+			SELECT origin IN ('investment_trade', 'dividend') FROM transactions WHERE id = $1`, id)
+		if err != nil {
+			return model.SyncChangeResult{}, err
+		}
+		if investment {
+			return rejectSyncChange(change, "investment activities cannot be changed through sync"), nil
+		}
+
+		// Перевод удаляется вместе со второй половиной и процентами платежа по
+		// кредиту, как и в TransactionRepository.Delete
+		sqlQuery, args = `
+			UPDATE transactions SET deleted_at = $2
+			WHERE (id = $1 OR transfer_transaction_id = $1 OR id IN (
+					SELECT lp.interest_transaction_id FROM loan_payments lp
+					JOIN transactions p ON p.id = lp.transaction_id
+					WHERE p.id = $1 OR p.transfer_transaction_id = $1
+				))
+				AND deleted_at IS NULL`, []any{id, change.UpdatedAt}
+	}
+
+	_, err := tx.ExecContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+
+	var version int64
+	err = tx.GetContext(ctx, &version, `SELECT version FROM sync_sequences WHERE user_id = $1`, userID)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+
+	return model.SyncChangeResult{
+		Entity:   change.Entity,
+		ID:       id,
+		ClientID: change.ClientID,
+		Status:   model.SyncChangeApplied,
+		Version:  version,
+	}, nil
+}
+
+func (r SyncRepositoryPostgres) upsertAccount(ctx context.Context, tx *sqlx.Tx, userID uint64, change model.SyncChangeRecord, row *syncRow) (model.SyncChangeResult, error) {
+	data := change.Account
+	var saved syncRow
+
+	if row == nil {
+		if data.Type == model.AccountTypeLoan {
+			return rejectSyncChange(change, "loan accounts are created through the API"), nil
+		}
+
+		onBudget := data.OnBudget == nil || *data.OnBudget
+		if data.Type == model.AccountTypeInvestment {
+			if data.OnBudget != nil && *data.OnBudget {
+				return rejectSyncChange(change, "investment accounts are tracked off budget"), nil
+			}
+			onBudget = false
+		}
+
+		if data.Currency != nil && !data.Currency.IsValid() {
+			return rejectSyncChange(change, "invalid currency"), nil
+		}
+
+		err := tx.GetContext(ctx, &saved, `
+			INSERT INTO accounts (user_id, client_id, name, type, is_archived, order_num, on_budget, currency, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, (SELECT base_currency FROM users WHERE id = $1)), $9, $9)
+			RETURNING id, version`,
+			userID, change.ClientID, data.Name, data.Type, data.IsArchived, data.OrderNum, onBudget, data.Currency, change.UpdatedAt,
+		)
+		if err != nil {
+			return model.SyncChangeResult{}, err
+		}
+
+		// Категория платежа по карте создаётся так же, как в AccountRepository.Create
+		if data.Type == model.AccountTypeCredit && onBudget {
+			err = insertPaymentCategory(ctx, tx, userID, saved.ID, data.Name, change.UpdatedAt)
+			if err != nil {
+				return model.SyncChangeResult{}, err
+			}
+		}
+
+		return applySyncChange(change, saved), nil
+	}
+
+	err := tx.GetContext(ctx, &saved, `
+		UPDATE accounts SET name = $2, is_archived = $3, order_num = $4, updated_at = $5
+		WHERE id = $1
+		RETURNING id, version`,
+		row.ID, data.Name, data.IsArchived, data.OrderNum, change.UpdatedAt,
+	)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE categories SET name = $2, updated_at = $3 WHERE payment_account_id = $1`,
+		row.ID, data.Name, change.UpdatedAt)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+
+	return applySyncChange(change, saved), nil
+}
+
+func (r SyncRepositoryPostgres) upsertCategory(ctx context.Context, tx *sqlx.Tx, userID uint64, change model.SyncChangeRecord, row *syncRow) (model.SyncChangeResult, error) {
+	data := change.Category
+	var saved syncRow
+
+	var targetType *model.CategoryTargetType
+	var targetAmount *decimal.Decimal
+	var targetDate *time.Time
+	if data.Target != nil {
+		targetType, targetAmount, targetDate = &data.Target.Type, &data.Target.Amount, data.Target.Date
+	}
+
+	groupID, err := r.resolveReference(ctx, tx, model.SyncEntityCategoryGroup, userID, data.GroupID, data.GroupClientID)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+	if groupID == nil && (data.GroupID != nil || data.GroupClientID != nil) {
+		return rejectSyncChange(change, "category group not found"), nil
+	}
+
+	if row == nil {
+		err = tx.GetContext(ctx, &saved, `
+			INSERT INTO categories (user_id, client_id, name, group_id, sort_order, is_hidden, target_type, target_amount, target_date, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+			RETURNING id, version`,
+			userID, change.ClientID, data.Name, groupID, data.SortOrder, data.IsHidden, targetType, targetAmount, targetDate, change.UpdatedAt,
+		)
+	} else {
+		err = tx.GetContext(ctx, &saved, `
+			UPDATE categories
+			SET name = $2, group_id = $3, sort_order = $4, is_hidden = $5, target_type = $6, target_amount = $7, target_date = $8, updated_at = $9
+			WHERE id = $1
+			RETURNING id, version`,
+			row.ID, data.Name, groupID, data.SortOrder, data.IsHidden, targetType, targetAmount, targetDate, change.UpdatedAt,
+		)
+	}
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+
+	return applySyncChange(change, saved), nil
+}
+
+func (r SyncRepositoryPostgres) upsertCategoryGroup(ctx context.Context, tx *sqlx.Tx, userID uint64, change model.SyncChangeRecord, row *syncRow) (model.SyncChangeResult, error) {
+	data := change.CategoryGroup
+	var saved syncRow
+
+	var err error
+	if row == nil {
+		err = tx.GetContext(ctx, &saved, `
+			INSERT INTO category_groups (user_id, client_id, name, sort_order, is_hidden, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6)
+			RETURNING id, version`,
+			userID, change.ClientID, data.Name, data.SortOrder, data.IsHidden, change.UpdatedAt,
+		)
+	} else {
+		err = tx.GetContext(ctx, &saved, `
+			UPDATE category_groups SET name = $2, sort_order = $3, is_hidden = $4, updated_at = $5
+			WHERE id = $1
+			RETURNING id, version`,
+			row.ID, data.Name, data.SortOrder, data.IsHidden, change.UpdatedAt,
+		)
+	}
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+
+	return applySyncChange(change, saved), nil
+}
+
+func (r SyncRepositoryPostgres) upsertTransaction(ctx context.Context, tx *sqlx.Tx, userID uint64, change model.SyncChangeRecord, row *syncRow) (model.SyncChangeResult, error) {
+	data := change.Transaction
+	var saved syncRow
+
+	accountID, err := r.resolveReference(ctx, tx, model.SyncEntityAccount, userID, data.AccountID, data.AccountClientID)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+	if accountID == nil {
+		return rejectSyncChange(change, "account not found"), nil
+	}
+
+	categoryID, err := r.resolveReference(ctx, tx, model.SyncEntityCategory, userID, data.CategoryID, data.CategoryClientID)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+	if categoryID == nil && (data.CategoryID != nil || data.CategoryClientID != nil) {
+		return rejectSyncChange(change, "category not found"), nil
+	}
+
+	if categoryID != nil {
+		var onBudget bool
+		err = tx.GetContext(ctx, &onBudget, `SELECT on_budget FROM accounts WHERE id = $1`, *accountID)
+		if err != nil {
+			return model.SyncChangeResult{}, err
+		}
+		if !onBudget {
+			return rejectSyncChange(change, "off-budget account transactions have no category"), nil
+		}
+	}
+
+	if row == nil {
+		err = tx.GetContext(ctx, &saved, `
+			INSERT INTO transactions (user_id, client_id, account_id, category_id, amount, date, note, approved, cleared, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+			RETURNING id, version`,
+			userID, change.ClientID, *accountID, categoryID, data.Amount, data.Date, data.Note, data.IsApproved, data.IsCleared, change.UpdatedAt,
+		)
+		if err != nil {
+			return model.SyncChangeResult{}, err
+		}
+
+		return applySyncChange(change, saved), nil
+	}
+
+	// Переводы и разбивки меняют сразу несколько строк, офлайн их правка не поддерживается
+	var compound bool
+	err = tx.GetContext(ctx, &compound, `
+		SELECT t.transfer_transaction_id IS NOT NULL
+		           OR EXISTS(SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)
+		FROM transactions t WHERE t.id = $1`, row.ID)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+	if compound {
+		return rejectSyncChange(change, "transfers and split transactions cannot be changed through sync"), nil
+	}
+
+	var investment bool
+	err = tx.GetContext(ctx, &investment, `
+		SELECT origin IN ('investment_trade', 'dividend') FROM transactions WHERE id = $1`, row.ID)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+	if investment {
+		return rejectSyncChange(change, "investment activities cannot be changed through sync"), nil
+	}
+
+	// Снять блокировку сверки можно только через API транзакций
+	var reconciledChange bool
+	err = tx.GetContext(ctx, &reconciledChange, `
+		SELECT reconciled_at IS NOT NULL AND (account_id <> $2 OR amount <> $3 OR date <> $4)
+		FROM transactions WHERE id = $1`, row.ID, *accountID, data.Amount, data.Date)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+	if reconciledChange {
+		return rejectSyncChange(change, "transaction is reconciled"), nil
+	}
+
+	err = tx.GetContext(ctx, &saved, `
+		UPDATE transactions
+		SET account_id = $2, category_id = $3, amount = $4, date = $5, note = $6, approved = $7, cleared = $8, updated_at = $9
+		WHERE id = $1
+		RETURNING id, version`,
+		row.ID, *accountID, categoryID, data.Amount, data.Date, data.Note, data.IsApproved, data.IsCleared, change.UpdatedAt,
+	)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+
+	return applySyncChange(change, saved), nil
+}
+
+func (r SyncRepositoryPostgres) upsertBudgetAllocation(ctx context.Context, tx *sqlx.Tx, userID uint64, change model.SyncChangeRecord, row *syncRow) (model.SyncChangeResult, error) {
+	data := change.BudgetAllocation
+	var saved syncRow
+
+	categoryID, err := r.resolveReference(ctx, tx, model.SyncEntityCategory, userID, data.CategoryID, data.CategoryClientID)
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+	if categoryID == nil {
+		return rejectSyncChange(change, "category not found"), nil
+	}
+
+	if row == nil {
+		// Назначение на тот же месяц могло прийти с другого устройства — сливаем с ним
+		err = tx.GetContext(ctx, &saved, `
+			INSERT INTO budget_allocations (user_id, client_id, category_id, year, month, assigned, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+			ON CONFLICT (user_id, category_id, year, month)
+			DO UPDATE SET assigned   = EXCLUDED.assigned,
+			              client_id  = COALESCE(budget_allocations.client_id, EXCLUDED.client_id),
+			              updated_at = EXCLUDED.updated_at
+			RETURNING id, version`,
+			userID, change.ClientID, *categoryID, data.Year, data.Month, data.Assigned, change.UpdatedAt,
+		)
+	} else {
+		err = tx.GetContext(ctx, &saved, `
+			UPDATE budget_allocations SET category_id = $2, year = $3, month = $4, assigned = $5, updated_at = $6
+			WHERE id = $1
+			RETURNING id, version`,
+			row.ID, *categoryID, data.Year, data.Month, data.Assigned, change.UpdatedAt,
+		)
+	}
+	if err != nil {
+		return model.SyncChangeResult{}, err
+	}
+
+	return applySyncChange(change, saved), nil
+}
+
+// resolveReference находит ID записи пользователя по серверному ID или client_id.
+// Возвращает nil, если ссылка не задана или запись не найдена.
+func (r SyncRepositoryPostgres) resolveReference(ctx context.Context, tx *sqlx.Tx, entity model.SyncEntity, userID uint64, id *uint64, clientID *string) (*uint64, error) {
+	query := r.sq.Select("id").From(string(entity)).
+		Where(sq.Eq{"user_id": userID})
+	if softDeletedEntities[entity] {
+		query = query.Where("deleted_at IS NULL")
+	}
+
+	switch {
+	case id != nil:
+		query = query.Where(sq.Eq{"id": *id})
+	case clientID != nil:
+		query = query.Where(sq.Eq{"client_id": *clientID})
+	default:
+		return nil, nil
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved uint64
+	err = tx.GetContext(ctx, &resolved, sqlQuery, args...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &resolved, nil
+}
+
+func applySyncChange(change model.SyncChangeRecord, saved syncRow) model.SyncChangeResult {
+	return model.SyncChangeResult{
+		Entity:   change.Entity,
+		ID:       saved.ID,
+		ClientID: change.ClientID,
+		Status:   model.SyncChangeApplied,
+		Version:  saved.Version,
+	}
+}
+
+func rejectSyncChange(change model.SyncChangeRecord, reason string) model.SyncChangeResult {
+	result := model.SyncChangeResult{
+		Entity:   change.Entity,
+		ClientID: change.ClientID,
+		Status:   model.SyncChangeRejected,
+		Error:    reason,
+	}
+	if change.ID != nil {
+		result.ID = *change.ID
+	}
+
+	return result
+}
@@ -7,6 +7,9 @@ import (
 	"github.com/jmoiron/sqlx"
 	"litespend-api/internal/model"
 	"litespend-api/internal/repository/databases"
+	"slices"
+	"strings"
+	"time"
 )
 
 type TransactionRepositoryPostgres struct {
@@ -26,8 +29,8 @@ func (r TransactionRepositoryPostgres) Create(ctx context.Context, transaction m
 
 	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
 		err := tx.GetContext(ctx, &createdID,
-			`INSERT INTO transactions(user_id, account_id, category_id, amount, date, note, approved, cleared, created_at, updated_at) 
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`,
+			`INSERT INTO transactions(user_id, account_id, category_id, amount, date, note, approved, cleared, created_at, updated_at, prescribed_expanse_id, scheduled_date, payee_id) 
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id`,
 			transaction.UserID,
 			transaction.AccountID,
 			transaction.CategoryID,
@@ -38,11 +41,19 @@ func (r TransactionRepositoryPostgres) Create(ctx context.Context, transaction m
 			transaction.IsCleared,
 			transaction.CreatedAt,
 			transaction.UpdatedAt,
+			transaction.PrescribedExpanseID,
+			transaction.ScheduledDate,
+			transaction.PayeeID,
 		)
 		if err != nil {
 			return err
 		}
-		return nil
+
+		if err = rememberPayeeCategory(ctx, tx, createdID); err != nil {
+			return err
+		}
+
+		return r.insertSplits(ctx, tx, createdID, transaction.Splits, transaction.CreatedAt)
 	})
 	if err != nil {
 		return 0, err
@@ -52,6 +63,12 @@ func (r TransactionRepositoryPostgres) Create(ctx context.Context, transaction m
 }
 
 func (r TransactionRepositoryPostgres) Update(ctx context.Context, id int, dto model.UpdateTransactionRecord) error {
+	return databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		return r.update(ctx, tx, id, dto)
+	})
+}
+
+func (r TransactionRepositoryPostgres) update(ctx context.Context, db sqlx.ExecerContext, id int, dto model.UpdateTransactionRecord) error {
 	query := r.sq.Update("transactions").Where(sq.Eq{"id": id})
 
 	if dto.AccountID != nil {
@@ -62,6 +79,19 @@ func (r TransactionRepositoryPostgres) Update(ctx context.Context, id int, dto m
 		query = query.Set("category_id", *dto.CategoryID)
 	}
 
+	if dto.PayeeID != nil {
+		query = query.Set("payee_id", *dto.PayeeID)
+	}
+
+	if dto.ClearPayee {
+		query = query.Set("payee_id", nil)
+	}
+
+	// У разбитой транзакции категории задаются только в строках разбивки
+	if dto.ClearCategory || dto.Splits != nil && len(*dto.Splits) > 0 {
+		query = query.Set("category_id", nil)
+	}
+
 	if dto.Amount != nil {
 		query = query.Set("amount", *dto.Amount)
 	}
@@ -82,23 +112,236 @@ func (r TransactionRepositoryPostgres) Update(ctx context.Context, id int, dto m
 		query = query.Set("approved", *dto.IsApproved)
 	}
 
-	query.Set("updated_at", dto.UpdatedAt)
+	if dto.Unreconcile {
+		query = query.Set("reconciled_at", nil)
+	}
+
+	query = query.Set("updated_at", dto.UpdatedAt)
 
 	sqlQuery, args, err := query.ToSql()
 	if err != nil {
 		return err
 	}
 
-	_, err = r.db.ExecContext(ctx, sqlQuery, args...)
+	_, err = db.ExecContext(ctx, sqlQuery, args...)
 	if err != nil {
 		return err
 	}
 
+	if dto.PayeeID != nil || dto.CategoryID != nil {
+		if err = rememberPayeeCategory(ctx, db, id); err != nil {
+			return err
+		}
+	}
+
+	if dto.Splits != nil {
+		_, err = db.ExecContext(ctx, `DELETE FROM transaction_splits WHERE transaction_id = $1`, id)
+		if err != nil {
+			return err
+		}
+
+		return r.insertSplits(ctx, db, id, *dto.Splits, dto.UpdatedAt)
+	}
+
 	return nil
 }
 
-func (r TransactionRepositoryPostgres) Delete(ctx context.Context, id int) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM transactions WHERE id = $1`, id)
+// rememberPayeeCategory запоминает категорию транзакции у её получателя, чтобы
+// предлагать её для следующих транзакций.
+func rememberPayeeCategory(ctx context.Context, db sqlx.ExecerContext, transactionID int) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE payees p SET last_category_id = t.category_id, updated_at = t.updated_at
+		FROM transactions t
+		WHERE t.id = $1
+			AND p.id = t.payee_id
+			AND t.category_id IS NOT NULL
+			AND p.last_category_id IS DISTINCT FROM t.category_id`, transactionID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r TransactionRepositoryPostgres) insertSplits(ctx context.Context, db sqlx.ExecerContext, transactionID int, splits []model.CreateTransactionSplitRecord, createdAt time.Time) error {
+	if len(splits) == 0 {
+		return nil
+	}
+
+	query := r.sq.Insert("transaction_splits").Columns("transaction_id", "category_id", "amount", "note", "created_at", "updated_at")
+	for _, split := range splits {
+		query = query.Values(transactionID, split.CategoryID, split.Amount, split.Note, createdAt, createdAt)
+	}
+
+	sqlQuery, args, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, sqlQuery, args...)
+	return err
+}
+
+// attachSplits подгружает строки разбивки для переданных транзакций.
+func (r TransactionRepositoryPostgres) attachSplits(ctx context.Context, db sqlx.QueryerContext, transactions []model.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	ids := make([]uint64, 0, len(transactions))
+	for _, transaction := range transactions {
+		ids = append(ids, transaction.ID)
+	}
+
+	sqlQuery, args, err := r.sq.Select("*").From("transaction_splits").
+		Where(sq.Eq{"transaction_id": ids}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	var splits []model.TransactionSplit
+	err = sqlx.SelectContext(ctx, db, &splits, sqlQuery, args...)
+	if err != nil {
+		return err
+	}
+
+	byTransaction := make(map[uint64][]model.TransactionSplit, len(splits))
+	for _, split := range splits {
+		byTransaction[split.TransactionID] = append(byTransaction[split.TransactionID], split)
+	}
+
+	for i := range transactions {
+		transactions[i].Splits = byTransaction[transactions[i].ID]
+	}
+
+	return nil
+}
+
+// CreateTransfer создаёт обе половины перевода и связывает их друг с другом.
+func (r TransactionRepositoryPostgres) CreateTransfer(ctx context.Context, from model.CreateTransactionRecord, to model.CreateTransactionRecord) (int, int, error) {
+	var fromID, toID int
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		for _, leg := range []struct {
+			record model.CreateTransactionRecord
+			id     *int
+		}{{from, &fromID}, {to, &toID}} {
+			err := tx.GetContext(ctx, leg.id,
+				`INSERT INTO transactions(user_id, account_id, category_id, amount, date, note, approved, cleared, created_at, updated_at) 
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`,
+				leg.record.UserID,
+				leg.record.AccountID,
+				leg.record.CategoryID,
+				leg.record.Amount,
+				leg.record.Date,
+				leg.record.Note,
+				leg.record.IsApproved,
+				leg.record.IsCleared,
+				leg.record.CreatedAt,
+				leg.record.UpdatedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			UPDATE transactions SET transfer_transaction_id = CASE id WHEN $1 THEN $2 ELSE $1 END
+			WHERE id IN ($1, $2)`, fromID, toID)
+		return err
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return fromID, toID, nil
+}
+
+// ConvertToTransfer делает транзакцию половиной перевода: правит её и добавляет
+// вторую половину pair. Возвращает ID второй половины.
+func (r TransactionRepositoryPostgres) ConvertToTransfer(ctx context.Context, id int, dto model.UpdateTransactionRecord, pair model.CreateTransactionRecord) (int, error) {
+	var pairID int
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := r.update(ctx, tx, id, dto); err != nil {
+			return err
+		}
+
+		err := tx.GetContext(ctx, &pairID,
+			`INSERT INTO transactions(user_id, account_id, category_id, amount, date, note, approved, cleared, created_at, updated_at) 
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`,
+			pair.UserID,
+			pair.AccountID,
+			pair.CategoryID,
+			pair.Amount,
+			pair.Date,
+			pair.Note,
+			pair.IsApproved,
+			pair.IsCleared,
+			pair.CreatedAt,
+			pair.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE transactions SET transfer_transaction_id = CASE id WHEN $1 THEN $2 ELSE $1 END
+			WHERE id IN ($1, $2)`, id, pairID)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return pairID, nil
+}
+
+// UpdateTransfer изменяет половину перевода и синхронно вторую половину.
+func (r TransactionRepositoryPostgres) UpdateTransfer(ctx context.Context, id int, dto model.UpdateTransactionRecord, pairID int, pairDto model.UpdateTransactionRecord) error {
+	return databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		if err := r.update(ctx, tx, id, dto); err != nil {
+			return err
+		}
+
+		return r.update(ctx, tx, pairID, pairDto)
+	})
+}
+
+// Delete переносит транзакцию в корзину.
+func (r TransactionRepositoryPostgres) Delete(ctx context.Context, id int, deletedAt time.Time) error {
+	// Вместе с переводом удаляется и его вторая половина, а с платежом по кредиту —
+	// начисленные при нём проценты
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE transactions SET deleted_at = $2
+		WHERE (id = $1 OR transfer_transaction_id = $1 OR id IN (
+				SELECT lp.interest_transaction_id FROM loan_payments lp
+				JOIN transactions p ON p.id = lp.transaction_id
+				WHERE p.id = $1 OR p.transfer_transaction_id = $1
+			))
+			AND deleted_at IS NULL`, id, deletedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Restore возвращает транзакцию из корзины вместе со второй половиной перевода
+// и процентами платежа по кредиту.
+func (r TransactionRepositoryPostgres) Restore(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE transactions t SET deleted_at = NULL
+		FROM transactions src
+		WHERE src.id = $1
+		  AND t.deleted_at = src.deleted_at
+		  AND (t.id = src.id OR t.transfer_transaction_id = src.id OR t.id IN (
+				SELECT lp.interest_transaction_id FROM loan_payments lp
+				JOIN transactions p ON p.id = lp.transaction_id
+				WHERE p.id = src.id OR p.transfer_transaction_id = src.id
+			))`, id)
 	if err != nil {
 		return err
 	}
@@ -107,20 +350,45 @@ func (r TransactionRepositoryPostgres) Delete(ctx context.Context, id int) error
 }
 
 func (r TransactionRepositoryPostgres) GetByID(ctx context.Context, id int) (model.Transaction, error) {
+	return r.getByID(ctx, id, false)
+}
+
+// GetDeletedByID возвращает транзакцию из корзины.
+func (r TransactionRepositoryPostgres) GetDeletedByID(ctx context.Context, id int) (model.Transaction, error) {
+	return r.getByID(ctx, id, true)
+}
+
+func (r TransactionRepositoryPostgres) getByID(ctx context.Context, id int, deleted bool) (model.Transaction, error) {
 	var transaction model.Transaction
 
-	err := r.db.GetContext(ctx, &transaction, `SELECT * FROM transactions WHERE id = $1`, id)
+	deletedCondition := "deleted_at IS NULL"
+	if deleted {
+		deletedCondition = "deleted_at IS NOT NULL"
+	}
+
+	err := r.db.GetContext(ctx, &transaction, `SELECT * FROM transactions WHERE id = $1 AND `+deletedCondition, id)
+	if err != nil {
+		return transaction, err
+	}
+
+	transactions := []model.Transaction{transaction}
+	err = r.attachSplits(ctx, r.db, transactions)
 	if err != nil {
 		return transaction, err
 	}
 
-	return transaction, nil
+	return transactions[0], nil
 }
 
 func (r TransactionRepositoryPostgres) GetList(ctx context.Context, userID uint64) ([]model.Transaction, error) {
 	var transactions []model.Transaction = make([]model.Transaction, 0)
 
-	err := r.db.SelectContext(ctx, &transactions, `SELECT * FROM transactions WHERE user_id = $1 ORDER BY date DESC, created_at DESC`, userID)
+	err := r.db.SelectContext(ctx, &transactions, `SELECT * FROM transactions WHERE user_id = $1 AND deleted_at IS NULL ORDER BY date DESC, created_at DESC`, userID)
+	if err != nil {
+		return transactions, err
+	}
+
+	err = r.attachSplits(ctx, r.db, transactions)
 	if err != nil {
 		return transactions, err
 	}
@@ -128,58 +396,228 @@ func (r TransactionRepositoryPostgres) GetList(ctx context.Context, userID uint6
 	return transactions, nil
 }
 
-func (r TransactionRepositoryPostgres) GetListPaginated(ctx context.Context, userID uint64, accountID *uint64, params model.PaginationParams) ([]model.Transaction, int, error) {
+func (r TransactionRepositoryPostgres) GetListPaginated(ctx context.Context, userID uint64, filter model.TransactionFilter, params model.PaginationParams) ([]model.Transaction, int, model.TransactionTotals, error) {
 	var transactions []model.Transaction = make([]model.Transaction, 0)
-	var total int
+	var summary struct {
+		Total int `db:"total"`
+		model.TransactionTotals
+	}
 
-	whereClause := "WHERE t.user_id = $1"
-	args := []interface{}{userID}
-	argIndex := 1
+	conditions := transactionFilterConditions(userID, filter, params.Search)
+
+	countQuery, args, err := r.sq.Select(
+		"COUNT(*) AS total",
+		"COALESCE(SUM(t.amount) FILTER (WHERE t.amount > 0), 0) AS income",
+		"COALESCE(SUM(t.amount) FILTER (WHERE t.amount < 0), 0) AS expense",
+		"COALESCE(SUM(t.amount), 0) AS balance",
+	).
+		From("transactions t").
+		Where(conditions).
+		ToSql()
+	if err != nil {
+		return transactions, 0, summary.TransactionTotals, err
+	}
 
-	if accountID != nil {
-		argIndex++
-		whereClause += fmt.Sprintf(" AND t.account_id = $%d", argIndex)
-		args = append(args, *accountID)
+	err = r.db.GetContext(ctx, &summary, countQuery, args...)
+	if err != nil {
+		return transactions, 0, summary.TransactionTotals, err
 	}
 
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM transactions t %s`, whereClause)
-	err := r.db.GetContext(ctx, &total, countQuery, args...)
+	orderBy := transactionOrderBy(params.SortFieldOrDefault())
+
+	query, args, err := r.sq.Select("t.*").
+		From("transactions t").
+		Where(conditions).
+		OrderBy(orderBy...).
+		Limit(uint64(params.Limit)).
+		Offset(uint64(params.Offset())).
+		ToSql()
 	if err != nil {
-		return transactions, 0, err
+		return transactions, 0, summary.TransactionTotals, err
 	}
 
-	orderBy := "ORDER BY t.date DESC, t.created_at DESC"
-	if params.SortBy != nil {
-		sortOrder := "DESC"
-		if params.SortOrder != nil && *params.SortOrder == model.SortOrderASC {
-			sortOrder = "ASC"
-		}
+	err = r.db.SelectContext(ctx, &transactions, query, args...)
+	if err != nil {
+		return transactions, 0, summary.TransactionTotals, err
+	}
+
+	err = r.attachSplits(ctx, r.db, transactions)
+	if err != nil {
+		return transactions, 0, summary.TransactionTotals, err
+	}
+
+	return transactions, summary.Total, summary.TransactionTotals, nil
+}
 
-		switch *params.SortBy {
-		case model.SortFieldDate:
-			orderBy = fmt.Sprintf("ORDER BY t.date %s", sortOrder)
-		case model.SortFieldCategory:
-			orderBy = fmt.Sprintf("ORDER BY t.category_id %s", sortOrder)
+// GetListByCursor возвращает до params.Limit транзакций после курсора (или перед ним
+// для курсора назад) и признак того, что в этом направлении есть ещё строки.
+func (r TransactionRepositoryPostgres) GetListByCursor(ctx context.Context, userID uint64, filter model.TransactionFilter, params model.PaginationParams, cursor *model.Cursor) ([]model.Transaction, bool, error) {
+	var transactions []model.Transaction = make([]model.Transaction, 0)
+
+	sortBy, sortOrder := params.SortFieldOrDefault()
+	key, ok := transactionSortKeys[sortBy]
+	if !ok {
+		return transactions, false, fmt.Errorf("unknown sort field %q", sortBy)
+	}
+
+	// Предыдущая страница выбирается в обратном порядке и затем разворачивается
+	backward := cursor != nil && cursor.Backward
+	if backward {
+		if sortOrder == model.SortOrderASC {
+			sortOrder = model.SortOrderDESC
+		} else {
+			sortOrder = model.SortOrderASC
 		}
 	}
 
-	argIndex++
-	limitArg := argIndex
-	argIndex++
-	offsetArg := argIndex
-	args = append(args, params.Limit, params.Offset())
+	conditions := transactionFilterConditions(userID, filter, params.Search)
+	if cursor != nil {
+		operator := "<"
+		if sortOrder == model.SortOrderASC {
+			operator = ">"
+		}
+		conditions = append(conditions, sq.Expr(
+			fmt.Sprintf("(%s, t.id) %s (CAST(? AS %s), ?)", key.expr, operator, key.cast),
+			cursor.Key, cursor.ID,
+		))
+	}
 
-	query := fmt.Sprintf(`
-		SELECT t.* FROM transactions t 
-		%s 
-		%s 
-		LIMIT $%d OFFSET $%d
-	`, whereClause, orderBy, limitArg, offsetArg)
+	query, args, err := r.sq.Select("t.*").
+		From("transactions t").
+		Where(conditions).
+		OrderBy(transactionOrderBy(sortBy, sortOrder)...).
+		Limit(uint64(params.Limit + 1)).
+		ToSql()
+	if err != nil {
+		return transactions, false, err
+	}
 
 	err = r.db.SelectContext(ctx, &transactions, query, args...)
 	if err != nil {
-		return transactions, 0, err
+		return transactions, false, err
+	}
+
+	// Лишняя строка только показывает, что дальше есть ещё данные
+	hasMore := len(transactions) > params.Limit
+	if hasMore {
+		transactions = transactions[:params.Limit]
+	}
+
+	if backward {
+		slices.Reverse(transactions)
+	}
+
+	err = r.attachSplits(ctx, r.db, transactions)
+	if err != nil {
+		return transactions, false, err
+	}
+
+	return transactions, hasMore, nil
+}
+
+// transactionSortKeys — выражение ключа сортировки и его тип для сравнения с курсором.
+// NULL заменяется значением, чтобы сравнение кортежей с курсором было определено.
+var transactionSortKeys = map[model.SortField]struct {
+	expr string
+	cast string
+}{
+	model.SortFieldDate:        {expr: "t.date", cast: "date"},
+	model.SortFieldCategory:    {expr: "COALESCE(t.category_id, 0)", cast: "bigint"},
+	model.SortFieldDescription: {expr: "COALESCE(t.note, '')", cast: "text"},
+}
+
+// transactionOrderBy сортирует по ключу и затем по id, чтобы порядок был однозначным.
+func transactionOrderBy(sortBy model.SortField, sortOrder model.SortOrder) []string {
+	key, ok := transactionSortKeys[sortBy]
+	if !ok {
+		key = transactionSortKeys[model.SortFieldDate]
+	}
+
+	direction := "DESC"
+	if sortOrder == model.SortOrderASC {
+		direction = "ASC"
+	}
+
+	return []string{key.expr + " " + direction, "t.id " + direction}
+}
+
+// transactionFilterConditions собирает условия выборки транзакций пользователя.
+func transactionFilterConditions(userID uint64, filter model.TransactionFilter, search *string) sq.And {
+	conditions := sq.And{sq.Eq{"t.user_id": userID}, sq.Expr("t.deleted_at IS NULL")}
+
+	if filter.From != nil {
+		conditions = append(conditions, sq.GtOrEq{"t.date": *filter.From})
+	}
+
+	if filter.To != nil {
+		conditions = append(conditions, sq.LtOrEq{"t.date": *filter.To})
 	}
 
-	return transactions, total, nil
+	if len(filter.AccountIDs) > 0 {
+		conditions = append(conditions, sq.Eq{"t.account_id": filter.AccountIDs})
+	}
+
+	if len(filter.PayeeIDs) > 0 {
+		conditions = append(conditions, sq.Eq{"t.payee_id": filter.PayeeIDs})
+	}
+
+	// Разбитая транзакция подходит, если в категорию попадает хотя бы одна её строка
+	if len(filter.CategoryIDs) > 0 {
+		splits := sq.Select("1").
+			From("transaction_splits s").
+			Where("s.transaction_id = t.id").
+			Where(sq.Eq{"s.category_id": filter.CategoryIDs})
+
+		conditions = append(conditions, sq.Or{
+			sq.Eq{"t.category_id": filter.CategoryIDs},
+			sq.Expr("EXISTS (?)", splits),
+		})
+	}
+
+	if filter.MinAmount != nil {
+		conditions = append(conditions, sq.Expr("ABS(t.amount) >= ?", *filter.MinAmount))
+	}
+
+	if filter.MaxAmount != nil {
+		conditions = append(conditions, sq.Expr("ABS(t.amount) <= ?", *filter.MaxAmount))
+	}
+
+	if filter.IsCleared != nil {
+		conditions = append(conditions, sq.Eq{"t.cleared": *filter.IsCleared})
+	}
+
+	if filter.IsApproved != nil {
+		conditions = append(conditions, sq.Eq{"t.approved": *filter.IsApproved})
+	}
+
+	if filter.Uncategorized {
+		conditions = append(conditions,
+			sq.Eq{"t.category_id": nil, "t.transfer_transaction_id": nil},
+			sq.Expr("NOT EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id)"),
+		)
+	}
+
+	if filter.Kind != nil {
+		switch *filter.Kind {
+		case model.TransactionKindIncome:
+			conditions = append(conditions, sq.Gt{"t.amount": 0})
+		case model.TransactionKindExpense:
+			conditions = append(conditions, sq.Lt{"t.amount": 0})
+		}
+	}
+
+	// Полнотекстовый поиск по заметке, ILIKE — для недописанных слов
+	if search != nil && strings.TrimSpace(*search) != "" {
+		term := strings.TrimSpace(*search)
+		conditions = append(conditions, sq.Expr(
+			"(to_tsvector('russian', t.note) @@ websearch_to_tsquery('russian', ?) OR t.note ILIKE ?)",
+			term, "%"+escapeLike(term)+"%",
+		))
+	}
+
+	return conditions
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
 }
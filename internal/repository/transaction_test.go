@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"litespend-api/internal/model"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/shopspring/decimal"
+)
+
+func TestTransactionFilterConditions(t *testing.T) {
+	from := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	minAmount := decimal.NewFromInt(100)
+	cleared := false
+	expense := model.TransactionKindExpense
+	search := "  кофе_50%  "
+
+	tests := []struct {
+		name     string
+		filter   model.TransactionFilter
+		search   *string
+		wantSQL  []string
+		wantArgs int
+	}{
+		{
+			name:     "без фильтров только активные транзакции пользователя",
+			wantSQL:  []string{"t.user_id = $1", "t.deleted_at IS NULL"},
+			wantArgs: 1,
+		},
+		{
+			name: "категории учитывают строки разбивки",
+			filter: model.TransactionFilter{
+				AccountIDs:  []uint64{1, 2},
+				CategoryIDs: []uint64{3},
+			},
+			wantSQL: []string{
+				"t.account_id IN ($2,$3)",
+				"t.category_id IN ($4) OR EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id AND s.category_id IN ($5))",
+			},
+			wantArgs: 5,
+		},
+		{
+			name: "дата, сумма по модулю, состояние и тип",
+			filter: model.TransactionFilter{
+				From:      &from,
+				MinAmount: &minAmount,
+				IsCleared: &cleared,
+				Kind:      &expense,
+			},
+			wantSQL:  []string{"t.date >= $2", "ABS(t.amount) >= $3", "t.cleared = $4", "t.amount < $5"},
+			wantArgs: 5,
+		},
+		{
+			name:     "без категории исключает разбитые и переводы",
+			filter:   model.TransactionFilter{Uncategorized: true},
+			wantSQL:  []string{"t.category_id IS NULL", "t.transfer_transaction_id IS NULL", "NOT EXISTS (SELECT 1 FROM transaction_splits s"},
+			wantArgs: 1,
+		},
+		{
+			name:     "поиск экранирует спецсимволы LIKE",
+			search:   &search,
+			wantSQL:  []string{"websearch_to_tsquery('russian', $2) OR t.note ILIKE $3"},
+			wantArgs: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+				Select("t.*").From("transactions t").
+				Where(transactionFilterConditions(1, tt.filter, tt.search)).
+				ToSql()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, want := range tt.wantSQL {
+				if !strings.Contains(query, want) {
+					t.Errorf("query %q does not contain %q", query, want)
+				}
+			}
+
+			if len(args) != tt.wantArgs {
+				t.Errorf("got %d args, want %d: %v", len(args), tt.wantArgs, args)
+			}
+
+			if tt.search != nil && args[len(args)-1] != `%кофе\_50\%%` {
+				t.Errorf("like pattern = %v", args[len(args)-1])
+			}
+		})
+	}
+}
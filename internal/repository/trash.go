@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository/databases"
+	"time"
+)
+
+type TrashRepositoryPostgres struct {
+	db           *sqlx.DB
+	sq           sq.StatementBuilderType
+	transactions TransactionRepositoryPostgres
+}
+
+func NewTrashRepositoryPostgres(db *sqlx.DB) TrashRepositoryPostgres {
+	return TrashRepositoryPostgres{
+		db:           db,
+		sq:           sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		transactions: NewTransactionRepositoryPostgres(db),
+	}
+}
+
+func (r TrashRepositoryPostgres) GetList(ctx context.Context, userID uint64) (model.Trash, error) {
+	trash := model.Trash{
+		Accounts:     make([]model.Account, 0),
+		Categories:   make([]model.Category, 0),
+		Transactions: make([]model.Transaction, 0),
+	}
+
+	// Баланс счёта в корзине — по транзакциям, удалённым вместе с ним
+	err := r.db.SelectContext(ctx, &trash.Accounts, `
+		SELECT a.*, COALESCE(SUM(tr.amount), 0) as balance FROM accounts a
+		         LEFT JOIN transactions tr ON a.id = tr.account_id AND tr.deleted_at = a.deleted_at
+		WHERE a.user_id = $1 AND a.deleted_at IS NOT NULL GROUP BY a.id
+		ORDER BY a.deleted_at DESC, a.id`, userID)
+	if err != nil {
+		return trash, err
+	}
+
+	err = r.db.SelectContext(ctx, &trash.Categories, `
+		SELECT * FROM categories WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id`, userID)
+	if err != nil {
+		return trash, err
+	}
+
+	err = r.db.SelectContext(ctx, &trash.Transactions, `
+		SELECT * FROM transactions WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, date DESC, id`, userID)
+	if err != nil {
+		return trash, err
+	}
+
+	err = r.transactions.attachSplits(ctx, r.db, trash.Transactions)
+	if err != nil {
+		return trash, err
+	}
+
+	return trash, nil
+}
+
+// Purge окончательно стирает записи, удалённые раньше before. Категории, на которые
+// ещё ссылаются активные транзакции, и счета с оставшимися транзакциями не трогаются.
+func (r TrashRepositoryPostgres) Purge(ctx context.Context, before time.Time) (model.PurgeResult, error) {
+	var result model.PurgeResult
+
+	err := databases.WithinTransaction(ctx, r.db, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM transactions WHERE deleted_at < $1`, before)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		result.Transactions = int(affected)
+
+		var categoryIDs []uint64
+		err = tx.SelectContext(ctx, &categoryIDs, `
+			SELECT c.id FROM categories c
+			WHERE c.deleted_at < $1
+			  AND NOT EXISTS(SELECT 1 FROM transactions t WHERE t.category_id = c.id)
+			  AND NOT EXISTS(SELECT 1 FROM transaction_splits s WHERE s.category_id = c.id)
+			FOR UPDATE`, before)
+		if err != nil {
+			return err
+		}
+
+		if len(categoryIDs) > 0 {
+			// Вместе с категорией стираются её назначения, иначе они повиснут без владельца
+			for _, query := range []sq.DeleteBuilder{
+				r.sq.Delete("budget_allocations").Where(sq.Eq{"category_id": categoryIDs}),
+				r.sq.Delete("categories").Where(sq.Eq{"id": categoryIDs}),
+			} {
+				sqlQuery, args, err := query.ToSql()
+				if err != nil {
+					return err
+				}
+
+				_, err = tx.ExecContext(ctx, sqlQuery, args...)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		result.Categories = len(categoryIDs)
+
+		var accountIDs []uint64
+		err = tx.SelectContext(ctx, &accountIDs, `
+			SELECT a.id FROM accounts a
+			WHERE a.deleted_at < $1
+			  AND NOT EXISTS(SELECT 1 FROM transactions t WHERE t.account_id = a.id)
+			FOR UPDATE`, before)
+		if err != nil {
+			return err
+		}
+
+		if len(accountIDs) > 0 {
+			// Категории платежа карты удаляются каскадно вместе со счётом, их
+			// назначения стираются заранее по той же причине, что и выше
+			paymentCategories := r.sq.Select("id").From("categories").Where(sq.Eq{"payment_account_id": accountIDs})
+			for _, query := range []sq.DeleteBuilder{
+				r.sq.Delete("budget_allocations").Where(sq.Expr("category_id IN (?)", paymentCategories)),
+				r.sq.Delete("accounts").Where(sq.Eq{"id": accountIDs}),
+			} {
+				sqlQuery, args, err := query.ToSql()
+				if err != nil {
+					return err
+				}
+
+				_, err = tx.ExecContext(ctx, sqlQuery, args...)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		result.Accounts = len(accountIDs)
+
+		return nil
+	})
+	if err != nil {
+		return model.PurgeResult{}, err
+	}
+
+	return result, nil
+}
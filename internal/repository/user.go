@@ -53,6 +53,14 @@ func (r UserRepositoryPostgres) Update(ctx context.Context, id int, dto model.Up
 		query = query.Set("password_hash", *dto.PasswordHash)
 	}
 
+	if dto.OverspendingMode != nil {
+		query = query.Set("overspending_mode", *dto.OverspendingMode)
+	}
+
+	if dto.BaseCurrency != nil {
+		query = query.Set("base_currency", *dto.BaseCurrency)
+	}
+
 	sqlQuery, args, _ := query.ToSql()
 
 	_, err := r.db.ExecContext(ctx, sqlQuery, args...)
@@ -2,9 +2,27 @@ package service
 
 import (
 	"context"
+	"errors"
 	"litespend-api/internal/model"
 	"litespend-api/internal/repository"
+	"strings"
 	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	startingBalanceNote          = "Начальный остаток"
+	balanceAdjustmentNote        = "Корректировка баланса"
+	reconciliationAdjustmentNote = "Корректировка при сверке"
+)
+
+var (
+	ErrAccountNotFound        = errors.New("account not found")
+	ErrAccountHasTransactions = errors.New("account has transactions, choose archive, cascade or reassign mode")
+	ErrInvalidAccountDeletion = errors.New("invalid account deletion")
+	ErrAccountInTrash         = errors.New("account is in trash")
+	ErrReconciliationMismatch = errors.New("cleared balance does not match statement balance")
 )
 
 type AccountService struct {
@@ -16,15 +34,67 @@ func NewAccountService(repository repository.AccountRepository) *AccountService
 }
 
 func (s *AccountService) Create(ctx context.Context, logined model.User, account model.CreateAccountRequest) (uint64, error) {
-	createdID, err := s.repo.Create(ctx, model.CreateAccountRecord{
+	isLoan := account.Type == model.AccountTypeLoan
+	if isLoan != (account.Loan != nil) || (isLoan && !account.Loan.IsValid()) {
+		return 0, ErrInvalidLoanTerms
+	}
+
+	// Сделки с бумагами не должны менять бюджет, поэтому инвестиционный счёт только внебюджетный
+	isInvestment := account.Type == model.AccountTypeInvestment
+	if isInvestment && account.OnBudget != nil && *account.OnBudget {
+		return 0, ErrOnBudgetInvestment
+	}
+
+	currency := logined.BaseCurrency
+	if account.Currency != nil {
+		currency = normalizeCurrency(*account.Currency)
+		if !currency.IsValid() {
+			return 0, ErrInvalidCurrency
+		}
+	}
+
+	now := time.Now()
+	record := model.CreateAccountRecord{
 		UserID:     logined.ID,
 		Name:       account.Name,
 		Type:       account.Type,
 		IsArchived: account.IsArchived,
 		OrderNum:   account.OrderNum,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
-	})
+		OnBudget:   !isLoan && !isInvestment,
+		Currency:   currency,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if account.OnBudget != nil {
+		record.OnBudget = *account.OnBudget
+	}
+
+	// Кредит без заданного остатка открывается с долгом на всю сумму на дату выдачи
+	if isLoan {
+		record.Loan = &model.LoanTermsRecord{
+			Principal:    account.Loan.Principal,
+			InterestRate: account.Loan.InterestRate,
+			TermMonths:   account.Loan.TermMonths,
+			PaymentDay:   account.Loan.PaymentDay,
+			StartDate:    account.Loan.StartDate,
+		}
+
+		if account.StartingBalance == nil {
+			principal := account.Loan.Principal.Neg()
+			account.StartingBalance, account.StartingDate = &principal, &account.Loan.StartDate
+		}
+	}
+
+	// Нулевой остаток не проводится, чтобы не засорять историю пустой транзакцией
+	if account.StartingBalance != nil && !account.StartingBalance.IsZero() {
+		record.StartingBalance = &model.AccountBalanceRecord{
+			Amount: *account.StartingBalance,
+			Date:   balanceDate(account.StartingDate, now),
+			Note:   startingBalanceNote,
+		}
+	}
+
+	createdID, err := s.repo.Create(ctx, record)
 	if err != nil {
 		return 0, err
 	}
@@ -32,6 +102,93 @@ func (s *AccountService) Create(ctx context.Context, logined model.User, account
 	return createdID, nil
 }
 
+// AdjustBalance выставляет баланс счёта: разница с текущим балансом проводится
+// корректирующей транзакцией.
+func (s *AccountService) AdjustBalance(ctx context.Context, logined model.User, id uint64, req model.AdjustAccountBalanceRequest) (model.AccountBalanceAdjustment, error) {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.AccountBalanceAdjustment{}, ErrAccountNotFound
+	}
+
+	if account.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.AccountBalanceAdjustment{}, ErrAccessDenied
+	}
+
+	now := time.Now()
+	note := balanceAdjustmentNote
+	if req.Note != nil && strings.TrimSpace(*req.Note) != "" {
+		note = *req.Note
+	}
+
+	return s.repo.AdjustBalance(ctx, model.AdjustAccountBalanceRecord{
+		AccountID: id,
+		Balance:   req.Balance,
+		Date:      balanceDate(req.Date, now),
+		Note:      note,
+		CreatedAt: now,
+	})
+}
+
+// GetReconciliation сравнивает остаток по выписке на дату с подтверждёнными транзакциями.
+func (s *AccountService) GetReconciliation(ctx context.Context, logined model.User, id uint64, date time.Time, statementBalance decimal.Decimal) (model.AccountReconciliation, error) {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.AccountReconciliation{}, ErrAccountNotFound
+	}
+
+	if account.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.AccountReconciliation{}, ErrAccessDenied
+	}
+
+	balance, err := s.repo.GetClearedBalance(ctx, id, date)
+	if err != nil {
+		return model.AccountReconciliation{}, err
+	}
+
+	return model.NewAccountReconciliation(id, date, statementBalance, balance), nil
+}
+
+// Reconcile подтверждает сверку: подтверждённые транзакции блокируются как сверенные.
+// Если остаток не сходится и корректировка не запрошена, возвращается
+// ErrReconciliationMismatch вместе с расхождением.
+func (s *AccountService) Reconcile(ctx context.Context, logined model.User, id uint64, req model.ReconciliationRequest) (model.ReconciliationResult, error) {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.ReconciliationResult{}, ErrAccountNotFound
+	}
+
+	if account.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.ReconciliationResult{}, ErrAccessDenied
+	}
+
+	result, err := s.repo.Reconcile(ctx, model.ReconcileAccountRecord{
+		AccountID:        id,
+		Date:             req.Date,
+		StatementBalance: req.StatementBalance,
+		CreateAdjustment: req.CreateAdjustment,
+		AdjustmentNote:   reconciliationAdjustmentNote,
+		ReconciledAt:     time.Now(),
+	})
+	if err != nil {
+		return model.ReconciliationResult{}, err
+	}
+
+	if !result.Difference.IsZero() && result.AdjustmentTransactionID == nil {
+		return result, ErrReconciliationMismatch
+	}
+
+	return result, nil
+}
+
+// balanceDate возвращает дату служебной транзакции: переданную или сегодняшнюю.
+func balanceDate(date *time.Time, now time.Time) time.Time {
+	if date != nil {
+		return *date
+	}
+
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
 func (s *AccountService) GetList(ctx context.Context, logined model.User) ([]model.Account, error) {
 	accounts, err := s.repo.GetList(ctx, logined.ID)
 	if err != nil {
@@ -41,17 +198,88 @@ func (s *AccountService) GetList(ctx context.Context, logined model.User) ([]mod
 	return accounts, nil
 }
 
-func (s *AccountService) Delete(ctx context.Context, logined model.User, id uint64) error {
+// Delete переносит счёт в корзину. Если у счёта есть транзакции, нужно выбрать
+// режим: архивировать счёт, удалить транзакции вместе с ним или перенести их на
+// другой счёт, см. CanTakeTransactionsOf.
+func (s *AccountService) Delete(ctx context.Context, logined model.User, id uint64, req model.DeleteAccountRequest) error {
 	account, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return ErrAccountNotFound
+	}
+
+	if account.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return ErrAccessDenied
+	}
+
+	if req.Mode != "" && !req.Mode.IsValid() {
+		return ErrInvalidAccountDeletion
+	}
+
+	if req.Mode == "" {
+		count, err := s.repo.CountTransactions(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return ErrAccountHasTransactions
+		}
+	}
+
+	now := time.Now()
+
+	switch req.Mode {
+	case model.AccountDeleteModeArchive:
+		archived := true
+		return s.repo.Update(ctx, id, model.UpdateAccountRecord{
+			IsArchived: &archived,
+			UpdatedAt:  now,
+		})
+	case model.AccountDeleteModeReassign:
+		if req.TargetAccountID == nil || *req.TargetAccountID == id {
+			return ErrInvalidAccountDeletion
+		}
+
+		target, err := s.repo.GetByID(ctx, *req.TargetAccountID)
+		if err != nil || !target.CanTakeTransactionsOf(account) {
+			return ErrInvalidAccountDeletion
+		}
+
+		// Перевод между счетами после переноса оказался бы переводом счёта самому себе
+		transfers, err := s.repo.CountTransfersWith(ctx, id, target.ID)
+		if err != nil {
+			return err
+		}
+		if transfers > 0 {
+			return ErrInvalidAccountDeletion
+		}
+	}
+
+	err = s.repo.Delete(ctx, model.DeleteAccountRecord{
+		ID:              id,
+		Mode:            req.Mode,
+		TargetAccountID: req.TargetAccountID,
+		DeletedAt:       now,
+	})
 	if err != nil {
 		return err
 	}
 
+	return nil
+}
+
+// Restore возвращает счёт из корзины вместе с транзакциями, удалёнными каскадно.
+func (s *AccountService) Restore(ctx context.Context, logined model.User, id uint64) error {
+	account, err := s.repo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return ErrAccountNotFound
+	}
+
 	if account.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
 		return ErrAccessDenied
 	}
 
-	err = s.repo.Delete(ctx, id)
+	err = s.repo.Restore(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -9,18 +9,35 @@ import (
 )
 
 var (
-	ErrBudgetNotFound = errors.New("budget not found")
+	ErrBudgetNotFound      = errors.New("budget not found")
+	ErrInvalidBudgetPeriod = errors.New("invalid budget period")
+	ErrInvalidQuickAssign  = errors.New("invalid quick assign source")
+	ErrInvalidBudgetMove   = errors.New("invalid budget move")
+	ErrBudgetExists        = errors.New("budget for this category and month already exists")
+	ErrInvalidBudgetUpdate = errors.New("budget category and period cannot be changed")
 )
 
 type BudgetService struct {
-	repo repository.BudgetRepository
+	repo         repository.BudgetRepository
+	categoryRepo repository.CategoryRepository
+	rateRepo     repository.ExchangeRateRepository
 }
 
-func NewBudgetService(repository repository.BudgetRepository) *BudgetService {
-	return &BudgetService{repo: repository}
+func NewBudgetService(repository repository.BudgetRepository, categoryRepo repository.CategoryRepository, rateRepo repository.ExchangeRateRepository) *BudgetService {
+	return &BudgetService{repo: repository, categoryRepo: categoryRepo, rateRepo: rateRepo}
 }
 
+// Create заводит назначение категории на месяц. Если назначение уже есть,
+// возвращается ErrBudgetExists: менять его нужно через Update или Assign.
 func (s *BudgetService) Create(ctx context.Context, logined model.User, req model.CreateBudgetAllocationRequest) (int, error) {
+	if !isValidBudgetPeriod(req.Year, req.Month) {
+		return 0, ErrInvalidBudgetPeriod
+	}
+
+	if err := s.checkCategory(ctx, logined, req.CategoryID); err != nil {
+		return 0, err
+	}
+
 	record := model.CreateBudgetAllocationRecord{
 		UserID:     logined.ID,
 		CategoryID: req.CategoryID,
@@ -29,9 +46,114 @@ func (s *BudgetService) Create(ctx context.Context, logined model.User, req mode
 		Assigned:   req.Assigned,
 		CreatedAt:  time.Now(),
 	}
-	return s.repo.Create(ctx, record)
+
+	id, err := s.repo.Create(ctx, record)
+	if err != nil {
+		return 0, err
+	}
+
+	if id == 0 {
+		return 0, ErrBudgetExists
+	}
+
+	return id, nil
+}
+
+func (s *BudgetService) Assign(ctx context.Context, logined model.User, year uint, month uint, req model.AssignBudgetRequest) (model.BudgetAllocation, error) {
+	if !isValidBudgetPeriod(year, month) {
+		return model.BudgetAllocation{}, ErrInvalidBudgetPeriod
+	}
+
+	if err := s.checkCategory(ctx, logined, req.CategoryID); err != nil {
+		return model.BudgetAllocation{}, err
+	}
+
+	return s.repo.Assign(ctx, model.AssignBudgetRecord{
+		UserID:     logined.ID,
+		CategoryID: req.CategoryID,
+		Year:       year,
+		Month:      month,
+		Amount:     req.Amount,
+		UpdatedAt:  time.Now(),
+	})
+}
+
+func (s *BudgetService) QuickAssign(ctx context.Context, logined model.User, year uint, month uint, req model.QuickAssignRequest) (model.QuickAssignResult, error) {
+	if !isValidBudgetPeriod(year, month) {
+		return model.QuickAssignResult{}, ErrInvalidBudgetPeriod
+	}
+
+	if req.Source != model.QuickAssignSourceLastMonthAssigned && req.Source != model.QuickAssignSourceLastMonthSpent {
+		return model.QuickAssignResult{}, ErrInvalidQuickAssign
+	}
+
+	sourceYear, sourceMonth := year, month-1
+	if month == 1 {
+		sourceYear, sourceMonth = year-1, 12
+	}
+
+	updated, err := s.repo.QuickAssign(ctx, model.QuickAssignRecord{
+		UserID:      logined.ID,
+		Year:        year,
+		Month:       month,
+		Source:      req.Source,
+		SourceYear:  sourceYear,
+		SourceMonth: sourceMonth,
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		return model.QuickAssignResult{}, err
+	}
+
+	return model.QuickAssignResult{CategoriesUpdated: updated}, nil
+}
+
+// MoveMoney перекрывает перерасход одной категории деньгами из другой.
+func (s *BudgetService) MoveMoney(ctx context.Context, logined model.User, year uint, month uint, req model.MoveBudgetRequest) (model.BudgetMove, error) {
+	if !isValidBudgetPeriod(year, month) {
+		return model.BudgetMove{}, ErrInvalidBudgetPeriod
+	}
+
+	if req.FromCategoryID == req.ToCategoryID || !req.Amount.IsPositive() {
+		return model.BudgetMove{}, ErrInvalidBudgetMove
+	}
+
+	for _, categoryID := range []uint64{req.FromCategoryID, req.ToCategoryID} {
+		category, err := s.categoryRepo.GetByID(ctx, int(categoryID))
+		if err != nil {
+			return model.BudgetMove{}, ErrCategoryNotFound
+		}
+		if category.UserID != logined.ID {
+			return model.BudgetMove{}, ErrAccessDenied
+		}
+	}
+
+	return s.repo.MoveMoney(ctx, model.CreateBudgetMoveRecord{
+		UserID:         logined.ID,
+		Year:           year,
+		Month:          month,
+		FromCategoryID: req.FromCategoryID,
+		ToCategoryID:   req.ToCategoryID,
+		Amount:         req.Amount,
+		Note:           req.Note,
+		CreatedAt:      time.Now(),
+	})
+}
+
+func (s *BudgetService) GetMoves(ctx context.Context, logined model.User, year uint, month uint) ([]model.BudgetMove, error) {
+	if !isValidBudgetPeriod(year, month) {
+		return nil, ErrInvalidBudgetPeriod
+	}
+
+	return s.repo.GetMoves(ctx, logined.ID, year, month)
+}
+
+func isValidBudgetPeriod(year uint, month uint) bool {
+	return year >= 1970 && year <= 9999 && month >= 1 && month <= 12
 }
 
+// Update меняет назначенную сумму. Категория и месяц назначения не меняются,
+// их можно передать только с прежними значениями.
 func (s *BudgetService) Update(ctx context.Context, logined model.User, id int, dto model.UpdateBudgetAllocationRequest) error {
 	budget, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -40,7 +162,18 @@ func (s *BudgetService) Update(ctx context.Context, logined model.User, id int,
 	if budget.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
 		return ErrAccessDenied
 	}
-	return s.repo.Update(ctx, id, dto)
+
+	if dto.CategoryID != nil && *dto.CategoryID != budget.CategoryID ||
+		dto.Year != nil && *dto.Year != budget.Year ||
+		dto.Month != nil && *dto.Month != budget.Month {
+		return ErrInvalidBudgetUpdate
+	}
+
+	if dto.Assigned == nil {
+		return nil
+	}
+
+	return s.repo.Update(ctx, id, model.UpdateBudgetAllocationRequest{Assigned: dto.Assigned})
 }
 
 func (s *BudgetService) Delete(ctx context.Context, logined model.User, id int) error {
@@ -65,6 +198,32 @@ func (s *BudgetService) GetByID(ctx context.Context, logined model.User, id int)
 	return budget, nil
 }
 
+// GetList возвращает бюджет месяца в базовой валюте пользователя. Суммы в валютах
+// без курса не учитываются, такие валюты перечислены в MissingRates.
 func (s *BudgetService) GetList(ctx context.Context, logined model.User, year uint64, month uint64) (model.CategoryBudgetResponse, error) {
-	return s.repo.GetListDetailedByPeriod(ctx, logined.ID, year, month)
+	budget, err := s.repo.GetListDetailedByPeriod(ctx, logined.ID, year, month, logined.OverspendingMode)
+	if err != nil {
+		return budget, err
+	}
+	budget.Currency = logined.BaseCurrency
+
+	budget.MissingRates, err = s.rateRepo.GetMissingCurrencies(ctx, logined.ID)
+	if err != nil {
+		return budget, err
+	}
+
+	return budget, nil
+}
+
+// checkCategory проверяет, что категория назначения есть и принадлежит пользователю.
+func (s *BudgetService) checkCategory(ctx context.Context, logined model.User, categoryID uint64) error {
+	category, err := s.categoryRepo.GetByID(ctx, int(categoryID))
+	if err != nil {
+		return ErrCategoryNotFound
+	}
+	if category.UserID != logined.ID {
+		return ErrAccessDenied
+	}
+
+	return nil
 }
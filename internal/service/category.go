@@ -9,23 +9,41 @@ import (
 )
 
 var (
-	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryNotFound        = errors.New("category not found")
+	ErrInvalidCategoryTarget   = errors.New("invalid category target")
+	ErrCategoryInUse           = errors.New("category is in use, reassign its history to another category")
+	ErrInvalidCategoryReassign = errors.New("invalid target category")
+	ErrInvalidCategoryOrder    = errors.New("invalid category order")
+	ErrPaymentCategory         = errors.New("credit card payment category is managed by its account")
 )
 
 type CategoryService struct {
-	repo repository.CategoryRepository
+	repo      repository.CategoryRepository
+	groupRepo repository.CategoryGroupRepository
 }
 
-func NewCategoryService(repository repository.CategoryRepository) *CategoryService {
+func NewCategoryService(repository repository.CategoryRepository, groupRepo repository.CategoryGroupRepository) *CategoryService {
 	return &CategoryService{
-		repo: repository,
+		repo:      repository,
+		groupRepo: groupRepo,
 	}
 }
 
 func (s *CategoryService) Create(ctx context.Context, logined model.User, req model.CreateCategoryRequest) (int, error) {
+	if err := validateCategoryTarget(req.Target); err != nil {
+		return 0, err
+	}
+
+	if err := s.validateGroup(ctx, logined.ID, req.GroupID); err != nil {
+		return 0, err
+	}
+
 	category := model.CreateCategoryRecord{
 		UserID:    logined.ID,
 		Name:      req.Name,
+		GroupID:   req.GroupID,
+		IsHidden:  req.IsHidden,
+		Target:    req.Target,
 		UpdatedAt: time.Now(),
 		CreatedAt: time.Now(),
 	}
@@ -48,10 +66,33 @@ func (s *CategoryService) Update(ctx context.Context, logined model.User, id int
 		return ErrAccessDenied
 	}
 
+	if err := validateCategoryTarget(dto.Target); err != nil {
+		return err
+	}
+
+	groupID := dto.GroupID
+	if dto.RemoveGroup {
+		groupID = nil
+	}
+	if groupID != nil {
+		if err := s.validateGroup(ctx, category.UserID, groupID); err != nil {
+			return err
+		}
+
+		// Категория уже в этой группе — её место в порядке не меняется
+		if category.GroupID != nil && *category.GroupID == *groupID {
+			groupID = nil
+		}
+	}
+
 	err = s.repo.Update(ctx, id, model.UpdateCategoryRecord{
-		Name:      dto.Name,
-		GroupName: dto.GroupName,
-		UpdatedAt: time.Now(),
+		Name:         dto.Name,
+		GroupID:      groupID,
+		RemoveGroup:  dto.RemoveGroup && category.GroupID != nil,
+		IsHidden:     dto.IsHidden,
+		Target:       dto.Target,
+		RemoveTarget: dto.RemoveTarget,
+		UpdatedAt:    time.Now(),
 	})
 	if err != nil {
 		return err
@@ -60,8 +101,96 @@ func (s *CategoryService) Update(ctx context.Context, logined model.User, id int
 	return nil
 }
 
-func (s *CategoryService) Delete(ctx context.Context, logined model.User, id int) error {
+// Delete переносит категорию в корзину. Используемую категорию можно удалить
+// только с переносом истории в другую, иначе возвращается ErrCategoryInUse
+// вместе с количеством ссылок на неё.
+func (s *CategoryService) Delete(ctx context.Context, logined model.User, id int, req model.DeleteCategoryRequest) (model.CategoryUsage, error) {
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.CategoryUsage{}, ErrCategoryNotFound
+	}
+
+	if category.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.CategoryUsage{}, ErrAccessDenied
+	}
+
+	if category.PaymentAccountID != nil {
+		return model.CategoryUsage{}, ErrPaymentCategory
+	}
+
+	if req.ReassignTo != nil {
+		return s.reassign(ctx, category, *req.ReassignTo)
+	}
+
+	usage, err := s.repo.GetUsage(ctx, id)
+	if err != nil {
+		return model.CategoryUsage{}, err
+	}
+
+	if usage.IsUsed() {
+		return usage, ErrCategoryInUse
+	}
+
+	err = s.repo.Delete(ctx, id, time.Now())
+	if err != nil {
+		return model.CategoryUsage{}, err
+	}
+
+	return usage, nil
+}
+
+// Merge объединяет категорию с другой: транзакции, назначения по месяцам и
+// перемещения переходят в целевую категорию, а исходная уходит в корзину.
+func (s *CategoryService) Merge(ctx context.Context, logined model.User, id int, req model.MergeCategoryRequest) (model.CategoryUsage, error) {
+	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.CategoryUsage{}, ErrCategoryNotFound
+	}
+
+	if category.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.CategoryUsage{}, ErrAccessDenied
+	}
+
+	if category.PaymentAccountID != nil {
+		return model.CategoryUsage{}, ErrPaymentCategory
+	}
+
+	return s.reassign(ctx, category, req.IntoCategoryID)
+}
+
+func (s *CategoryService) GetUsage(ctx context.Context, logined model.User, id int) (model.CategoryUsage, error) {
 	category, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.CategoryUsage{}, ErrCategoryNotFound
+	}
+
+	if category.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.CategoryUsage{}, ErrAccessDenied
+	}
+
+	return s.repo.GetUsage(ctx, id)
+}
+
+func (s *CategoryService) reassign(ctx context.Context, category model.Category, targetID uint64) (model.CategoryUsage, error) {
+	if targetID == category.ID {
+		return model.CategoryUsage{}, ErrInvalidCategoryReassign
+	}
+
+	// Движения категории платежа считаются по карте, история туда не переносится
+	target, err := s.repo.GetByID(ctx, int(targetID))
+	if err != nil || target.UserID != category.UserID || target.PaymentAccountID != nil {
+		return model.CategoryUsage{}, ErrInvalidCategoryReassign
+	}
+
+	return s.repo.Reassign(ctx, model.ReassignCategoryRecord{
+		FromID:    category.ID,
+		ToID:      target.ID,
+		DeletedAt: time.Now(),
+	})
+}
+
+func (s *CategoryService) Restore(ctx context.Context, logined model.User, id int) error {
+	category, err := s.repo.GetDeletedByID(ctx, id)
 	if err != nil {
 		return ErrCategoryNotFound
 	}
@@ -70,7 +199,12 @@ func (s *CategoryService) Delete(ctx context.Context, logined model.User, id int
 		return ErrAccessDenied
 	}
 
-	err = s.repo.Delete(ctx, id)
+	// Категория платежа восстанавливается вместе со счётом карты
+	if category.PaymentAccountID != nil {
+		return ErrPaymentCategory
+	}
+
+	err = s.repo.Restore(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -99,3 +233,65 @@ func (s *CategoryService) GetList(ctx context.Context, logined model.User) ([]mo
 
 	return categories, nil
 }
+
+func validateCategoryTarget(target *model.CategoryTarget) error {
+	if target == nil {
+		return nil
+	}
+
+	if !target.Type.IsValid() || !target.Amount.IsPositive() {
+		return ErrInvalidCategoryTarget
+	}
+
+	if target.Type == model.CategoryTargetBalanceByDate && target.Date == nil {
+		return ErrInvalidCategoryTarget
+	}
+
+	if target.Type != model.CategoryTargetBalanceByDate {
+		target.Date = nil
+	}
+
+	return nil
+}
+
+// Reorder расставляет категории группы в порядке перетаскивания. Категории из
+// других групп переносятся в указанную группу.
+func (s *CategoryService) Reorder(ctx context.Context, logined model.User, req model.ReorderCategoriesRequest) error {
+	if err := s.validateGroup(ctx, logined.ID, req.GroupID); err != nil {
+		return err
+	}
+
+	categories, err := s.repo.GetList(ctx, logined.ID)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[uint64]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+
+	if !isValidOrder(req.CategoryIDs, known) {
+		return ErrInvalidCategoryOrder
+	}
+
+	return s.repo.Reorder(ctx, model.ReorderCategoriesRecord{
+		GroupID:     req.GroupID,
+		CategoryIDs: req.CategoryIDs,
+		UpdatedAt:   time.Now(),
+	})
+}
+
+// validateGroup проверяет, что группа существует и принадлежит владельцу категории.
+func (s *CategoryService) validateGroup(ctx context.Context, userID uint64, groupID *uint64) error {
+	if groupID == nil {
+		return nil
+	}
+
+	group, err := s.groupRepo.GetByID(ctx, *groupID)
+	if err != nil || group.UserID != userID {
+		return ErrCategoryGroupNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCategoryGroupNotFound     = errors.New("category group not found")
+	ErrInvalidCategoryGroupName  = errors.New("category group name is required")
+	ErrInvalidCategoryGroupOrder = errors.New("invalid category group order")
+)
+
+type CategoryGroupService struct {
+	repo repository.CategoryGroupRepository
+}
+
+func NewCategoryGroupService(repository repository.CategoryGroupRepository) *CategoryGroupService {
+	return &CategoryGroupService{repo: repository}
+}
+
+func (s *CategoryGroupService) Create(ctx context.Context, logined model.User, req model.CreateCategoryGroupRequest) (uint64, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return 0, ErrInvalidCategoryGroupName
+	}
+
+	createdID, err := s.repo.Create(ctx, model.CreateCategoryGroupRecord{
+		UserID:    logined.ID,
+		Name:      req.Name,
+		IsHidden:  req.IsHidden,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return createdID, nil
+}
+
+// Update переименовывает или скрывает группу. Категории ссылаются на группу по ID,
+// поэтому новое имя сразу видно у всех её категорий.
+func (s *CategoryGroupService) Update(ctx context.Context, logined model.User, id uint64, dto model.UpdateCategoryGroupRequest) error {
+	group, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return ErrCategoryGroupNotFound
+	}
+
+	if group.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return ErrAccessDenied
+	}
+
+	if dto.Name != nil && strings.TrimSpace(*dto.Name) == "" {
+		return ErrInvalidCategoryGroupName
+	}
+
+	err = s.repo.Update(ctx, id, model.UpdateCategoryGroupRecord{
+		Name:      dto.Name,
+		IsHidden:  dto.IsHidden,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete удаляет группу. Её категории не удаляются, а остаются без группы.
+func (s *CategoryGroupService) Delete(ctx context.Context, logined model.User, id uint64) error {
+	group, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return ErrCategoryGroupNotFound
+	}
+
+	if group.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return ErrAccessDenied
+	}
+
+	err = s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *CategoryGroupService) GetList(ctx context.Context, logined model.User) ([]model.CategoryGroup, error) {
+	groups, err := s.repo.GetList(ctx, logined.ID)
+	if err != nil {
+		return []model.CategoryGroup{}, err
+	}
+
+	return groups, nil
+}
+
+func (s *CategoryGroupService) Reorder(ctx context.Context, logined model.User, req model.ReorderCategoryGroupsRequest) error {
+	groups, err := s.repo.GetList(ctx, logined.ID)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[uint64]bool, len(groups))
+	for _, group := range groups {
+		known[group.ID] = true
+	}
+
+	if !isValidOrder(req.GroupIDs, known) {
+		return ErrInvalidCategoryGroupOrder
+	}
+
+	return s.repo.Reorder(ctx, model.ReorderCategoryGroupsRecord{
+		GroupIDs:  req.GroupIDs,
+		UpdatedAt: time.Now(),
+	})
+}
+
+// isValidOrder проверяет, что порядок не пуст, не содержит повторов и чужих записей.
+func isValidOrder(ids []uint64, known map[uint64]bool) bool {
+	if len(ids) == 0 {
+		return false
+	}
+
+	seen := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		if !known[id] || seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+
+	return true
+}
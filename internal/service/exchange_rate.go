@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"litespend-api/internal/model"
+	"litespend-api/internal/pkg/spreadsheet"
+	"litespend-api/internal/repository"
+)
+
+var (
+	ErrInvalidCurrency     = errors.New("invalid currency")
+	ErrInvalidExchangeRate = errors.New("invalid exchange rate")
+)
+
+// exchangeRateColumns — обязательные столбцы CSV с курсами, ищутся по заголовку.
+var exchangeRateColumns = []string{"date", "from", "to", "rate"}
+
+type ExchangeRateService struct {
+	repo repository.ExchangeRateRepository
+}
+
+func NewExchangeRateService(repository repository.ExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{repo: repository}
+}
+
+func (s *ExchangeRateService) Create(ctx context.Context, logined model.User, req model.CreateExchangeRateRequest) (model.ExchangeRate, error) {
+	req.FromCurrency = normalizeCurrency(req.FromCurrency)
+	req.ToCurrency = normalizeCurrency(req.ToCurrency)
+	if !req.IsValid() {
+		return model.ExchangeRate{}, ErrInvalidExchangeRate
+	}
+
+	return s.repo.Create(ctx, model.CreateExchangeRateRecord{
+		UserID:       logined.ID,
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+		Rate:         req.Rate,
+		Date:         req.Date,
+		CreatedAt:    time.Now(),
+	})
+}
+
+func (s *ExchangeRateService) GetList(ctx context.Context, logined model.User, currency *model.Currency) ([]model.ExchangeRate, error) {
+	if currency != nil {
+		normalized := normalizeCurrency(*currency)
+		if !normalized.IsValid() {
+			return []model.ExchangeRate{}, ErrInvalidCurrency
+		}
+		currency = &normalized
+	}
+
+	rates, err := s.repo.GetList(ctx, logined.ID, currency)
+	if err != nil {
+		return []model.ExchangeRate{}, err
+	}
+
+	return rates, nil
+}
+
+// Import загружает курсы из CSV или XLSX со столбцами date, from, to и rate.
+// Ошибочные строки пропускаются и перечисляются в результате, как при импорте
+// транзакций.
+func (s *ExchangeRateService) Import(ctx context.Context, logined model.User, fileData []byte) (model.ExchangeRateImportResult, error) {
+	result := model.ExchangeRateImportResult{}
+
+	rows, err := spreadsheet.ReadRows(fileData)
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrImportInvalidFile, err)
+	}
+
+	index := make(map[string]int, len(rows[0]))
+	for i, column := range rows[0] {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range exchangeRateColumns {
+		if _, ok := index[column]; !ok {
+			return result, fmt.Errorf("%w: column %q not found", ErrImportInvalidFile, column)
+		}
+	}
+
+	for i, row := range rows[1:] {
+		// Номер строки в файле с учётом заголовка
+		rowNum := i + 2
+
+		if spreadsheet.IsEmptyRow(row) {
+			continue
+		}
+
+		cell := func(column string) string {
+			if index[column] >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index[column]])
+		}
+
+		date, err := spreadsheet.ParseDate(cell("date"))
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", rowNum, err))
+			continue
+		}
+
+		rate, err := spreadsheet.ParseAmount(cell("rate"))
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", rowNum, err))
+			continue
+		}
+
+		_, err = s.Create(ctx, logined, model.CreateExchangeRateRequest{
+			FromCurrency: model.Currency(cell("from")),
+			ToCurrency:   model.Currency(cell("to")),
+			Rate:         rate,
+			Date:         date,
+		})
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", rowNum, err))
+			continue
+		}
+
+		result.RatesImported++
+	}
+
+	return result, nil
+}
+
+func normalizeCurrency(currency model.Currency) model.Currency {
+	return model.Currency(strings.ToUpper(strings.TrimSpace(string(currency))))
+}
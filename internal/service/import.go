@@ -1 +1,332 @@
 package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"litespend-api/internal/model"
+	"litespend-api/internal/pkg/spreadsheet"
+	"litespend-api/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrImportInvalidFile    = errors.New("invalid import file")
+	ErrImportInvalidMapping = errors.New("invalid column mapping")
+)
+
+type ImportService struct {
+	transactionRepo repository.TransactionRepository
+	categoryRepo    repository.CategoryRepository
+	accountRepo     repository.AccountRepository
+	payeeRepo       repository.PayeeRepository
+	rules           ruleRunner
+}
+
+func NewImportService(
+	transactionRepo repository.TransactionRepository,
+	categoryRepo repository.CategoryRepository,
+	accountRepo repository.AccountRepository,
+	payeeRepo repository.PayeeRepository,
+	ruleRepo repository.RuleRepository,
+) *ImportService {
+	return &ImportService{
+		transactionRepo: transactionRepo,
+		categoryRepo:    categoryRepo,
+		accountRepo:     accountRepo,
+		payeeRepo:       payeeRepo,
+		rules:           newRuleRunner(ruleRepo, transactionRepo, accountRepo),
+	}
+}
+
+func (s *ImportService) ParseExcelFile(fileData []byte) (model.ExcelFileStructure, error) {
+	rows, err := spreadsheet.ReadRows(fileData)
+	if err != nil {
+		return model.ExcelFileStructure{}, fmt.Errorf("%w: %v", ErrImportInvalidFile, err)
+	}
+
+	columns := make([]string, 0, len(rows[0]))
+	for _, column := range rows[0] {
+		columns = append(columns, strings.TrimSpace(column))
+	}
+
+	return model.ExcelFileStructure{
+		Columns: columns,
+		Rows:    len(rows) - 1,
+	}, nil
+}
+
+func (s *ImportService) ImportData(ctx context.Context, logined model.User, fileData []byte, req model.ImportRequest) (model.ImportResult, error) {
+	result := model.ImportResult{}
+
+	account, err := s.importAccount(ctx, logined, req.AccountID)
+	if err != nil {
+		return result, err
+	}
+
+	rows, err := spreadsheet.ReadRows(fileData)
+	if err != nil {
+		return result, fmt.Errorf("%w: %v", ErrImportInvalidFile, err)
+	}
+
+	columns, err := resolveImportColumns(rows[0], req.Mapping)
+	if err != nil {
+		return result, err
+	}
+
+	categories, err := s.categoryRepo.GetList(ctx, account.UserID)
+	if err != nil {
+		return result, err
+	}
+
+	categoryIDs := make(map[string]uint64, len(categories))
+	for _, category := range categories {
+		categoryIDs[normalizeCategoryName(category.Name)] = category.ID
+	}
+
+	// Получатели по тексту из файла, чтобы не искать одно имя для каждой строки
+	payees := make(map[string]model.Payee)
+
+	rules, err := s.rules.load(ctx, account.UserID)
+	if err != nil {
+		return result, err
+	}
+
+	for i, row := range rows[1:] {
+		// Номер строки в файле с учётом заголовка
+		rowNum := i + 2
+
+		if spreadsheet.IsEmptyRow(row) {
+			continue
+		}
+
+		parsed, err := parseImportRow(row, columns)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: %v", rowNum, err))
+			continue
+		}
+
+		var categoryID *uint64
+		if parsed.category != "" {
+			key := normalizeCategoryName(parsed.category)
+			id, ok := categoryIDs[key]
+			if !ok {
+				createdID, err := s.categoryRepo.Create(ctx, model.CreateCategoryRecord{
+					UserID:    account.UserID,
+					Name:      parsed.category,
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				})
+				if err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("row %d: failed to create category %q: %v", rowNum, parsed.category, err))
+					continue
+				}
+
+				id = uint64(createdID)
+				categoryIDs[key] = id
+				result.CategoriesCreated++
+			}
+			categoryID = &id
+		}
+
+		var payee *model.Payee
+		if parsed.payee != "" {
+			key := strings.ToLower(parsed.payee)
+			resolved, ok := payees[key]
+			if !ok {
+				resolved, err = s.payeeRepo.Resolve(ctx, account.UserID, parsed.payee, time.Now())
+				if err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("row %d: failed to resolve payee %q: %v", rowNum, parsed.payee, err))
+					continue
+				}
+
+				payees[key] = resolved
+			}
+			payee = &resolved
+		}
+
+		record := model.CreateTransactionRecord{
+			UserID:     account.UserID,
+			AccountID:  account.ID,
+			CategoryID: categoryID,
+			Amount:     parsed.amount,
+			Note:       parsed.note,
+			Date:       parsed.date,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}
+
+		var payeeName string
+		if payee != nil {
+			record.PayeeID = &payee.ID
+			payeeName = payee.Name
+		}
+
+		_, applied, err := s.rules.create(ctx, rules, record, payeeName)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("row %d: failed to create transaction: %v", rowNum, err))
+			continue
+		}
+
+		result.TransactionsCreated++
+		if applied {
+			result.RulesApplied++
+		}
+	}
+
+	return result, nil
+}
+
+// importAccount возвращает счёт для импорта: указанный или, если счёт не указан,
+// первый бюджетный счёт пользователя.
+func (s *ImportService) importAccount(ctx context.Context, logined model.User, accountID *uint64) (model.Account, error) {
+	if accountID == nil {
+		accounts, err := s.accountRepo.GetList(ctx, logined.ID)
+		if err != nil {
+			return model.Account{}, err
+		}
+
+		for _, account := range accounts {
+			if account.OnBudget {
+				return account, nil
+			}
+		}
+
+		return model.Account{}, ErrAccountNotFound
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, *accountID)
+	if err != nil {
+		return model.Account{}, ErrAccountNotFound
+	}
+
+	if account.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.Account{}, ErrAccessDenied
+	}
+
+	return account, nil
+}
+
+// importColumns хранит индексы столбцов файла, -1 — столбец не сопоставлен.
+type importColumns struct {
+	amount      int
+	description int
+	date        int
+	category    int
+	kind        int
+	payee       int
+}
+
+type importRow struct {
+	amount   decimal.Decimal
+	note     string
+	date     time.Time
+	category string
+	payee    string
+}
+
+func resolveImportColumns(header []string, mapping model.ExcelColumnMapping) (importColumns, error) {
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		name := strings.ToLower(strings.TrimSpace(column))
+		if _, exists := index[name]; !exists {
+			index[name] = i
+		}
+	}
+
+	lookup := func(column *string) (int, error) {
+		if column == nil || strings.TrimSpace(*column) == "" {
+			return -1, nil
+		}
+
+		i, ok := index[strings.ToLower(strings.TrimSpace(*column))]
+		if !ok {
+			return -1, fmt.Errorf("%w: column %q not found", ErrImportInvalidMapping, *column)
+		}
+
+		return i, nil
+	}
+
+	if strings.TrimSpace(mapping.TransactionAmount) == "" {
+		return importColumns{}, fmt.Errorf("%w: amount column is required", ErrImportInvalidMapping)
+	}
+
+	var (
+		columns importColumns
+		err     error
+	)
+
+	if columns.amount, err = lookup(&mapping.TransactionAmount); err != nil {
+		return columns, err
+	}
+	if columns.description, err = lookup(mapping.TransactionDescription); err != nil {
+		return columns, err
+	}
+	if columns.date, err = lookup(mapping.TransactionDate); err != nil {
+		return columns, err
+	}
+	if columns.date == -1 {
+		return columns, fmt.Errorf("%w: date column is required", ErrImportInvalidMapping)
+	}
+	if columns.category, err = lookup(mapping.TransactionCategory); err != nil {
+		return columns, err
+	}
+	if columns.category == -1 {
+		if columns.category, err = lookup(mapping.CategoryName); err != nil {
+			return columns, err
+		}
+	}
+	if columns.kind, err = lookup(mapping.TransactionType); err != nil {
+		return columns, err
+	}
+	if columns.payee, err = lookup(mapping.TransactionPayee); err != nil {
+		return columns, err
+	}
+
+	return columns, nil
+}
+
+func parseImportRow(row []string, columns importColumns) (importRow, error) {
+	cell := func(i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var result importRow
+
+	amount, err := spreadsheet.ParseAmount(cell(columns.amount))
+	if err != nil {
+		return result, err
+	}
+
+	if kind := strings.ToLower(cell(columns.kind)); kind != "" {
+		switch kind {
+		case "expense", "расход", "списание", "-":
+			amount = amount.Abs().Neg()
+		case "income", "доход", "пополнение", "зачисление", "+":
+			amount = amount.Abs()
+		default:
+			return result, fmt.Errorf("unknown transaction type %q", kind)
+		}
+	}
+	result.amount = amount
+
+	if result.date, err = spreadsheet.ParseDate(cell(columns.date)); err != nil {
+		return result, err
+	}
+
+	result.note = cell(columns.description)
+	result.category = cell(columns.category)
+	result.payee = model.NormalizePayeeName(cell(columns.payee))
+
+	return result, nil
+}
+
+func normalizeCategoryName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
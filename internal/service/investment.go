@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository"
+)
+
+var (
+	ErrNotInvestmentAccount      = errors.New("account is not an investment account")
+	ErrInvalidInvestmentActivity = errors.New("invalid investment activity")
+	ErrInsufficientHolding       = errors.New("sell quantity exceeds holding")
+	ErrInvalidSecurityPrice      = errors.New("invalid security price")
+	ErrOnBudgetInvestment        = errors.New("investment accounts are tracked off budget")
+)
+
+var investmentActivityNotes = map[model.InvestmentActivityKind]string{
+	model.InvestmentActivityBuy:      "Покупка %s",
+	model.InvestmentActivitySell:     "Продажа %s",
+	model.InvestmentActivityDividend: "Дивиденды %s",
+}
+
+type InvestmentService struct {
+	repo        repository.InvestmentRepository
+	accountRepo repository.AccountRepository
+}
+
+func NewInvestmentService(repository repository.InvestmentRepository, accountRepo repository.AccountRepository) *InvestmentService {
+	return &InvestmentService{
+		repo:        repository,
+		accountRepo: accountRepo,
+	}
+}
+
+// RecordActivity проводит покупку, продажу или дивиденд по счёту. Деньги за сделку
+// списываются и зачисляются на сам инвестиционный счёт.
+func (s *InvestmentService) RecordActivity(ctx context.Context, logined model.User, accountID uint64, req model.CreateInvestmentActivityRequest) (model.InvestmentActivity, error) {
+	account, err := s.getAccount(ctx, logined, accountID)
+	if err != nil {
+		return model.InvestmentActivity{}, err
+	}
+
+	ticker := normalizeTicker(req.Ticker)
+	if ticker == "" || !req.IsValid() {
+		return model.InvestmentActivity{}, ErrInvalidInvestmentActivity
+	}
+
+	note := req.Note
+	if note == "" {
+		note = fmt.Sprintf(investmentActivityNotes[req.Kind], ticker)
+	}
+
+	result, err := s.repo.RecordActivity(ctx, model.CreateInvestmentActivityRecord{
+		UserID:    account.UserID,
+		AccountID: accountID,
+		Ticker:    ticker,
+		Kind:      req.Kind,
+		Quantity:  req.Quantity,
+		Price:     req.Price,
+		Fee:       req.Fee,
+		Amount:    req.Amount,
+		Date:      req.Date,
+		Note:      note,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return model.InvestmentActivity{}, err
+	}
+
+	if result.Activity == nil {
+		return model.InvestmentActivity{}, ErrInsufficientHolding
+	}
+
+	return *result.Activity, nil
+}
+
+func (s *InvestmentService) GetActivities(ctx context.Context, logined model.User, accountID uint64) ([]model.InvestmentActivity, error) {
+	if _, err := s.getAccount(ctx, logined, accountID); err != nil {
+		return []model.InvestmentActivity{}, err
+	}
+
+	activities, err := s.repo.GetActivities(ctx, accountID)
+	if err != nil {
+		return []model.InvestmentActivity{}, err
+	}
+
+	return activities, nil
+}
+
+// GetSummary оценивает счёт по последним введённым ценам бумаг.
+func (s *InvestmentService) GetSummary(ctx context.Context, logined model.User, accountID uint64) (model.InvestmentSummary, error) {
+	account, err := s.getAccount(ctx, logined, accountID)
+	if err != nil {
+		return model.InvestmentSummary{}, err
+	}
+
+	holdings, err := s.repo.GetHoldings(ctx, accountID)
+	if err != nil {
+		return model.InvestmentSummary{}, err
+	}
+
+	income, err := s.repo.GetIncome(ctx, accountID)
+	if err != nil {
+		return model.InvestmentSummary{}, err
+	}
+
+	return model.NewInvestmentSummary(accountID, account.Balance, holdings, income), nil
+}
+
+// CreatePrice сохраняет цену бумаги на дату. Цены общие для всех счетов пользователя.
+func (s *InvestmentService) CreatePrice(ctx context.Context, logined model.User, req model.CreateSecurityPriceRequest) (model.SecurityPrice, error) {
+	ticker := normalizeTicker(req.Ticker)
+	if ticker == "" || !req.Price.IsPositive() {
+		return model.SecurityPrice{}, ErrInvalidSecurityPrice
+	}
+
+	return s.repo.CreatePrice(ctx, model.CreateSecurityPriceRecord{
+		UserID:    logined.ID,
+		Ticker:    ticker,
+		Price:     req.Price,
+		Date:      req.Date,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *InvestmentService) GetPrices(ctx context.Context, logined model.User, ticker *string) ([]model.SecurityPrice, error) {
+	if ticker != nil {
+		normalized := normalizeTicker(*ticker)
+		ticker = &normalized
+	}
+
+	prices, err := s.repo.GetPrices(ctx, logined.ID, ticker)
+	if err != nil {
+		return []model.SecurityPrice{}, err
+	}
+
+	return prices, nil
+}
+
+func (s *InvestmentService) getAccount(ctx context.Context, logined model.User, accountID uint64) (model.Account, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return model.Account{}, ErrAccountNotFound
+	}
+
+	if account.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.Account{}, ErrAccessDenied
+	}
+
+	if account.Type != model.AccountTypeInvestment {
+		return model.Account{}, ErrNotInvestmentAccount
+	}
+
+	return account, nil
+}
+
+func normalizeTicker(ticker string) string {
+	return strings.ToUpper(strings.TrimSpace(ticker))
+}
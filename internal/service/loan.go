@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrLoanNotFound       = errors.New("loan not found")
+	ErrInvalidLoanTerms   = errors.New("invalid loan terms")
+	ErrInvalidLoanPayment = errors.New("invalid loan payment")
+)
+
+const loanInterestNote = "Проценты по кредиту"
+
+type LoanService struct {
+	repo        repository.LoanRepository
+	accountRepo repository.AccountRepository
+}
+
+func NewLoanService(repository repository.LoanRepository, accountRepo repository.AccountRepository) *LoanService {
+	return &LoanService{
+		repo:        repository,
+		accountRepo: accountRepo,
+	}
+}
+
+func (s *LoanService) Get(ctx context.Context, logined model.User, accountID uint64) (model.LoanDetails, error) {
+	account, loan, err := s.getLoan(ctx, logined, accountID)
+	if err != nil {
+		return model.LoanDetails{}, err
+	}
+
+	return model.LoanDetails{
+		Loan:           loan,
+		Debt:           account.Balance.Neg(),
+		MonthlyPayment: loan.MonthlyPayment(),
+	}, nil
+}
+
+func (s *LoanService) Update(ctx context.Context, logined model.User, accountID uint64, dto model.UpdateLoanRequest) error {
+	if _, _, err := s.getLoan(ctx, logined, accountID); err != nil {
+		return err
+	}
+
+	if (dto.InterestRate != nil && dto.InterestRate.IsNegative()) ||
+		(dto.TermMonths != nil && *dto.TermMonths <= 0) ||
+		(dto.PaymentDay != nil && (*dto.PaymentDay < 1 || *dto.PaymentDay > 31)) {
+		return ErrInvalidLoanTerms
+	}
+
+	return s.repo.Update(ctx, accountID, model.UpdateLoanRecord{
+		InterestRate: dto.InterestRate,
+		TermMonths:   dto.TermMonths,
+		PaymentDay:   dto.PaymentDay,
+		UpdatedAt:    time.Now(),
+	})
+}
+
+// GetSchedule возвращает исходный график платежей по условиям кредита.
+func (s *LoanService) GetSchedule(ctx context.Context, logined model.User, accountID uint64) (model.LoanSchedule, error) {
+	_, loan, err := s.getLoan(ctx, logined, accountID)
+	if err != nil {
+		return model.LoanSchedule{}, err
+	}
+
+	return loan.Schedule(), nil
+}
+
+// GetProjection прогнозирует погашение от сегодняшнего остатка долга, в том числе
+// с ежемесячным досрочным платежом extra.
+func (s *LoanService) GetProjection(ctx context.Context, logined model.User, accountID uint64, extra decimal.Decimal) (model.LoanProjection, error) {
+	if extra.IsNegative() {
+		return model.LoanProjection{}, ErrInvalidLoanPayment
+	}
+
+	_, loan, err := s.getLoan(ctx, logined, accountID)
+	if err != nil {
+		return model.LoanProjection{}, err
+	}
+
+	now := time.Now()
+	debt, err := s.repo.GetDebt(ctx, accountID, now)
+	if err != nil {
+		return model.LoanProjection{}, err
+	}
+
+	return model.NewLoanProjection(loan, debt, now, extra), nil
+}
+
+// RecordPayment проводит платёж по кредиту с другого счёта пользователя и делит
+// его на проценты и погашение основного долга.
+func (s *LoanService) RecordPayment(ctx context.Context, logined model.User, accountID uint64, req model.LoanPaymentRequest) (model.LoanPayment, error) {
+	account, loan, err := s.getLoan(ctx, logined, accountID)
+	if err != nil {
+		return model.LoanPayment{}, err
+	}
+
+	if !req.Amount.IsPositive() || req.FromAccountID == accountID {
+		return model.LoanPayment{}, ErrInvalidLoanPayment
+	}
+
+	from, err := s.accountRepo.GetByID(ctx, req.FromAccountID)
+	if err != nil {
+		return model.LoanPayment{}, ErrAccountNotFound
+	}
+
+	if from.UserID != account.UserID {
+		return model.LoanPayment{}, ErrAccessDenied
+	}
+
+	// Платёж делится на проценты и долг в валюте кредита, конвертация здесь не поддерживается
+	if from.Currency != account.Currency {
+		return model.LoanPayment{}, ErrInvalidLoanPayment
+	}
+
+	// Как и у перевода, категорию получает только бюджетная половина платежа
+	if req.CategoryID != nil && from.OnBudget == account.OnBudget {
+		return model.LoanPayment{}, ErrInvalidLoanPayment
+	}
+
+	return s.repo.RecordPayment(ctx, model.LoanPaymentRecord{
+		UserID:        account.UserID,
+		Loan:          loan,
+		FromAccountID: req.FromAccountID,
+		Amount:        req.Amount,
+		Date:          req.Date,
+		Note:          req.Note,
+		InterestNote:  loanInterestNote,
+		CategoryID:    req.CategoryID,
+		IsCleared:     req.IsCleared,
+		CreatedAt:     time.Now(),
+	})
+}
+
+func (s *LoanService) GetPayments(ctx context.Context, logined model.User, accountID uint64) ([]model.LoanPayment, error) {
+	if _, _, err := s.getLoan(ctx, logined, accountID); err != nil {
+		return []model.LoanPayment{}, err
+	}
+
+	payments, err := s.repo.GetPayments(ctx, accountID)
+	if err != nil {
+		return []model.LoanPayment{}, err
+	}
+
+	return payments, nil
+}
+
+func (s *LoanService) getLoan(ctx context.Context, logined model.User, accountID uint64) (model.Account, model.Loan, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return model.Account{}, model.Loan{}, ErrAccountNotFound
+	}
+
+	if account.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.Account{}, model.Loan{}, ErrAccessDenied
+	}
+
+	loan, err := s.repo.GetByAccountID(ctx, accountID)
+	if err != nil {
+		return model.Account{}, model.Loan{}, ErrLoanNotFound
+	}
+
+	return account, loan, nil
+}
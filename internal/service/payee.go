@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository"
+)
+
+var (
+	ErrPayeeNotFound           = errors.New("payee not found")
+	ErrPayeeExists             = errors.New("payee with this name already exists")
+	ErrInvalidPayee            = errors.New("invalid payee")
+	ErrInvalidPayeeMerge       = errors.New("payee cannot be merged into itself or another user's payee")
+	ErrPayeeRenameRuleNotFound = errors.New("payee rename rule not found")
+)
+
+const (
+	defaultPayeeAutocompleteLimit = 10
+	maxPayeeAutocompleteLimit     = 50
+)
+
+type PayeeService struct {
+	repo repository.PayeeRepository
+}
+
+func NewPayeeService(repository repository.PayeeRepository) *PayeeService {
+	return &PayeeService{repo: repository}
+}
+
+func (s *PayeeService) Create(ctx context.Context, logined model.User, req model.CreatePayeeRequest) (model.Payee, error) {
+	name := model.NormalizePayeeName(req.Name)
+	if name == "" {
+		return model.Payee{}, ErrInvalidPayee
+	}
+
+	if _, err := s.repo.GetByName(ctx, logined.ID, name); err == nil {
+		return model.Payee{}, ErrPayeeExists
+	}
+
+	return s.repo.Create(ctx, model.CreatePayeeRecord{
+		UserID:    logined.ID,
+		Name:      name,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *PayeeService) GetList(ctx context.Context, logined model.User, search *string) ([]model.Payee, error) {
+	payees, err := s.repo.GetList(ctx, logined.ID, search)
+	if err != nil {
+		return []model.Payee{}, err
+	}
+
+	return payees, nil
+}
+
+// Autocomplete подбирает получателей по началу или части имени вместе с
+// категорией, которую стоит предложить для новой транзакции.
+func (s *PayeeService) Autocomplete(ctx context.Context, logined model.User, term string, limit int) ([]model.PayeeSuggestion, error) {
+	term = model.NormalizePayeeName(term)
+	if term == "" {
+		return []model.PayeeSuggestion{}, nil
+	}
+
+	if limit <= 0 {
+		limit = defaultPayeeAutocompleteLimit
+	}
+	if limit > maxPayeeAutocompleteLimit {
+		limit = maxPayeeAutocompleteLimit
+	}
+
+	suggestions, err := s.repo.Autocomplete(ctx, logined.ID, term, limit)
+	if err != nil {
+		return []model.PayeeSuggestion{}, err
+	}
+
+	return suggestions, nil
+}
+
+func (s *PayeeService) Get(ctx context.Context, logined model.User, id uint64) (model.PayeeDetails, error) {
+	if _, err := s.getPayee(ctx, logined, id); err != nil {
+		return model.PayeeDetails{}, err
+	}
+
+	return s.repo.GetDetails(ctx, id)
+}
+
+func (s *PayeeService) Update(ctx context.Context, logined model.User, id uint64, req model.UpdatePayeeRequest) error {
+	payee, err := s.getPayee(ctx, logined, id)
+	if err != nil {
+		return err
+	}
+
+	name := model.NormalizePayeeName(req.Name)
+	if name == "" {
+		return ErrInvalidPayee
+	}
+
+	if existing, err := s.repo.GetByName(ctx, payee.UserID, name); err == nil && existing.ID != id {
+		return ErrPayeeExists
+	}
+
+	return s.repo.Update(ctx, id, model.UpdatePayeeRecord{
+		Name:      name,
+		UpdatedAt: time.Now(),
+	})
+}
+
+// Delete удаляет получателя, транзакции остаются без получателя.
+func (s *PayeeService) Delete(ctx context.Context, logined model.User, id uint64) error {
+	if _, err := s.getPayee(ctx, logined, id); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// Merge сливает дубликат id с получателем IntoPayeeID.
+func (s *PayeeService) Merge(ctx context.Context, logined model.User, id uint64, req model.MergePayeeRequest) (model.MergePayeeResult, error) {
+	from, err := s.getPayee(ctx, logined, id)
+	if err != nil {
+		return model.MergePayeeResult{}, err
+	}
+
+	if req.IntoPayeeID == id {
+		return model.MergePayeeResult{}, ErrInvalidPayeeMerge
+	}
+
+	into, err := s.repo.GetByID(ctx, req.IntoPayeeID)
+	if err != nil {
+		return model.MergePayeeResult{}, ErrPayeeNotFound
+	}
+
+	if into.UserID != from.UserID {
+		return model.MergePayeeResult{}, ErrInvalidPayeeMerge
+	}
+
+	return s.repo.Merge(ctx, model.MergePayeeRecord{
+		FromID:    id,
+		ToID:      into.ID,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *PayeeService) CreateRenameRule(ctx context.Context, logined model.User, id uint64, req model.CreatePayeeRenameRuleRequest) (model.PayeeRenameRule, error) {
+	payee, err := s.getPayee(ctx, logined, id)
+	if err != nil {
+		return model.PayeeRenameRule{}, err
+	}
+
+	pattern := model.NormalizePayeeName(req.Pattern)
+	if pattern == "" {
+		return model.PayeeRenameRule{}, ErrInvalidPayee
+	}
+
+	return s.repo.CreateRenameRule(ctx, model.CreatePayeeRenameRuleRecord{
+		UserID:    payee.UserID,
+		PayeeID:   id,
+		Pattern:   pattern,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *PayeeService) DeleteRenameRule(ctx context.Context, logined model.User, id uint64, ruleID uint64) error {
+	if _, err := s.getPayee(ctx, logined, id); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeleteRenameRule(ctx, id, ruleID)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return ErrPayeeRenameRuleNotFound
+	}
+
+	return nil
+}
+
+func (s *PayeeService) getPayee(ctx context.Context, logined model.User, id uint64) (model.Payee, error) {
+	payee, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.Payee{}, ErrPayeeNotFound
+	}
+
+	if payee.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.Payee{}, ErrAccessDenied
+	}
+
+	return payee, nil
+}
+
+// resolvePayee возвращает получателя транзакции: по id с проверкой владельца или
+// по тексту через правила переименования. Без id и текста получателя нет.
+func resolvePayee(ctx context.Context, repo repository.PayeeRepository, userID uint64, payeeID *uint64, payeeName *string) (*model.Payee, error) {
+	if payeeID != nil && payeeName != nil {
+		return nil, ErrInvalidPayee
+	}
+
+	if payeeID != nil {
+		payee, err := repo.GetByID(ctx, *payeeID)
+		if err != nil {
+			return nil, ErrPayeeNotFound
+		}
+		if payee.UserID != userID {
+			return nil, ErrAccessDenied
+		}
+		return &payee, nil
+	}
+
+	if payeeName == nil {
+		return nil, nil
+	}
+
+	name := model.NormalizePayeeName(*payeeName)
+	if name == "" {
+		return nil, nil
+	}
+
+	payee, err := repo.Resolve(ctx, userID, name, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &payee, nil
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrPrescribedExpanseNotFound = errors.New("prescribed expanse not found")
+	ErrInvalidPrescribedExpanse  = errors.New("invalid prescribed expanse")
+	ErrInvalidPayment            = errors.New("payment amount must be positive and not exceed the unpaid amount")
+	ErrNothingToPay              = errors.New("no unpaid occurrence found")
+)
+
+type PrescribedExpanseService struct {
+	repo            repository.PrescribedExpanseRepository
+	transactionRepo repository.TransactionRepository
+	accountRepo     repository.AccountRepository
+	categoryRepo    repository.CategoryRepository
+}
+
+func NewPrescribedExpanseService(
+	repo repository.PrescribedExpanseRepository,
+	transactionRepo repository.TransactionRepository,
+	accountRepo repository.AccountRepository,
+	categoryRepo repository.CategoryRepository,
+) *PrescribedExpanseService {
+	return &PrescribedExpanseService{
+		repo:            repo,
+		transactionRepo: transactionRepo,
+		accountRepo:     accountRepo,
+		categoryRepo:    categoryRepo,
+	}
+}
+
+func (s *PrescribedExpanseService) Create(ctx context.Context, logined model.User, req model.CreatePrescribedExpanseRequest) (int, error) {
+	if req.Interval == 0 {
+		req.Interval = 1
+	}
+
+	if !req.Frequency.IsValid() || req.Interval < 1 || !req.Amount.IsPositive() || req.Date.IsZero() {
+		return 0, ErrInvalidPrescribedExpanse
+	}
+
+	if req.EndDate != nil && req.EndDate.Before(req.Date) {
+		return 0, ErrInvalidPrescribedExpanse
+	}
+
+	if req.AccountID != nil {
+		if err := s.checkAccount(ctx, logined, *req.AccountID); err != nil {
+			return 0, err
+		}
+	}
+
+	if req.CategoryID != nil {
+		if err := s.checkCategory(ctx, logined.ID, *req.CategoryID); err != nil {
+			return 0, err
+		}
+	}
+
+	id, err := s.repo.Create(ctx, model.CreatePrescribedExpanseRecord{
+		UserID:      logined.ID,
+		AccountID:   req.AccountID,
+		CategoryID:  req.CategoryID,
+		Description: req.Description,
+		Frequency:   req.Frequency,
+		Interval:    req.Interval,
+		Amount:      req.Amount,
+		Date:        req.Date,
+		EndDate:     req.EndDate,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *PrescribedExpanseService) Update(ctx context.Context, logined model.User, id int, dto model.UpdatePrescribedExpanseRequest) error {
+	prescribedExpanse, err := s.GetByID(ctx, logined, id)
+	if err != nil {
+		return err
+	}
+
+	if dto.Frequency != nil && !dto.Frequency.IsValid() {
+		return ErrInvalidPrescribedExpanse
+	}
+
+	if dto.Interval != nil && *dto.Interval < 1 {
+		return ErrInvalidPrescribedExpanse
+	}
+
+	if dto.Amount != nil && !dto.Amount.IsPositive() {
+		return ErrInvalidPrescribedExpanse
+	}
+
+	start := prescribedExpanse.Date
+	if dto.Date != nil {
+		start = *dto.Date
+	}
+	if dto.EndDate != nil && dto.EndDate.Before(start) {
+		return ErrInvalidPrescribedExpanse
+	}
+
+	if dto.AccountID != nil {
+		if err := s.checkAccount(ctx, logined, *dto.AccountID); err != nil {
+			return err
+		}
+	}
+
+	if dto.CategoryID != nil {
+		if err := s.checkCategory(ctx, prescribedExpanse.UserID, *dto.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	err = s.repo.Update(ctx, id, model.UpdatePrescribedExpanseRecord{
+		AccountID:   dto.AccountID,
+		CategoryID:  dto.CategoryID,
+		Description: dto.Description,
+		Frequency:   dto.Frequency,
+		Interval:    dto.Interval,
+		Amount:      dto.Amount,
+		Date:        dto.Date,
+		EndDate:     dto.EndDate,
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *PrescribedExpanseService) Delete(ctx context.Context, logined model.User, id int) error {
+	_, err := s.GetByID(ctx, logined, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *PrescribedExpanseService) GetByID(ctx context.Context, logined model.User, id int) (model.PrescribedExpanse, error) {
+	prescribedExpanse, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return prescribedExpanse, ErrPrescribedExpanseNotFound
+	}
+
+	if prescribedExpanse.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return prescribedExpanse, ErrAccessDenied
+	}
+
+	return prescribedExpanse, nil
+}
+
+func (s *PrescribedExpanseService) GetList(ctx context.Context, logined model.User) ([]model.PrescribedExpanse, error) {
+	prescribedExpanses, err := s.repo.GetList(ctx, logined.ID)
+	if err != nil {
+		return prescribedExpanses, err
+	}
+
+	return prescribedExpanses, nil
+}
+
+// GetListWithPaymentStatus раскладывает расписания на повторения месяца и отмечает оплаченные.
+func (s *PrescribedExpanseService) GetListWithPaymentStatus(ctx context.Context, logined model.User, year uint, month uint) ([]model.PrescribedExpanseWithPaymentStatus, error) {
+	if !isValidBudgetPeriod(year, month) {
+		return nil, ErrInvalidBudgetPeriod
+	}
+
+	from := time.Date(int(year), time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+
+	prescribedExpanses, err := s.repo.GetList(ctx, logined.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	payments, err := s.getPayments(ctx, logined.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.PrescribedExpanseWithPaymentStatus, 0, len(prescribedExpanses))
+	for _, prescribedExpanse := range prescribedExpanses {
+		for _, dueDate := range prescribedExpanse.Occurrences(from, to) {
+			result = append(result, withPaymentStatus(prescribedExpanse, dueDate, payments))
+		}
+	}
+
+	return result, nil
+}
+
+// MarkAsPaid создаёт расходную транзакцию в счёт повторения расписания.
+func (s *PrescribedExpanseService) MarkAsPaid(ctx context.Context, logined model.User, id int, req model.PayPrescribedExpanseRequest) (int, error) {
+	prescribedExpanse, err := s.GetByID(ctx, logined, id)
+	if err != nil {
+		return 0, err
+	}
+
+	occurrence, err := s.findOccurrence(ctx, prescribedExpanse, req.DueDate)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := prescribedExpanse.Remaining(occurrence.PaidAmount)
+
+	amount := remaining
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+
+	if !amount.IsPositive() || amount.GreaterThan(remaining) {
+		return 0, ErrInvalidPayment
+	}
+
+	accountID, err := s.resolveAccount(ctx, logined, prescribedExpanse, req.AccountID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	return s.transactionRepo.Create(ctx, model.CreateTransactionRecord{
+		UserID:              prescribedExpanse.UserID,
+		AccountID:           accountID,
+		CategoryID:          prescribedExpanse.CategoryID,
+		Amount:              amount.Neg(),
+		Note:                prescribedExpanse.Description,
+		Date:                now,
+		IsApproved:          true,
+		PrescribedExpanseID: &prescribedExpanse.ID,
+		ScheduledDate:       &occurrence.DueDate,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	})
+}
+
+// findOccurrence возвращает повторение с указанной датой, а без неё — ближайшее
+// неоплаченное начиная с текущего месяца.
+func (s *PrescribedExpanseService) findOccurrence(ctx context.Context, prescribedExpanse model.PrescribedExpanse, dueDate *time.Time) (model.PrescribedExpanseWithPaymentStatus, error) {
+	var from, to time.Time
+	if dueDate != nil {
+		from, to = *dueDate, *dueDate
+	} else {
+		now := time.Now()
+		from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		// Год вперёд, чтобы найти следующее повторение даже у ежегодных трат
+		to = from.AddDate(1, 1, -1)
+	}
+
+	occurrences := prescribedExpanse.Occurrences(from, to)
+	if len(occurrences) == 0 {
+		if dueDate != nil {
+			return model.PrescribedExpanseWithPaymentStatus{}, ErrInvalidPrescribedExpanse
+		}
+		return model.PrescribedExpanseWithPaymentStatus{}, ErrNothingToPay
+	}
+
+	payments, err := s.getPayments(ctx, prescribedExpanse.UserID, occurrences[0], occurrences[len(occurrences)-1])
+	if err != nil {
+		return model.PrescribedExpanseWithPaymentStatus{}, err
+	}
+
+	for _, date := range occurrences {
+		occurrence := withPaymentStatus(prescribedExpanse, date, payments)
+		if !occurrence.IsPaid {
+			return occurrence, nil
+		}
+	}
+
+	return model.PrescribedExpanseWithPaymentStatus{}, ErrNothingToPay
+}
+
+// resolveAccount выбирает счёт оплаты: явно переданный, счёт расписания
+// или первый неархивный бюджетный счёт пользователя.
+func (s *PrescribedExpanseService) resolveAccount(ctx context.Context, logined model.User, prescribedExpanse model.PrescribedExpanse, accountID *uint64) (uint64, error) {
+	if accountID == nil {
+		accountID = prescribedExpanse.AccountID
+	}
+
+	if accountID != nil {
+		if err := s.checkAccount(ctx, logined, *accountID); err != nil {
+			return 0, err
+		}
+		return *accountID, nil
+	}
+
+	accounts, err := s.accountRepo.GetList(ctx, prescribedExpanse.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, account := range accounts {
+		if !account.IsArchived && account.OnBudget {
+			return account.ID, nil
+		}
+	}
+
+	return 0, ErrAccountNotFound
+}
+
+// checkAccount проверяет владельца счёта и то, что счёт бюджетный: оплата
+// расписания — транзакция с категорией.
+func (s *PrescribedExpanseService) checkAccount(ctx context.Context, logined model.User, accountID uint64) error {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return ErrAccountNotFound
+	}
+
+	if account.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return ErrAccessDenied
+	}
+
+	if !account.OnBudget {
+		return ErrOffBudgetCategory
+	}
+
+	return nil
+}
+
+// checkCategory проверяет, что категория есть, принадлежит владельцу расписания и
+// не является категорией платежа по карте: её движения считаются по самой карте.
+func (s *PrescribedExpanseService) checkCategory(ctx context.Context, userID uint64, categoryID uint64) error {
+	category, err := s.categoryRepo.GetByID(ctx, int(categoryID))
+	if err != nil {
+		return ErrCategoryNotFound
+	}
+
+	if category.UserID != userID {
+		return ErrAccessDenied
+	}
+
+	if category.PaymentAccountID != nil {
+		return ErrPaymentCategory
+	}
+
+	return nil
+}
+
+type paymentKey struct {
+	prescribedExpanseID uint64
+	dueDate             time.Time
+}
+
+func (s *PrescribedExpanseService) getPayments(ctx context.Context, userID uint64, from time.Time, to time.Time) (map[paymentKey]model.PrescribedExpansePayment, error) {
+	payments, err := s.repo.GetPayments(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[paymentKey]model.PrescribedExpansePayment, len(payments))
+	for _, payment := range payments {
+		date := payment.ScheduledDate
+		byKey[paymentKey{payment.PrescribedExpanseID, time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)}] = payment
+	}
+
+	return byKey, nil
+}
+
+func withPaymentStatus(prescribedExpanse model.PrescribedExpanse, dueDate time.Time, payments map[paymentKey]model.PrescribedExpansePayment) model.PrescribedExpanseWithPaymentStatus {
+	result := model.PrescribedExpanseWithPaymentStatus{
+		PrescribedExpanse: prescribedExpanse,
+		DueDate:           dueDate,
+		PaidAmount:        decimal.Zero,
+	}
+
+	if payment, ok := payments[paymentKey{prescribedExpanse.ID, dueDate}]; ok {
+		result.PaidAmount = payment.Amount
+		result.TransactionID = &payment.TransactionID
+	}
+	result.IsPaid = prescribedExpanse.Remaining(result.PaidAmount).IsZero()
+
+	return result
+}
+
+// PostDue проводит наступившие повторения всех расписаний как неподтверждённые транзакции.
+func (s *PrescribedExpanseService) PostDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	return s.repo.PostDue(ctx, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository"
+)
+
+var (
+	ErrRuleNotFound   = errors.New("rule not found")
+	ErrInvalidRule    = errors.New("invalid rule")
+	ErrInvalidRuleRun = errors.New("invalid rule run")
+)
+
+type RuleService struct {
+	repo         repository.RuleRepository
+	accountRepo  repository.AccountRepository
+	categoryRepo repository.CategoryRepository
+	payeeRepo    repository.PayeeRepository
+	runner       ruleRunner
+}
+
+func NewRuleService(
+	repository repository.RuleRepository,
+	transactionRepo repository.TransactionRepository,
+	accountRepo repository.AccountRepository,
+	categoryRepo repository.CategoryRepository,
+	payeeRepo repository.PayeeRepository,
+) *RuleService {
+	return &RuleService{
+		repo:         repository,
+		accountRepo:  accountRepo,
+		categoryRepo: categoryRepo,
+		payeeRepo:    payeeRepo,
+		runner:       newRuleRunner(repository, transactionRepo, accountRepo),
+	}
+}
+
+func (s *RuleService) Create(ctx context.Context, logined model.User, req model.CreateTransactionRuleRequest) (model.TransactionRule, error) {
+	record, err := s.buildRecord(ctx, logined.ID, req)
+	if err != nil {
+		return model.TransactionRule{}, err
+	}
+
+	record.CreatedAt = time.Now()
+	record.UpdatedAt = time.Now()
+
+	return s.repo.Create(ctx, record)
+}
+
+func (s *RuleService) GetList(ctx context.Context, logined model.User) ([]model.TransactionRule, error) {
+	rules, err := s.repo.GetList(ctx, logined.ID)
+	if err != nil {
+		return []model.TransactionRule{}, err
+	}
+
+	return rules, nil
+}
+
+// Update заменяет условия и действия правила целиком.
+func (s *RuleService) Update(ctx context.Context, logined model.User, id uint64, req model.CreateTransactionRuleRequest) error {
+	rule, err := s.getRule(ctx, logined, id)
+	if err != nil {
+		return err
+	}
+
+	record, err := s.buildRecord(ctx, rule.UserID, req)
+	if err != nil {
+		return err
+	}
+
+	record.UpdatedAt = time.Now()
+
+	return s.repo.Update(ctx, id, record)
+}
+
+func (s *RuleService) Delete(ctx context.Context, logined model.User, id uint64) error {
+	if _, err := s.getRule(ctx, logined, id); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// Apply прогоняет правила по уже внесённым транзакциям. При dryRun ничего не
+// меняется, а результат показывает, что изменилось бы. Одно выбранное правило
+// проверяется, даже если оно выключено, чтобы его можно было опробовать заранее.
+func (s *RuleService) Apply(ctx context.Context, logined model.User, req model.ApplyRulesRequest, dryRun bool) (model.ApplyRulesResult, error) {
+	result := model.ApplyRulesResult{DryRun: dryRun, Changes: make([]model.RuleChange, 0)}
+
+	if req.From != nil && req.To != nil && req.From.After(*req.To) {
+		return result, ErrInvalidRuleRun
+	}
+
+	var rules []model.TransactionRule
+	if req.RuleID != nil {
+		rule, err := s.getRule(ctx, logined, *req.RuleID)
+		if err != nil {
+			return result, err
+		}
+		rule.IsActive = true
+		rules = []model.TransactionRule{rule}
+	} else {
+		var err error
+		if rules, err = s.repo.GetList(ctx, logined.ID); err != nil {
+			return result, err
+		}
+	}
+
+	set := model.NewRuleSet(rules)
+	if set.IsEmpty() {
+		return result, nil
+	}
+
+	accounts, err := s.accountRepo.GetList(ctx, logined.ID)
+	if err != nil {
+		return result, err
+	}
+
+	accountsByID := make(map[uint64]model.Account, len(accounts))
+	for _, account := range accounts {
+		accountsByID[account.ID] = account
+	}
+
+	candidates, err := s.repo.GetCandidates(ctx, logined.ID, req)
+	if err != nil {
+		return result, err
+	}
+
+	result.Checked = len(candidates)
+	for _, candidate := range candidates {
+		outcome := set.Evaluate(candidate.Subject())
+		if outcome.IsEmpty() {
+			continue
+		}
+
+		account, ok := accountsByID[candidate.AccountID]
+		if !ok {
+			continue
+		}
+
+		var target *model.Account
+		if outcome.TransferAccountID != nil {
+			if found, ok := accountsByID[*outcome.TransferAccountID]; ok {
+				target = &found
+			}
+		}
+
+		change, changed := candidate.Change(fitRuleOutcome(outcome, account, target), req.Overwrite)
+		if !changed {
+			continue
+		}
+
+		if !dryRun {
+			if err := s.runner.applyChange(ctx, account.UserID, candidate, change); err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("transaction %d: %v", candidate.ID, err))
+				continue
+			}
+		}
+
+		result.Changes = append(result.Changes, change)
+	}
+
+	return result, nil
+}
+
+func (s *RuleService) getRule(ctx context.Context, logined model.User, id uint64) (model.TransactionRule, error) {
+	rule, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return model.TransactionRule{}, ErrRuleNotFound
+	}
+
+	if rule.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return model.TransactionRule{}, ErrAccessDenied
+	}
+
+	return rule, nil
+}
+
+// buildRecord проверяет правило и то, что счета, категория и получатель из него
+// принадлежат владельцу правила.
+func (s *RuleService) buildRecord(ctx context.Context, userID uint64, req model.CreateTransactionRuleRequest) (model.TransactionRuleRecord, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || !req.IsValid() {
+		return model.TransactionRuleRecord{}, ErrInvalidRule
+	}
+
+	if req.AccountID != nil && req.TransferAccountID != nil && *req.AccountID == *req.TransferAccountID {
+		return model.TransactionRuleRecord{}, ErrInvalidRule
+	}
+
+	for _, accountID := range []*uint64{req.AccountID, req.TransferAccountID} {
+		if accountID == nil {
+			continue
+		}
+		account, err := s.accountRepo.GetByID(ctx, *accountID)
+		if err != nil {
+			return model.TransactionRuleRecord{}, ErrAccountNotFound
+		}
+		if account.UserID != userID {
+			return model.TransactionRuleRecord{}, ErrAccessDenied
+		}
+	}
+
+	if req.SetCategoryID != nil {
+		category, err := s.categoryRepo.GetByID(ctx, int(*req.SetCategoryID))
+		if err != nil {
+			return model.TransactionRuleRecord{}, ErrCategoryNotFound
+		}
+		if category.UserID != userID {
+			return model.TransactionRuleRecord{}, ErrAccessDenied
+		}
+	}
+
+	if req.SetPayeeID != nil {
+		payee, err := s.payeeRepo.GetByID(ctx, *req.SetPayeeID)
+		if err != nil {
+			return model.TransactionRuleRecord{}, ErrPayeeNotFound
+		}
+		if payee.UserID != userID {
+			return model.TransactionRuleRecord{}, ErrAccessDenied
+		}
+	}
+
+	matchType := req.MatchType
+	if matchType == "" {
+		matchType = model.RuleMatchContains
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	weekdays, _ := model.NewWeekdays(req.Weekdays)
+
+	return model.TransactionRuleRecord{
+		UserID:            userID,
+		Name:              req.Name,
+		SortOrder:         req.SortOrder,
+		IsActive:          isActive,
+		MatchType:         matchType,
+		NotePattern:       req.NotePattern,
+		PayeePattern:      req.PayeePattern,
+		MinAmount:         req.MinAmount,
+		MaxAmount:         req.MaxAmount,
+		AccountID:         req.AccountID,
+		Weekdays:          weekdays,
+		SetCategoryID:     req.SetCategoryID,
+		SetPayeeID:        req.SetPayeeID,
+		SetNote:           req.SetNote,
+		SetApproved:       req.SetApproved,
+		TransferAccountID: req.TransferAccountID,
+	}, nil
+}
+
+// ruleRunner применяет правила к новым транзакциям при вводе и импорте и к
+// изменениям, найденным при прогоне по истории.
+type ruleRunner struct {
+	ruleRepo        repository.RuleRepository
+	transactionRepo repository.TransactionRepository
+	accountRepo     repository.AccountRepository
+}
+
+func newRuleRunner(ruleRepo repository.RuleRepository, transactionRepo repository.TransactionRepository, accountRepo repository.AccountRepository) ruleRunner {
+	return ruleRunner{
+		ruleRepo:        ruleRepo,
+		transactionRepo: transactionRepo,
+		accountRepo:     accountRepo,
+	}
+}
+
+func (r ruleRunner) load(ctx context.Context, userID uint64) (model.RuleSet, error) {
+	rules, err := r.ruleRepo.GetList(ctx, userID)
+	if err != nil {
+		return model.RuleSet{}, err
+	}
+
+	return model.NewRuleSet(rules), nil
+}
+
+// create сохраняет новую транзакцию с действиями подошедших правил; payee — имя
+// получателя для условий. Сообщает, подошло ли какое-нибудь правило. Правило-перевод
+// создаёт перевод, если категория или разбивка не заданы явно.
+func (r ruleRunner) create(ctx context.Context, set model.RuleSet, record model.CreateTransactionRecord, payee string) (int, bool, error) {
+	outcome := set.Evaluate(model.RuleSubject{
+		AccountID: record.AccountID,
+		Amount:    record.Amount,
+		Date:      record.Date,
+		Note:      record.Note,
+		Payee:     payee,
+	})
+	if outcome.IsEmpty() {
+		id, err := r.transactionRepo.Create(ctx, record)
+		return id, false, err
+	}
+
+	account, err := r.accountRepo.GetByID(ctx, record.AccountID)
+	if err != nil {
+		return 0, false, ErrAccountNotFound
+	}
+
+	var target *model.Account
+	if outcome.TransferAccountID != nil && record.CategoryID == nil && len(record.Splits) == 0 {
+		if found, err := r.accountRepo.GetByID(ctx, *outcome.TransferAccountID); err == nil {
+			target = &found
+		}
+	}
+
+	outcome = fitRuleOutcome(outcome, account, target)
+	outcome.ApplyToRecord(&record)
+
+	if outcome.TransferAccountID == nil {
+		id, err := r.transactionRepo.Create(ctx, record)
+		return id, true, err
+	}
+
+	// У переводов нет получателя
+	record.PayeeID = nil
+	pair := record
+	pair.AccountID = *outcome.TransferAccountID
+	pair.Amount = record.Amount.Neg()
+
+	id, _, err := r.transactionRepo.CreateTransfer(ctx, record, pair)
+	return id, true, err
+}
+
+// applyChange сохраняет изменение, найденное прогоном правил по истории.
+func (r ruleRunner) applyChange(ctx context.Context, userID uint64, candidate model.RuleCandidate, change model.RuleChange) error {
+	now := time.Now()
+	record := change.UpdateRecord(now)
+
+	if change.After.TransferAccountID == nil {
+		return r.transactionRepo.Update(ctx, int(candidate.ID), record)
+	}
+
+	_, err := r.transactionRepo.ConvertToTransfer(ctx, int(candidate.ID), record, model.CreateTransactionRecord{
+		UserID:     userID,
+		AccountID:  *change.After.TransferAccountID,
+		Amount:     candidate.Amount.Neg(),
+		Date:       candidate.Date,
+		Note:       change.After.Note,
+		IsApproved: change.After.IsApproved,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	})
+	return err
+}
+
+// fitRuleOutcome убирает действия, которые нельзя выполнить на счёте транзакции:
+// категорию на внебюджетном счёте и перевод на недоступный счёт, тот же счёт или
+// счёт в другой валюте. target — счёт перевода, nil, если он не найден.
+func fitRuleOutcome(outcome model.RuleOutcome, account model.Account, target *model.Account) model.RuleOutcome {
+	if !account.OnBudget {
+		outcome.CategoryID = nil
+	}
+
+	if outcome.TransferAccountID != nil {
+		if target == nil || target.UserID != account.UserID || target.ID == account.ID || target.Currency != account.Currency {
+			outcome.TransferAccountID = nil
+		}
+	}
+
+	return outcome
+}
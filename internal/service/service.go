@@ -5,50 +5,123 @@ import (
 	"litespend-api/internal/model"
 	"litespend-api/internal/repository"
 	"litespend-api/internal/session"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type Service struct {
 	User
 	Transaction
 	Category
+	CategoryGroup
 	Budget
 	Auth
 	Import
 	Account
+	ExchangeRate
+	Investment
+	Loan
+	Payee
+	PrescribedExpanse
+	Rule
+	Statistics
+	Sync
+	Trash
 }
 
 type Account interface {
 	Create(ctx context.Context, logined model.User, account model.CreateAccountRequest) (uint64, error)
 	Update(ctx context.Context, logined model.User, id uint64, dto model.UpdateAccountRequest) error
 	GetList(ctx context.Context, logined model.User) ([]model.Account, error)
+	Delete(ctx context.Context, logined model.User, id uint64, req model.DeleteAccountRequest) error
+	Restore(ctx context.Context, logined model.User, id uint64) error
+	AdjustBalance(ctx context.Context, logined model.User, id uint64, req model.AdjustAccountBalanceRequest) (model.AccountBalanceAdjustment, error)
+	GetReconciliation(ctx context.Context, logined model.User, id uint64, date time.Time, statementBalance decimal.Decimal) (model.AccountReconciliation, error)
+	Reconcile(ctx context.Context, logined model.User, id uint64, req model.ReconciliationRequest) (model.ReconciliationResult, error)
+}
+
+type ExchangeRate interface {
+	Create(ctx context.Context, logined model.User, req model.CreateExchangeRateRequest) (model.ExchangeRate, error)
+	GetList(ctx context.Context, logined model.User, currency *model.Currency) ([]model.ExchangeRate, error)
+	Import(ctx context.Context, logined model.User, fileData []byte) (model.ExchangeRateImportResult, error)
+}
+
+type Investment interface {
+	RecordActivity(ctx context.Context, logined model.User, accountID uint64, req model.CreateInvestmentActivityRequest) (model.InvestmentActivity, error)
+	GetActivities(ctx context.Context, logined model.User, accountID uint64) ([]model.InvestmentActivity, error)
+	GetSummary(ctx context.Context, logined model.User, accountID uint64) (model.InvestmentSummary, error)
+	CreatePrice(ctx context.Context, logined model.User, req model.CreateSecurityPriceRequest) (model.SecurityPrice, error)
+	GetPrices(ctx context.Context, logined model.User, ticker *string) ([]model.SecurityPrice, error)
+}
+
+type Loan interface {
+	Get(ctx context.Context, logined model.User, accountID uint64) (model.LoanDetails, error)
+	Update(ctx context.Context, logined model.User, accountID uint64, dto model.UpdateLoanRequest) error
+	GetSchedule(ctx context.Context, logined model.User, accountID uint64) (model.LoanSchedule, error)
+	GetProjection(ctx context.Context, logined model.User, accountID uint64, extra decimal.Decimal) (model.LoanProjection, error)
+	RecordPayment(ctx context.Context, logined model.User, accountID uint64, req model.LoanPaymentRequest) (model.LoanPayment, error)
+	GetPayments(ctx context.Context, logined model.User, accountID uint64) ([]model.LoanPayment, error)
+}
+
+type Payee interface {
+	Create(ctx context.Context, logined model.User, req model.CreatePayeeRequest) (model.Payee, error)
+	GetList(ctx context.Context, logined model.User, search *string) ([]model.Payee, error)
+	Autocomplete(ctx context.Context, logined model.User, term string, limit int) ([]model.PayeeSuggestion, error)
+	Get(ctx context.Context, logined model.User, id uint64) (model.PayeeDetails, error)
+	Update(ctx context.Context, logined model.User, id uint64, req model.UpdatePayeeRequest) error
 	Delete(ctx context.Context, logined model.User, id uint64) error
+	Merge(ctx context.Context, logined model.User, id uint64, req model.MergePayeeRequest) (model.MergePayeeResult, error)
+	CreateRenameRule(ctx context.Context, logined model.User, id uint64, req model.CreatePayeeRenameRuleRequest) (model.PayeeRenameRule, error)
+	DeleteRenameRule(ctx context.Context, logined model.User, id uint64, ruleID uint64) error
 }
 
 type User interface {
 	Register(ctx context.Context, user model.RegisterRequest) error
 	Login(ctx context.Context, req model.LoginRequest) (model.User, error)
 	Logout(ctx context.Context) error
+	GetSettings(ctx context.Context, logined model.User) (model.UserSettings, error)
+	UpdateSettings(ctx context.Context, logined model.User, req model.UpdateUserSettingsRequest) (model.UserSettings, error)
 }
 
 type Transaction interface {
 	Create(ctx context.Context, logined model.User, transaction model.CreateTransactionRequest) (int, error)
+	CreateTransfer(ctx context.Context, logined model.User, req model.CreateTransferRequest) (model.TransferResult, error)
 	Update(ctx context.Context, logined model.User, id int, dto model.UpdateTransactionRequest) error
 	Delete(ctx context.Context, logined model.User, id int) error
+	Restore(ctx context.Context, logined model.User, id int) error
 	GetByID(ctx context.Context, logined model.User, id int) (model.Transaction, error)
 	GetList(ctx context.Context, logined model.User) ([]model.Transaction, error)
-	GetListPaginated(ctx context.Context, logined model.User, accountID *uint64, params model.PaginationParams) (model.PaginatedTransactionsResponse, error)
+	GetListPaginated(ctx context.Context, logined model.User, filter model.TransactionFilter, params model.PaginationParams) (model.PaginatedTransactionsResponse, error)
+	GetListByCursor(ctx context.Context, logined model.User, filter model.TransactionFilter, params model.PaginationParams) (model.CursorTransactionsResponse, error)
 }
 
 type Category interface {
 	Create(ctx context.Context, logined model.User, req model.CreateCategoryRequest) (int, error)
 	Update(ctx context.Context, logined model.User, id int, dto model.UpdateCategoryRequest) error
-	Delete(ctx context.Context, logined model.User, id int) error
+	Delete(ctx context.Context, logined model.User, id int, req model.DeleteCategoryRequest) (model.CategoryUsage, error)
+	Merge(ctx context.Context, logined model.User, id int, req model.MergeCategoryRequest) (model.CategoryUsage, error)
+	Restore(ctx context.Context, logined model.User, id int) error
 	GetByID(ctx context.Context, logined model.User, id int) (model.Category, error)
 	GetList(ctx context.Context, logined model.User) ([]model.Category, error)
+	GetUsage(ctx context.Context, logined model.User, id int) (model.CategoryUsage, error)
+	Reorder(ctx context.Context, logined model.User, req model.ReorderCategoriesRequest) error
+}
+
+type CategoryGroup interface {
+	Create(ctx context.Context, logined model.User, req model.CreateCategoryGroupRequest) (uint64, error)
+	Update(ctx context.Context, logined model.User, id uint64, dto model.UpdateCategoryGroupRequest) error
+	Delete(ctx context.Context, logined model.User, id uint64) error
+	GetList(ctx context.Context, logined model.User) ([]model.CategoryGroup, error)
+	Reorder(ctx context.Context, logined model.User, req model.ReorderCategoryGroupsRequest) error
 }
 
 type Budget interface {
 	Create(ctx context.Context, logined model.User, req model.CreateBudgetAllocationRequest) (int, error)
+	Assign(ctx context.Context, logined model.User, year uint, month uint, req model.AssignBudgetRequest) (model.BudgetAllocation, error)
+	QuickAssign(ctx context.Context, logined model.User, year uint, month uint, req model.QuickAssignRequest) (model.QuickAssignResult, error)
+	MoveMoney(ctx context.Context, logined model.User, year uint, month uint, req model.MoveBudgetRequest) (model.BudgetMove, error)
+	GetMoves(ctx context.Context, logined model.User, year uint, month uint) ([]model.BudgetMove, error)
 	Update(ctx context.Context, logined model.User, id int, dto model.UpdateBudgetAllocationRequest) error
 	Delete(ctx context.Context, logined model.User, id int) error
 	GetByID(ctx context.Context, logined model.User, id int) (model.BudgetAllocation, error)
@@ -62,16 +135,64 @@ type Auth interface {
 
 type Import interface {
 	ParseExcelFile(fileData []byte) (model.ExcelFileStructure, error)
-	ImportData(ctx context.Context, logined model.User, fileData []byte, mapping model.ExcelColumnMapping) (model.ImportResult, error)
+	ImportData(ctx context.Context, logined model.User, fileData []byte, req model.ImportRequest) (model.ImportResult, error)
+}
+
+type PrescribedExpanse interface {
+	Create(ctx context.Context, logined model.User, req model.CreatePrescribedExpanseRequest) (int, error)
+	Update(ctx context.Context, logined model.User, id int, dto model.UpdatePrescribedExpanseRequest) error
+	Delete(ctx context.Context, logined model.User, id int) error
+	GetByID(ctx context.Context, logined model.User, id int) (model.PrescribedExpanse, error)
+	GetList(ctx context.Context, logined model.User) ([]model.PrescribedExpanse, error)
+	GetListWithPaymentStatus(ctx context.Context, logined model.User, year uint, month uint) ([]model.PrescribedExpanseWithPaymentStatus, error)
+	MarkAsPaid(ctx context.Context, logined model.User, id int, req model.PayPrescribedExpanseRequest) (int, error)
+	PostDue(ctx context.Context) (int, error)
+}
+
+type Rule interface {
+	Create(ctx context.Context, logined model.User, req model.CreateTransactionRuleRequest) (model.TransactionRule, error)
+	GetList(ctx context.Context, logined model.User) ([]model.TransactionRule, error)
+	Update(ctx context.Context, logined model.User, id uint64, req model.CreateTransactionRuleRequest) error
+	Delete(ctx context.Context, logined model.User, id uint64) error
+	Apply(ctx context.Context, logined model.User, req model.ApplyRulesRequest, dryRun bool) (model.ApplyRulesResult, error)
+}
+
+type Statistics interface {
+	GetCurrentBalance(ctx context.Context, logined model.User, year uint, month uint) (model.CurrentBalanceStatistics, error)
+	GetPeriodStatistics(ctx context.Context, logined model.User, req model.PeriodStatisticsRequest) (model.PeriodStatisticsResponse, error)
+	GetCategoryStatistics(ctx context.Context, logined model.User, req model.CategoryStatisticsRequest) (model.CategoryStatisticsResponse, error)
+	GetPayeeStatistics(ctx context.Context, logined model.User, req model.PayeeStatisticsRequest) (model.PayeeStatisticsResponse, error)
+	GetNetWorth(ctx context.Context, logined model.User) (model.NetWorth, error)
+}
+
+type Sync interface {
+	Pull(ctx context.Context, logined model.User, req model.SyncPullRequest) (model.SyncPullResponse, error)
+	Push(ctx context.Context, logined model.User, req model.SyncPushRequest) (model.SyncPushResponse, error)
+}
+
+type Trash interface {
+	GetList(ctx context.Context, logined model.User) (model.Trash, error)
+	Purge(ctx context.Context, retention time.Duration) (model.PurgeResult, error)
 }
 
 func NewService(repository *repository.Repository, sessionManager *session.SessionManager) *Service {
 	return &Service{
-		User:        NewUserService(repository.UserRepository),
-		Transaction: NewTransactionService(repository.TransactionRepository),
-		Category:    NewCategoryService(repository.CategoryRepository),
-		Budget:      NewBudgetService(repository.BudgetRepository),
-		Auth:        NewAuthService(sessionManager, repository.UserRepository),
-		Account:     NewAccountService(repository.AccountRepository),
+		User:              NewUserService(repository.UserRepository),
+		Transaction:       NewTransactionService(repository.TransactionRepository, repository.AccountRepository, repository.PayeeRepository, repository.RuleRepository),
+		Category:          NewCategoryService(repository.CategoryRepository, repository.CategoryGroupRepository),
+		CategoryGroup:     NewCategoryGroupService(repository.CategoryGroupRepository),
+		Budget:            NewBudgetService(repository.BudgetRepository, repository.CategoryRepository, repository.ExchangeRateRepository),
+		Auth:              NewAuthService(sessionManager, repository.UserRepository),
+		Import:            NewImportService(repository.TransactionRepository, repository.CategoryRepository, repository.AccountRepository, repository.PayeeRepository, repository.RuleRepository),
+		Account:           NewAccountService(repository.AccountRepository),
+		ExchangeRate:      NewExchangeRateService(repository.ExchangeRateRepository),
+		Investment:        NewInvestmentService(repository.InvestmentRepository, repository.AccountRepository),
+		Loan:              NewLoanService(repository.LoanRepository, repository.AccountRepository),
+		Payee:             NewPayeeService(repository.PayeeRepository),
+		PrescribedExpanse: NewPrescribedExpanseService(repository.PrescribedExpanseRepository, repository.TransactionRepository, repository.AccountRepository, repository.CategoryRepository),
+		Rule:              NewRuleService(repository.RuleRepository, repository.TransactionRepository, repository.AccountRepository, repository.CategoryRepository, repository.PayeeRepository),
+		Statistics:        NewStatisticsService(repository.StatisticsRepository, repository.BudgetRepository, repository.ExchangeRateRepository),
+		Sync:              NewSyncService(repository.SyncRepository),
+		Trash:             NewTrashService(repository.TrashRepository),
 	}
 }
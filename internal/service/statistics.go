@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInvalidStatisticsFilter = errors.New("invalid statistics filter")
+)
+
+// StatisticsService строит отчёты в базовой валюте. Суммы в валютах без курса в
+// отчёты не входят, такие валюты перечислены в MissingRates ответа.
+type StatisticsService struct {
+	repo       repository.StatisticsRepository
+	budgetRepo repository.BudgetRepository
+	rateRepo   repository.ExchangeRateRepository
+}
+
+func NewStatisticsService(repo repository.StatisticsRepository, budgetRepo repository.BudgetRepository, rateRepo repository.ExchangeRateRepository) *StatisticsService {
+	return &StatisticsService{
+		repo:       repo,
+		budgetRepo: budgetRepo,
+		rateRepo:   rateRepo,
+	}
+}
+
+// GetCurrentBalance возвращает доходы и расходы месяца, сумму, разложенную по
+// категориям, и остаток, который ещё можно распределить.
+func (s *StatisticsService) GetCurrentBalance(ctx context.Context, logined model.User, year uint, month uint) (model.CurrentBalanceStatistics, error) {
+	if !isValidBudgetPeriod(year, month) {
+		return model.CurrentBalanceStatistics{}, ErrInvalidBudgetPeriod
+	}
+
+	from := time.Date(int(year), time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+
+	statistics, err := s.repo.GetTotals(ctx, logined.ID, from, to)
+	if err != nil {
+		return statistics, err
+	}
+
+	budget, err := s.budgetRepo.GetListDetailedByPeriod(ctx, logined.ID, uint64(year), uint64(month), logined.OverspendingMode)
+	if err != nil {
+		return statistics, err
+	}
+
+	statistics.TotalReserved = decimal.Zero
+	for _, group := range budget.Groups {
+		statistics.TotalReserved = statistics.TotalReserved.Add(decimal.NewFromFloat(group.Available))
+	}
+	statistics.FreeToDistribute = budget.ToBeBudgeted
+	statistics.Currency = logined.BaseCurrency
+
+	statistics.MissingRates, err = s.rateRepo.GetMissingCurrencies(ctx, logined.ID)
+	if err != nil {
+		return statistics, err
+	}
+
+	return statistics, nil
+}
+
+func (s *StatisticsService) GetPeriodStatistics(ctx context.Context, logined model.User, req model.PeriodStatisticsRequest) (model.PeriodStatisticsResponse, error) {
+	period, err := validateStatisticsRequest(req.Period, req.Filter())
+	if err != nil {
+		return model.PeriodStatisticsResponse{}, err
+	}
+
+	items, err := s.repo.GetPeriodStatistics(ctx, logined.ID, period, req.Filter())
+	if err != nil {
+		return model.PeriodStatisticsResponse{}, err
+	}
+
+	missing, err := s.rateRepo.GetMissingCurrencies(ctx, logined.ID)
+	if err != nil {
+		return model.PeriodStatisticsResponse{}, err
+	}
+
+	return model.PeriodStatisticsResponse{
+		Period:       period,
+		Currency:     logined.BaseCurrency,
+		Items:        items,
+		MissingRates: missing,
+	}, nil
+}
+
+func (s *StatisticsService) GetCategoryStatistics(ctx context.Context, logined model.User, req model.CategoryStatisticsRequest) (model.CategoryStatisticsResponse, error) {
+	period, err := validateStatisticsRequest(req.Period, req.Filter())
+	if err != nil {
+		return model.CategoryStatisticsResponse{}, err
+	}
+
+	items, err := s.repo.GetCategoryStatistics(ctx, logined.ID, period, req.Filter())
+	if err != nil {
+		return model.CategoryStatisticsResponse{}, err
+	}
+
+	missing, err := s.rateRepo.GetMissingCurrencies(ctx, logined.ID)
+	if err != nil {
+		return model.CategoryStatisticsResponse{}, err
+	}
+
+	return model.CategoryStatisticsResponse{
+		Period:       period,
+		Currency:     logined.BaseCurrency,
+		Items:        items,
+		MissingRates: missing,
+	}, nil
+}
+
+// GetPayeeStatistics сводит доходы и расходы по получателям за период фильтра.
+func (s *StatisticsService) GetPayeeStatistics(ctx context.Context, logined model.User, req model.PayeeStatisticsRequest) (model.PayeeStatisticsResponse, error) {
+	if _, err := validateStatisticsRequest(model.PeriodTypeMonth, req.Filter()); err != nil {
+		return model.PayeeStatisticsResponse{}, err
+	}
+
+	items, err := s.repo.GetPayeeStatistics(ctx, logined.ID, req.Filter())
+	if err != nil {
+		return model.PayeeStatisticsResponse{}, err
+	}
+
+	missing, err := s.rateRepo.GetMissingCurrencies(ctx, logined.ID)
+	if err != nil {
+		return model.PayeeStatisticsResponse{}, err
+	}
+
+	return model.PayeeStatisticsResponse{
+		Currency:     logined.BaseCurrency,
+		Items:        items,
+		MissingRates: missing,
+	}, nil
+}
+
+// GetNetWorth считает капитал по всем счетам, включая внебюджетные, с оценкой
+// бумаг по последним введённым ценам.
+func (s *StatisticsService) GetNetWorth(ctx context.Context, logined model.User) (model.NetWorth, error) {
+	accounts, err := s.repo.GetNetWorth(ctx, logined.ID)
+	if err != nil {
+		return model.NetWorth{}, err
+	}
+
+	netWorth := model.NewNetWorth(logined.BaseCurrency, accounts)
+	netWorth.MissingRates, err = s.rateRepo.GetMissingCurrencies(ctx, logined.ID)
+	if err != nil {
+		return model.NetWorth{}, err
+	}
+
+	return netWorth, nil
+}
+
+// validateStatisticsRequest проверяет фильтры и возвращает период, по умолчанию — месяц.
+func validateStatisticsRequest(period model.PeriodType, filter model.StatisticsFilter) (model.PeriodType, error) {
+	if period == "" {
+		period = model.PeriodTypeMonth
+	}
+
+	if !period.IsValid() {
+		return period, ErrInvalidStatisticsFilter
+	}
+
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return period, ErrInvalidStatisticsFilter
+	}
+
+	return period, nil
+}
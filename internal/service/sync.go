@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository"
+	"strings"
+	"time"
+)
+
+const (
+	maxSyncBatchSize   = 500
+	maxSyncClientIDLen = 64
+)
+
+var (
+	ErrInvalidSyncToken   = errors.New("invalid sync token")
+	ErrInvalidSyncRequest = errors.New("invalid sync request")
+)
+
+type SyncService struct {
+	repo repository.SyncRepository
+}
+
+func NewSyncService(repo repository.SyncRepository) *SyncService {
+	return &SyncService{
+		repo: repo,
+	}
+}
+
+// Pull отдаёт изменения после токена. Без токена клиент получает все свои данные.
+func (s *SyncService) Pull(ctx context.Context, logined model.User, req model.SyncPullRequest) (model.SyncPullResponse, error) {
+	since := int64(-1)
+	if req.Token != "" {
+		version, err := model.DecodeSyncToken(req.Token)
+		if err != nil {
+			return model.SyncPullResponse{}, fmt.Errorf("%w: %v", ErrInvalidSyncToken, err)
+		}
+		since = version
+	}
+
+	changes, version, err := s.repo.GetChanges(ctx, logined.ID, since)
+	if err != nil {
+		return model.SyncPullResponse{}, err
+	}
+
+	return model.SyncPullResponse{
+		SyncChanges: changes,
+		Token:       model.EncodeSyncToken(version),
+	}, nil
+}
+
+// Push применяет офлайн-очередь клиента. Некорректные изменения отклоняются по
+// отдельности, результат возвращается для каждого изменения в порядке запроса.
+func (s *SyncService) Push(ctx context.Context, logined model.User, req model.SyncPushRequest) (model.SyncPushResponse, error) {
+	if len(req.Changes) == 0 || len(req.Changes) > maxSyncBatchSize {
+		return model.SyncPushResponse{}, ErrInvalidSyncRequest
+	}
+
+	now := time.Now()
+	results := make([]model.SyncChangeResult, len(req.Changes))
+	records := make([]model.SyncChangeRecord, 0, len(req.Changes))
+	positions := make([]int, 0, len(req.Changes))
+
+	for i, change := range req.Changes {
+		record, err := newSyncChangeRecord(change, now)
+		if err != nil {
+			results[i] = model.SyncChangeResult{
+				Entity:   change.Entity,
+				ClientID: change.ClientID,
+				Status:   model.SyncChangeRejected,
+				Error:    err.Error(),
+			}
+			if change.ID != nil {
+				results[i].ID = *change.ID
+			}
+			continue
+		}
+
+		records = append(records, record)
+		positions = append(positions, i)
+	}
+
+	if len(records) > 0 {
+		applied, err := s.repo.ApplyChanges(ctx, logined.ID, records)
+		if err != nil {
+			return model.SyncPushResponse{}, err
+		}
+
+		for i, result := range applied {
+			results[positions[i]] = result
+		}
+	}
+
+	return model.SyncPushResponse{Results: results}, nil
+}
+
+func newSyncChangeRecord(change model.SyncChange, now time.Time) (model.SyncChangeRecord, error) {
+	record := model.SyncChangeRecord{
+		Entity:      change.Entity,
+		Operation:   change.Operation,
+		ID:          change.ID,
+		ClientID:    change.ClientID,
+		BaseVersion: change.BaseVersion,
+		UpdatedAt:   now,
+	}
+
+	if !change.Entity.IsValid() {
+		return record, errors.New("unknown entity")
+	}
+
+	if !change.Operation.IsValid() {
+		return record, errors.New("unknown operation")
+	}
+
+	if err := validateSyncClientID(change.ClientID); err != nil {
+		return record, err
+	}
+
+	if change.ID == nil && change.ClientID == nil {
+		return record, errors.New("id or client_id is required")
+	}
+
+	if change.Operation == model.SyncOperationDelete {
+		return record, nil
+	}
+
+	if len(change.Data) == 0 {
+		return record, errors.New("data is required")
+	}
+
+	var err error
+	switch change.Entity {
+	case model.SyncEntityAccount:
+		record.Account, err = decodeSyncData[model.SyncAccountData](change.Data)
+		if err == nil && (strings.TrimSpace(record.Account.Name) == "" || record.Account.Type == "") {
+			err = errors.New("account name and type are required")
+		}
+	case model.SyncEntityCategory:
+		record.Category, err = decodeSyncData[model.SyncCategoryData](change.Data)
+		if err == nil && strings.TrimSpace(record.Category.Name) == "" {
+			err = errors.New("category name is required")
+		}
+		if err == nil {
+			err = validateSyncClientID(record.Category.GroupClientID)
+		}
+		if err == nil {
+			err = validateCategoryTarget(record.Category.Target)
+		}
+	case model.SyncEntityCategoryGroup:
+		record.CategoryGroup, err = decodeSyncData[model.SyncCategoryGroupData](change.Data)
+		if err == nil && strings.TrimSpace(record.CategoryGroup.Name) == "" {
+			err = ErrInvalidCategoryGroupName
+		}
+	case model.SyncEntityTransaction:
+		record.Transaction, err = decodeSyncData[model.SyncTransactionData](change.Data)
+		if err == nil {
+			err = validateSyncClientID(record.Transaction.AccountClientID)
+		}
+		if err == nil {
+			err = validateSyncClientID(record.Transaction.CategoryClientID)
+		}
+		if err == nil && record.Transaction.AccountID == nil && record.Transaction.AccountClientID == nil {
+			err = errors.New("account is required")
+		}
+		if err == nil && record.Transaction.Date.IsZero() {
+			err = errors.New("date is required")
+		}
+	case model.SyncEntityBudgetAllocation:
+		record.BudgetAllocation, err = decodeSyncData[model.SyncBudgetAllocationData](change.Data)
+		if err == nil {
+			err = validateSyncClientID(record.BudgetAllocation.CategoryClientID)
+		}
+		if err == nil && record.BudgetAllocation.CategoryID == nil && record.BudgetAllocation.CategoryClientID == nil {
+			err = errors.New("category is required")
+		}
+		if err == nil && !isValidBudgetPeriod(record.BudgetAllocation.Year, record.BudgetAllocation.Month) {
+			err = ErrInvalidBudgetPeriod
+		}
+	}
+
+	return record, err
+}
+
+func decodeSyncData[T any](data json.RawMessage) (*T, error) {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("invalid data: %v", err)
+	}
+
+	return &value, nil
+}
+
+func validateSyncClientID(clientID *string) error {
+	if clientID == nil {
+		return nil
+	}
+
+	if *clientID == "" || len(*clientID) > maxSyncClientIDLen {
+		return errors.New("invalid client_id")
+	}
+
+	return nil
+}
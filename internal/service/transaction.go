@@ -3,28 +3,71 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
 	"litespend-api/internal/model"
 	"litespend-api/internal/repository"
+
+	"github.com/shopspring/decimal"
 )
 
 var (
-	ErrTransactionNotFound = errors.New("transaction not found")
-	ErrAccessDenied        = errors.New("access denied")
+	ErrTransactionNotFound   = errors.New("transaction not found")
+	ErrAccessDenied          = errors.New("access denied")
+	ErrInvalidTransfer       = errors.New("invalid transfer")
+	ErrInvalidSplit          = errors.New("split amounts must be non-zero and sum up to the transaction amount")
+	ErrTransactionReconciled = errors.New("transaction is reconciled, pass unlock_reconciled to change amount, date or account")
+	ErrOffBudgetCategory     = errors.New("transactions on off-budget accounts have no category")
+	ErrInvestmentTransaction = errors.New("investment activity transactions cannot be changed")
+
+	ErrInvalidTransactionFilter = errors.New("invalid transaction filter")
+	ErrInvalidCursor            = errors.New("invalid cursor")
 )
 
 type TransactionService struct {
-	repo repository.TransactionRepository
+	repo        repository.TransactionRepository
+	accountRepo repository.AccountRepository
+	payeeRepo   repository.PayeeRepository
+	rules       ruleRunner
 }
 
-func NewTransactionService(repository repository.TransactionRepository) *TransactionService {
+func NewTransactionService(
+	repository repository.TransactionRepository,
+	accountRepo repository.AccountRepository,
+	payeeRepo repository.PayeeRepository,
+	ruleRepo repository.RuleRepository,
+) *TransactionService {
 	return &TransactionService{
-		repo: repository,
+		repo:        repository,
+		accountRepo: accountRepo,
+		payeeRepo:   payeeRepo,
+		rules:       newRuleRunner(ruleRepo, repository, accountRepo),
 	}
 }
 
 func (s *TransactionService) Create(ctx context.Context, logined model.User, req model.CreateTransactionRequest) (int, error) {
+	splits, err := buildSplitRecords(req.Amount, req.Splits)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(splits) > 0 && req.CategoryID != nil {
+		return 0, ErrInvalidSplit
+	}
+
+	if req.CategoryID != nil || len(splits) > 0 {
+		if err := s.checkOnBudget(ctx, req.AccountID); err != nil {
+			return 0, err
+		}
+	}
+
+	payee, err := resolvePayee(ctx, s.payeeRepo, logined.ID, req.PayeeID, req.PayeeName)
+	if err != nil {
+		return 0, err
+	}
+
 	transaction := model.CreateTransactionRecord{
 		UserID:     logined.ID,
 		CategoryID: req.CategoryID,
@@ -34,11 +77,24 @@ func (s *TransactionService) Create(ctx context.Context, logined model.User, req
 		Note:       req.Note,
 		IsCleared:  req.IsCleared,
 		IsApproved: req.IsApproved,
+		Splits:     splits,
 		UpdatedAt:  time.Now(),
 		CreatedAt:  time.Now(),
 	}
 
-	id, err := s.repo.Create(ctx, transaction)
+	var payeeName string
+	if payee != nil {
+		transaction.PayeeID = &payee.ID
+		payeeName = payee.Name
+	}
+
+	// Правила дополняют то, что не задано явно
+	rules, err := s.rules.load(ctx, logined.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	id, _, err := s.rules.create(ctx, rules, transaction, payeeName)
 	if err != nil {
 		return 0, err
 	}
@@ -46,6 +102,75 @@ func (s *TransactionService) Create(ctx context.Context, logined model.User, req
 	return id, nil
 }
 
+func (s *TransactionService) CreateTransfer(ctx context.Context, logined model.User, req model.CreateTransferRequest) (model.TransferResult, error) {
+	if req.FromAccountID == req.ToAccountID || !req.Amount.IsPositive() {
+		return model.TransferResult{}, ErrInvalidTransfer
+	}
+
+	accounts := make([]model.Account, 0, 2)
+	for _, accountID := range []uint64{req.FromAccountID, req.ToAccountID} {
+		account, err := s.accountRepo.GetByID(ctx, accountID)
+		if err != nil {
+			return model.TransferResult{}, ErrAccountNotFound
+		}
+
+		if account.UserID != logined.ID {
+			return model.TransferResult{}, ErrAccessDenied
+		}
+		accounts = append(accounts, account)
+	}
+
+	// Категорию получает только бюджетная половина перевода на внебюджетный счёт или с него
+	if req.CategoryID != nil && accounts[0].OnBudget == accounts[1].OnBudget {
+		return model.TransferResult{}, ErrInvalidTransfer
+	}
+
+	// Между валютами списание и зачисление различаются, обе суммы задаёт клиент
+	toAmount := req.Amount
+	if accounts[0].Currency != accounts[1].Currency {
+		if req.ToAmount == nil || !req.ToAmount.IsPositive() {
+			return model.TransferResult{}, ErrInvalidTransfer
+		}
+		toAmount = *req.ToAmount
+	} else if req.ToAmount != nil && !req.ToAmount.Equal(req.Amount) {
+		return model.TransferResult{}, ErrInvalidTransfer
+	}
+
+	from := model.CreateTransactionRecord{
+		UserID:     logined.ID,
+		AccountID:  req.FromAccountID,
+		Amount:     req.Amount.Neg(),
+		Note:       req.Note,
+		Date:       req.Date,
+		IsCleared:  req.IsCleared,
+		IsApproved: req.IsApproved,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	to := from
+	to.AccountID = req.ToAccountID
+	to.Amount = toAmount
+
+	if req.CategoryID != nil {
+		if accounts[0].OnBudget {
+			from.CategoryID = req.CategoryID
+		} else {
+			to.CategoryID = req.CategoryID
+		}
+	}
+
+	fromID, toID, err := s.repo.CreateTransfer(ctx, from, to)
+	if err != nil {
+		return model.TransferResult{}, err
+	}
+
+	return model.TransferResult{
+		FromTransactionID: fromID,
+		ToTransactionID:   toID,
+	}, nil
+}
+
 func (s *TransactionService) Update(ctx context.Context, logined model.User, id int, dto model.UpdateTransactionRequest) error {
 	transaction, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -56,7 +181,12 @@ func (s *TransactionService) Update(ctx context.Context, logined model.User, id
 		return ErrAccessDenied
 	}
 
-	err = s.repo.Update(ctx, id, model.UpdateTransactionRecord{
+	// Сумма сделки связана с позицией по бумаге, правка разошлась бы с ней
+	if transaction.Origin.IsInvestment() {
+		return ErrInvestmentTransaction
+	}
+
+	record := model.UpdateTransactionRecord{
 		AccountID:  dto.AccountID,
 		CategoryID: dto.CategoryID,
 		Amount:     dto.Amount,
@@ -65,7 +195,77 @@ func (s *TransactionService) Update(ctx context.Context, logined model.User, id
 		IsCleared:  dto.IsCleared,
 		IsApproved: dto.IsApproved,
 		UpdatedAt:  time.Now(),
-	})
+	}
+
+	if dto.PayeeID != nil || dto.PayeeName != nil {
+		payee, err := resolvePayee(ctx, s.payeeRepo, transaction.UserID, dto.PayeeID, dto.PayeeName)
+		if err != nil {
+			return err
+		}
+		if payee != nil {
+			record.PayeeID = &payee.ID
+		}
+		record.ClearPayee = payee == nil
+	}
+
+	if transaction.IsTransfer() {
+		if dto.Splits != nil {
+			return ErrInvalidTransfer
+		}
+		return s.updateTransfer(ctx, transaction, record, dto.TransferAmount, dto.UnlockReconciled)
+	}
+
+	if err := checkReconciled(transaction, &record, dto.UnlockReconciled); err != nil {
+		return err
+	}
+
+	// Категория после правки: новая, из новой разбивки или оставшаяся прежней
+	categorized := dto.CategoryID != nil
+	if dto.Splits != nil {
+		categorized = categorized || len(*dto.Splits) > 0
+	} else {
+		categorized = categorized || transaction.CategoryID != nil || transaction.IsSplit()
+	}
+	if categorized {
+		accountID := transaction.AccountID
+		if dto.AccountID != nil {
+			accountID = *dto.AccountID
+		}
+		if err := s.checkOnBudget(ctx, accountID); err != nil {
+			return err
+		}
+	}
+
+	amount := transaction.Amount
+	if dto.Amount != nil {
+		amount = *dto.Amount
+	}
+
+	if dto.Splits != nil {
+		splits, err := buildSplitRecords(amount, *dto.Splits)
+		if err != nil {
+			return err
+		}
+		if len(splits) > 0 && dto.CategoryID != nil {
+			return ErrInvalidSplit
+		}
+		record.Splits = &splits
+	} else if transaction.IsSplit() {
+		// Без новой разбивки сумма и категория должны остаться согласованными с текущей
+		if dto.CategoryID != nil {
+			return ErrInvalidSplit
+		}
+
+		current := make([]model.CreateTransactionSplitRequest, 0, len(transaction.Splits))
+		for _, split := range transaction.Splits {
+			current = append(current, model.CreateTransactionSplitRequest{CategoryID: split.CategoryID, Amount: split.Amount})
+		}
+		if _, err := buildSplitRecords(amount, current); err != nil {
+			return err
+		}
+	}
+
+	err = s.repo.Update(ctx, id, record)
 	if err != nil {
 		return err
 	}
@@ -73,6 +273,182 @@ func (s *TransactionService) Update(ctx context.Context, logined model.User, id
 	return nil
 }
 
+// buildSplitRecords проверяет, что строки разбивки в сумме дают сумму транзакции.
+func buildSplitRecords(amount decimal.Decimal, splits []model.CreateTransactionSplitRequest) ([]model.CreateTransactionSplitRecord, error) {
+	records := make([]model.CreateTransactionSplitRecord, 0, len(splits))
+	if len(splits) == 0 {
+		return records, nil
+	}
+
+	total := decimal.Zero
+	for _, split := range splits {
+		if split.Amount.IsZero() || split.CategoryID == 0 {
+			return nil, ErrInvalidSplit
+		}
+
+		total = total.Add(split.Amount)
+		records = append(records, model.CreateTransactionSplitRecord{
+			CategoryID: split.CategoryID,
+			Amount:     split.Amount,
+			Note:       split.Note,
+		})
+	}
+
+	if !total.Equal(amount) {
+		return nil, ErrInvalidSplit
+	}
+
+	return records, nil
+}
+
+// checkOnBudget не даёт назначить категорию транзакции внебюджетного счёта:
+// бюджет такие транзакции не учитывает.
+func (s *TransactionService) checkOnBudget(ctx context.Context, accountID uint64) error {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return ErrAccountNotFound
+	}
+
+	if !account.OnBudget {
+		return ErrOffBudgetCategory
+	}
+
+	return nil
+}
+
+// checkReconciled не даёт менять сумму, дату и счёт сверенной транзакции. С unlock
+// правка проходит, а отметка о сверке снимается.
+func checkReconciled(transaction model.Transaction, record *model.UpdateTransactionRecord, unlock bool) error {
+	if !transaction.IsReconciled() {
+		return nil
+	}
+
+	changed := (record.Amount != nil && !record.Amount.Equal(transaction.Amount)) ||
+		(record.Date != nil && !record.Date.Equal(transaction.Date)) ||
+		(record.AccountID != nil && *record.AccountID != transaction.AccountID)
+	if !changed {
+		return nil
+	}
+
+	if !unlock {
+		return ErrTransactionReconciled
+	}
+	record.Unreconcile = true
+
+	return nil
+}
+
+// updateTransfer переносит сумму, дату и заметку на вторую половину перевода.
+// Счёт, cleared и approved у каждой половины свои. Категория бывает только у
+// бюджетной половины перевода между бюджетным и внебюджетным счётом.
+// transferAmount — сумма второй половины, нужна только между счетами в разных валютах.
+func (s *TransactionService) updateTransfer(ctx context.Context, transaction model.Transaction, record model.UpdateTransactionRecord, transferAmount *decimal.Decimal, unlockReconciled bool) error {
+	pairID := int(*transaction.TransferTransactionID)
+	pair, err := s.repo.GetByID(ctx, pairID)
+	if err != nil {
+		return ErrTransactionNotFound
+	}
+
+	accountID := transaction.AccountID
+	if record.AccountID != nil {
+		accountID = *record.AccountID
+	}
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return ErrAccountNotFound
+	}
+
+	if account.UserID != transaction.UserID || account.ID == pair.AccountID {
+		return ErrInvalidTransfer
+	}
+
+	pairAccount, err := s.accountRepo.GetByID(ctx, pair.AccountID)
+	if err != nil {
+		return ErrAccountNotFound
+	}
+
+	pairRecord := model.UpdateTransactionRecord{
+		Date:      record.Date,
+		Note:      record.Note,
+		UpdatedAt: record.UpdatedAt,
+	}
+
+	categoryID := record.CategoryID
+	if categoryID == nil {
+		categoryID = transaction.CategoryID
+	}
+	if categoryID == nil {
+		categoryID = pair.CategoryID
+	}
+
+	if categoryID != nil {
+		switch {
+		case account.OnBudget == pairAccount.OnBudget && record.CategoryID != nil:
+			return ErrInvalidTransfer
+		case account.OnBudget == pairAccount.OnBudget:
+			// После смены счёта перевод стал внутренним, категория ему больше не нужна
+			record.ClearCategory = true
+			pairRecord.ClearCategory = true
+		case account.OnBudget:
+			record.CategoryID = categoryID
+			pairRecord.ClearCategory = true
+		default:
+			record.CategoryID = nil
+			record.ClearCategory = true
+			pairRecord.CategoryID = categoryID
+		}
+	}
+
+	// Знак половины перевода определяется исходной транзакцией
+	sign := decimal.NewFromInt(1)
+	if transaction.Amount.IsNegative() {
+		sign = sign.Neg()
+	}
+
+	if account.Currency != pairAccount.Currency {
+		// Между валютами суммы половин независимы, но меняются только вместе
+		if (record.Amount == nil) != (transferAmount == nil) {
+			return ErrInvalidTransfer
+		}
+		if transferAmount != nil {
+			pairAmount := transferAmount.Abs().Mul(sign).Neg()
+			if pairAmount.IsZero() {
+				return ErrInvalidTransfer
+			}
+			pairRecord.Amount = &pairAmount
+		}
+	} else if transferAmount != nil {
+		return ErrInvalidTransfer
+	}
+
+	if record.Amount != nil {
+		amount := record.Amount.Abs().Mul(sign)
+		if amount.IsZero() {
+			return ErrInvalidTransfer
+		}
+		record.Amount = &amount
+
+		if pairRecord.Amount == nil {
+			pairAmount := amount.Neg()
+			pairRecord.Amount = &pairAmount
+		}
+	} else if account.Currency == pairAccount.Currency && !pair.Amount.Equal(transaction.Amount.Neg()) {
+		// Перевод стал переводом в одной валюте, вторая половина выравнивается по первой
+		pairAmount := transaction.Amount.Neg()
+		pairRecord.Amount = &pairAmount
+	}
+
+	// Половины перевода сверяются на своих счетах независимо
+	if err := checkReconciled(transaction, &record, unlockReconciled); err != nil {
+		return err
+	}
+	if err := checkReconciled(pair, &pairRecord, unlockReconciled); err != nil {
+		return err
+	}
+
+	return s.repo.UpdateTransfer(ctx, int(transaction.ID), record, pairID, pairRecord)
+}
+
 func (s *TransactionService) Delete(ctx context.Context, logined model.User, id int) error {
 	transaction, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -83,7 +459,35 @@ func (s *TransactionService) Delete(ctx context.Context, logined model.User, id
 		return ErrAccessDenied
 	}
 
-	err = s.repo.Delete(ctx, id)
+	if transaction.Origin.IsInvestment() {
+		return ErrInvestmentTransaction
+	}
+
+	// Для перевода репозиторий удаляет обе половины
+	err = s.repo.Delete(ctx, id, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Restore возвращает транзакцию из корзины, если её счёт не удалён.
+func (s *TransactionService) Restore(ctx context.Context, logined model.User, id int) error {
+	transaction, err := s.repo.GetDeletedByID(ctx, id)
+	if err != nil {
+		return ErrTransactionNotFound
+	}
+
+	if transaction.UserID != logined.ID && logined.Role != model.UserRoleAdmin {
+		return ErrAccessDenied
+	}
+
+	if _, err = s.accountRepo.GetByID(ctx, transaction.AccountID); err != nil {
+		return ErrAccountInTrash
+	}
+
+	err = s.repo.Restore(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -113,13 +517,116 @@ func (s *TransactionService) GetList(ctx context.Context, logined model.User) ([
 	return transactions, nil
 }
 
-func (s *TransactionService) GetListPaginated(ctx context.Context, logined model.User, accountID *uint64, params model.PaginationParams) (model.PaginatedTransactionsResponse, error) {
+func (s *TransactionService) GetListPaginated(ctx context.Context, logined model.User, filter model.TransactionFilter, params model.PaginationParams) (model.PaginatedTransactionsResponse, error) {
 	params.Validate()
 
-	transactions, total, err := s.repo.GetListPaginated(ctx, logined.ID, accountID, params)
+	if err := validateTransactionFilter(filter); err != nil {
+		return model.PaginatedTransactionsResponse{}, err
+	}
+
+	transactions, total, totals, err := s.repo.GetListPaginated(ctx, logined.ID, filter, params)
 	if err != nil {
 		return model.PaginatedTransactionsResponse{}, err
 	}
 
-	return model.NewPaginatedResponse(transactions, total, params), nil
+	return model.PaginatedTransactionsResponse{
+		PaginatedResponse: model.NewPaginatedResponse(transactions, total, params),
+		Totals:            totals,
+	}, nil
+}
+
+// GetListByCursor отдаёт страницу keyset-пагинации. Сортировка берётся из курсора,
+// а для первой страницы — из параметров запроса. Курсор, выданный для другой
+// сортировки, чем явно переданная в запросе, отклоняется.
+func (s *TransactionService) GetListByCursor(ctx context.Context, logined model.User, filter model.TransactionFilter, params model.PaginationParams) (model.CursorTransactionsResponse, error) {
+	params.Validate()
+
+	if err := validateTransactionFilter(filter); err != nil {
+		return model.CursorTransactionsResponse{}, err
+	}
+
+	var cursor *model.Cursor
+	if params.Cursor != nil && *params.Cursor != "" {
+		decoded, err := model.DecodeCursor(*params.Cursor)
+		if err != nil {
+			return model.CursorTransactionsResponse{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+
+		if params.SortBy != nil && *params.SortBy != decoded.SortBy ||
+			params.SortOrder != nil && *params.SortOrder != decoded.SortOrder {
+			return model.CursorTransactionsResponse{}, fmt.Errorf("%w: sort differs from the cursor", ErrInvalidCursor)
+		}
+
+		cursor = &decoded
+		params.SortBy = &decoded.SortBy
+		params.SortOrder = &decoded.SortOrder
+	}
+
+	transactions, hasMore, err := s.repo.GetListByCursor(ctx, logined.ID, filter, params, cursor)
+	if err != nil {
+		return model.CursorTransactionsResponse{}, err
+	}
+
+	response := model.CursorTransactionsResponse{
+		Data: transactions,
+		Meta: model.CursorMeta{Limit: params.Limit},
+	}
+	if len(transactions) == 0 {
+		return response, nil
+	}
+
+	sortBy, sortOrder := params.SortFieldOrDefault()
+	backward := cursor != nil && cursor.Backward
+
+	// Вперёд можно идти, если есть ещё строки или мы пришли сюда, листая назад
+	if hasMore || backward {
+		next := transactionCursor(transactions[len(transactions)-1], sortBy, sortOrder, false).Encode()
+		response.Meta.NextCursor = &next
+	}
+
+	if (cursor != nil && !backward) || (backward && hasMore) {
+		prev := transactionCursor(transactions[0], sortBy, sortOrder, true).Encode()
+		response.Meta.PrevCursor = &prev
+	}
+
+	return response, nil
+}
+
+func transactionCursor(transaction model.Transaction, sortBy model.SortField, sortOrder model.SortOrder, backward bool) model.Cursor {
+	cursor := model.Cursor{
+		SortBy:    sortBy,
+		SortOrder: sortOrder,
+		ID:        transaction.ID,
+		Backward:  backward,
+	}
+
+	switch sortBy {
+	case model.SortFieldCategory:
+		cursor.Key = "0"
+		if transaction.CategoryID != nil {
+			cursor.Key = strconv.FormatUint(*transaction.CategoryID, 10)
+		}
+	case model.SortFieldDescription:
+		cursor.Key = transaction.Note
+	default:
+		cursor.Key = transaction.Date.Format(model.CursorDateLayout)
+	}
+
+	return cursor
+}
+
+func validateTransactionFilter(filter model.TransactionFilter) error {
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return ErrInvalidTransactionFilter
+	}
+
+	if filter.MinAmount != nil && filter.MaxAmount != nil && filter.MinAmount.GreaterThan(*filter.MaxAmount) {
+		return ErrInvalidTransactionFilter
+	}
+
+	if filter.Kind != nil && !filter.Kind.IsValid() {
+		return ErrInvalidTransactionFilter
+	}
+
+	return nil
 }
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"litespend-api/internal/model"
+	"litespend-api/internal/repository"
+	"time"
+)
+
+type TrashService struct {
+	repo repository.TrashRepository
+}
+
+func NewTrashService(repo repository.TrashRepository) *TrashService {
+	return &TrashService{
+		repo: repo,
+	}
+}
+
+func (s *TrashService) GetList(ctx context.Context, logined model.User) (model.Trash, error) {
+	trash, err := s.repo.GetList(ctx, logined.ID)
+	if err != nil {
+		return model.Trash{}, err
+	}
+
+	return trash, nil
+}
+
+// Purge окончательно стирает записи всех пользователей, лежащие в корзине дольше retention.
+func (s *TrashService) Purge(ctx context.Context, retention time.Duration) (model.PurgeResult, error) {
+	return s.repo.Purge(ctx, time.Now().Add(-retention))
+}
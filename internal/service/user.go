@@ -12,6 +12,7 @@ import (
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidSettings    = errors.New("invalid settings")
 )
 
 type UserService struct {
@@ -60,3 +61,49 @@ func (s *UserService) Login(ctx context.Context, req model.LoginRequest) (model.
 func (s *UserService) Logout(ctx context.Context) error {
 	return nil
 }
+
+func (s *UserService) GetSettings(ctx context.Context, logined model.User) (model.UserSettings, error) {
+	return model.UserSettings{
+		OverspendingMode: logined.OverspendingMode,
+		BaseCurrency:     logined.BaseCurrency,
+	}, nil
+}
+
+func (s *UserService) UpdateSettings(ctx context.Context, logined model.User, req model.UpdateUserSettingsRequest) (model.UserSettings, error) {
+	settings := model.UserSettings{
+		OverspendingMode: logined.OverspendingMode,
+		BaseCurrency:     logined.BaseCurrency,
+	}
+
+	if req.OverspendingMode == nil && req.BaseCurrency == nil {
+		return settings, nil
+	}
+
+	if req.OverspendingMode != nil {
+		if !req.OverspendingMode.IsValid() {
+			return settings, ErrInvalidSettings
+		}
+		settings.OverspendingMode = *req.OverspendingMode
+	}
+
+	// Смена базовой валюты пересчитывает отчёты целиком, курсы к новой валюте
+	// пользователь вводит сам
+	if req.BaseCurrency != nil {
+		currency := normalizeCurrency(*req.BaseCurrency)
+		if !currency.IsValid() {
+			return settings, ErrInvalidSettings
+		}
+		req.BaseCurrency = &currency
+		settings.BaseCurrency = currency
+	}
+
+	err := s.repo.Update(ctx, int(logined.ID), model.UpdateUserRecord{
+		OverspendingMode: req.OverspendingMode,
+		BaseCurrency:     req.BaseCurrency,
+	})
+	if err != nil {
+		return settings, err
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transactions_transfer;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS transfer_transaction_id;
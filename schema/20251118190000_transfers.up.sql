@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+    ADD COLUMN transfer_transaction_id BIGINT REFERENCES transactions (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_transfer ON transactions (transfer_transaction_id);
@@ -0,0 +1,13 @@
+CREATE TABLE transaction_splits
+(
+    id             BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT         NOT NULL REFERENCES transactions (id) ON DELETE CASCADE,
+    category_id    BIGINT         NOT NULL,
+    amount         NUMERIC(14, 2) NOT NULL,
+    note           TEXT           NOT NULL DEFAULT '',
+    created_at     TIMESTAMP      NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMP      NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_transaction_splits_transaction ON transaction_splits (transaction_id);
+CREATE INDEX idx_transaction_splits_category ON transaction_splits (category_id);
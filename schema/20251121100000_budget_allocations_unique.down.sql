@@ -0,0 +1,2 @@
+ALTER TABLE budget_allocations
+    DROP CONSTRAINT IF EXISTS budget_allocations_user_category_period_key;
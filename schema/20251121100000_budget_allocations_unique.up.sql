@@ -0,0 +1,24 @@
+-- Схлопываем дубликаты, накопившиеся из-за повторных назначений
+WITH merged AS (
+    SELECT MIN(id) AS keep_id, SUM(assigned) AS assigned
+    FROM budget_allocations
+    GROUP BY user_id, category_id, year, month
+    HAVING COUNT(*) > 1
+)
+UPDATE budget_allocations ba
+SET assigned   = m.assigned,
+    updated_at = now()
+FROM merged m
+WHERE ba.id = m.keep_id;
+
+DELETE
+FROM budget_allocations ba
+    USING budget_allocations other
+WHERE ba.user_id = other.user_id
+  AND ba.category_id = other.category_id
+  AND ba.year = other.year
+  AND ba.month = other.month
+  AND ba.id > other.id;
+
+ALTER TABLE budget_allocations
+    ADD CONSTRAINT budget_allocations_user_category_period_key UNIQUE (user_id, category_id, year, month);
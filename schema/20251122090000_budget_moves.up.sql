@@ -0,0 +1,14 @@
+CREATE TABLE budget_moves
+(
+    id               BIGSERIAL PRIMARY KEY,
+    user_id          BIGINT         NOT NULL,
+    year             INT            NOT NULL,
+    month            INT            NOT NULL,
+    from_category_id BIGINT         NOT NULL,
+    to_category_id   BIGINT         NOT NULL,
+    amount           NUMERIC(14, 2) NOT NULL,
+    note             TEXT           NOT NULL DEFAULT '',
+    created_at       TIMESTAMP      NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_budget_moves_user_month ON budget_moves (user_id, year, month);
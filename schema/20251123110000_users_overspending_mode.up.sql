@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN overspending_mode TEXT NOT NULL DEFAULT 'to_be_budgeted';
@@ -0,0 +1,4 @@
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS target_type,
+    DROP COLUMN IF EXISTS target_amount,
+    DROP COLUMN IF EXISTS target_date;
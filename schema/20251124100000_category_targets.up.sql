@@ -0,0 +1,4 @@
+ALTER TABLE categories
+    ADD COLUMN target_type   TEXT,
+    ADD COLUMN target_amount NUMERIC(14, 2),
+    ADD COLUMN target_date   DATE;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_transactions_prescribed_expanse;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS scheduled_date;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS prescribed_expanse_id;
+
+DROP TABLE IF EXISTS prescribed_expanses;
@@ -0,0 +1,25 @@
+CREATE TABLE prescribed_expanses
+(
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT         NOT NULL,
+    account_id   BIGINT REFERENCES accounts (id) ON DELETE SET NULL,
+    category_id  BIGINT,
+    description  TEXT           NOT NULL DEFAULT '',
+    frequency    SMALLINT       NOT NULL,
+    repeat_every INT            NOT NULL DEFAULT 1,
+    amount       NUMERIC(14, 2) NOT NULL,
+    date         DATE           NOT NULL,
+    end_date     DATE,
+    created_at   TIMESTAMP      NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMP      NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_prescribed_expanses_user ON prescribed_expanses (user_id);
+
+-- Оплата конкретного повторения расписания
+ALTER TABLE transactions
+    ADD COLUMN prescribed_expanse_id BIGINT REFERENCES prescribed_expanses (id) ON DELETE SET NULL;
+ALTER TABLE transactions
+    ADD COLUMN scheduled_date DATE;
+
+CREATE INDEX idx_transactions_prescribed_expanse ON transactions (prescribed_expanse_id, scheduled_date);
@@ -0,0 +1,2 @@
+ALTER TABLE prescribed_expanses
+    DROP COLUMN IF EXISTS posted_through;
@@ -0,0 +1,3 @@
+-- Дата, по которую фоновая задача уже провела повторения расписания
+ALTER TABLE prescribed_expanses
+    ADD COLUMN posted_through DATE;
@@ -0,0 +1 @@
+CREATE INDEX idx_transactions_note_fts ON transactions USING GIN (to_tsvector('russian', note));
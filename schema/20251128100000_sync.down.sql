@@ -0,0 +1,39 @@
+DROP TRIGGER IF EXISTS budget_allocations_sync_tombstone ON budget_allocations;
+DROP TRIGGER IF EXISTS transactions_sync_tombstone ON transactions;
+DROP TRIGGER IF EXISTS categories_sync_tombstone ON categories;
+DROP TRIGGER IF EXISTS accounts_sync_tombstone ON accounts;
+
+DROP TRIGGER IF EXISTS budget_allocations_sync_version ON budget_allocations;
+DROP TRIGGER IF EXISTS transactions_sync_version ON transactions;
+DROP TRIGGER IF EXISTS categories_sync_version ON categories;
+DROP TRIGGER IF EXISTS accounts_sync_version ON accounts;
+
+DROP INDEX IF EXISTS idx_budget_allocations_user_version;
+DROP INDEX IF EXISTS idx_transactions_user_version;
+DROP INDEX IF EXISTS idx_categories_user_version;
+DROP INDEX IF EXISTS idx_accounts_user_version;
+
+DROP INDEX IF EXISTS idx_budget_allocations_user_client;
+DROP INDEX IF EXISTS idx_transactions_user_client;
+DROP INDEX IF EXISTS idx_categories_user_client;
+DROP INDEX IF EXISTS idx_accounts_user_client;
+
+ALTER TABLE budget_allocations
+    DROP COLUMN IF EXISTS client_id,
+    DROP COLUMN IF EXISTS version;
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS client_id,
+    DROP COLUMN IF EXISTS version;
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS client_id,
+    DROP COLUMN IF EXISTS version;
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS client_id,
+    DROP COLUMN IF EXISTS version;
+
+DROP FUNCTION IF EXISTS sync_record_tombstone();
+DROP FUNCTION IF EXISTS sync_bump_version();
+DROP FUNCTION IF EXISTS next_sync_version(BIGINT);
+
+DROP TABLE IF EXISTS sync_tombstones;
+DROP TABLE IF EXISTS sync_sequences;
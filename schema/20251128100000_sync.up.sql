@@ -0,0 +1,112 @@
+-- Монотонный счётчик изменений каждого пользователя для дельта-синхронизации
+CREATE TABLE sync_sequences
+(
+    user_id BIGINT PRIMARY KEY,
+    version BIGINT NOT NULL DEFAULT 0
+);
+
+-- Следы удалённых записей: клиент узнаёт из них, что нужно убрать у себя
+CREATE TABLE sync_tombstones
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT    NOT NULL,
+    entity     TEXT      NOT NULL,
+    entity_id  BIGINT    NOT NULL,
+    client_id  TEXT,
+    version    BIGINT    NOT NULL,
+    deleted_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_sync_tombstones_user_version ON sync_tombstones (user_id, version);
+
+-- Строка счётчика блокируется до конца транзакции, поэтому версии одного
+-- пользователя фиксируются строго по возрастанию
+CREATE FUNCTION next_sync_version(p_user_id BIGINT) RETURNS BIGINT AS
+$$
+INSERT INTO sync_sequences (user_id, version)
+VALUES (p_user_id, 1)
+ON CONFLICT (user_id) DO UPDATE SET version = sync_sequences.version + 1
+RETURNING version;
+$$ LANGUAGE sql;
+
+CREATE FUNCTION sync_bump_version() RETURNS trigger AS
+$$
+BEGIN
+    NEW.version := next_sync_version(NEW.user_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION sync_record_tombstone() RETURNS trigger AS
+$$
+BEGIN
+    INSERT INTO sync_tombstones (user_id, entity, entity_id, client_id, version)
+    VALUES (OLD.user_id, TG_TABLE_NAME, OLD.id, OLD.client_id, next_sync_version(OLD.user_id));
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE accounts
+    ADD COLUMN version   BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN client_id TEXT;
+ALTER TABLE categories
+    ADD COLUMN version   BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN client_id TEXT;
+ALTER TABLE transactions
+    ADD COLUMN version   BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN client_id TEXT;
+ALTER TABLE budget_allocations
+    ADD COLUMN version   BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN client_id TEXT;
+
+CREATE UNIQUE INDEX idx_accounts_user_client ON accounts (user_id, client_id);
+CREATE UNIQUE INDEX idx_categories_user_client ON categories (user_id, client_id);
+CREATE UNIQUE INDEX idx_transactions_user_client ON transactions (user_id, client_id);
+CREATE UNIQUE INDEX idx_budget_allocations_user_client ON budget_allocations (user_id, client_id);
+
+CREATE INDEX idx_accounts_user_version ON accounts (user_id, version);
+CREATE INDEX idx_categories_user_version ON categories (user_id, version);
+CREATE INDEX idx_transactions_user_version ON transactions (user_id, version);
+CREATE INDEX idx_budget_allocations_user_version ON budget_allocations (user_id, version);
+
+CREATE TRIGGER accounts_sync_version
+    BEFORE INSERT OR UPDATE
+    ON accounts
+    FOR EACH ROW
+EXECUTE FUNCTION sync_bump_version();
+CREATE TRIGGER categories_sync_version
+    BEFORE INSERT OR UPDATE
+    ON categories
+    FOR EACH ROW
+EXECUTE FUNCTION sync_bump_version();
+CREATE TRIGGER transactions_sync_version
+    BEFORE INSERT OR UPDATE
+    ON transactions
+    FOR EACH ROW
+EXECUTE FUNCTION sync_bump_version();
+CREATE TRIGGER budget_allocations_sync_version
+    BEFORE INSERT OR UPDATE
+    ON budget_allocations
+    FOR EACH ROW
+EXECUTE FUNCTION sync_bump_version();
+
+CREATE TRIGGER accounts_sync_tombstone
+    AFTER DELETE
+    ON accounts
+    FOR EACH ROW
+EXECUTE FUNCTION sync_record_tombstone();
+CREATE TRIGGER categories_sync_tombstone
+    AFTER DELETE
+    ON categories
+    FOR EACH ROW
+EXECUTE FUNCTION sync_record_tombstone();
+CREATE TRIGGER transactions_sync_tombstone
+    AFTER DELETE
+    ON transactions
+    FOR EACH ROW
+EXECUTE FUNCTION sync_record_tombstone();
+CREATE TRIGGER budget_allocations_sync_tombstone
+    AFTER DELETE
+    ON budget_allocations
+    FOR EACH ROW
+EXECUTE FUNCTION sync_record_tombstone();
@@ -0,0 +1,15 @@
+-- Записи из корзины при откате стираются, иначе они снова станут видимыми
+DELETE FROM transactions WHERE deleted_at IS NOT NULL;
+DELETE FROM categories WHERE deleted_at IS NOT NULL;
+DELETE FROM accounts WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_transactions_deleted;
+DROP INDEX IF EXISTS idx_categories_deleted;
+DROP INDEX IF EXISTS idx_accounts_deleted;
+
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE accounts
+    DROP COLUMN IF EXISTS deleted_at;
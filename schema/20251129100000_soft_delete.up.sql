@@ -0,0 +1,11 @@
+-- Удалённые записи попадают в корзину и окончательно стираются задачей очистки
+ALTER TABLE accounts
+    ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE categories
+    ADD COLUMN deleted_at TIMESTAMP;
+ALTER TABLE transactions
+    ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_accounts_deleted ON accounts (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_categories_deleted ON categories (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_transactions_deleted ON transactions (deleted_at) WHERE deleted_at IS NOT NULL;
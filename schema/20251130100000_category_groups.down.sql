@@ -0,0 +1,18 @@
+ALTER TABLE categories
+    ADD COLUMN group_name TEXT;
+
+UPDATE categories c
+SET group_name = g.name
+FROM category_groups g
+WHERE g.id = c.group_id;
+
+DROP INDEX IF EXISTS idx_categories_group;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS group_id,
+    DROP COLUMN IF EXISTS sort_order,
+    DROP COLUMN IF EXISTS is_hidden;
+
+DROP TABLE IF EXISTS category_groups;
+
+DELETE FROM sync_tombstones WHERE entity = 'category_groups';
@@ -0,0 +1,53 @@
+CREATE TABLE category_groups
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT    NOT NULL,
+    name       TEXT      NOT NULL,
+    sort_order INT       NOT NULL DEFAULT 0,
+    is_hidden  BOOLEAN   NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    version    BIGINT    NOT NULL DEFAULT 0,
+    client_id  TEXT
+);
+
+CREATE INDEX idx_category_groups_user ON category_groups (user_id, sort_order);
+CREATE UNIQUE INDEX idx_category_groups_user_client ON category_groups (user_id, client_id);
+CREATE INDEX idx_category_groups_user_version ON category_groups (user_id, version);
+
+CREATE TRIGGER category_groups_sync_version
+    BEFORE INSERT OR UPDATE
+    ON category_groups
+    FOR EACH ROW
+EXECUTE FUNCTION sync_bump_version();
+CREATE TRIGGER category_groups_sync_tombstone
+    AFTER DELETE
+    ON category_groups
+    FOR EACH ROW
+EXECUTE FUNCTION sync_record_tombstone();
+
+ALTER TABLE categories
+    ADD COLUMN group_id   BIGINT REFERENCES category_groups (id) ON DELETE SET NULL,
+    ADD COLUMN sort_order INT     NOT NULL DEFAULT 0,
+    ADD COLUMN is_hidden  BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Текстовые группы становятся записями, порядок — по алфавиту, как раньше
+INSERT INTO category_groups (user_id, name, sort_order)
+SELECT user_id, group_name, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY group_name) - 1
+FROM (SELECT DISTINCT user_id, group_name FROM categories WHERE COALESCE(group_name, '') <> '') g;
+
+UPDATE categories c
+SET group_id = g.id
+FROM category_groups g
+WHERE g.user_id = c.user_id AND g.name = c.group_name;
+
+UPDATE categories c
+SET sort_order = o.sort_order
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, group_id ORDER BY name) - 1 AS sort_order
+      FROM categories) o
+WHERE o.id = c.id;
+
+CREATE INDEX idx_categories_group ON categories (group_id, sort_order);
+
+ALTER TABLE categories
+    DROP COLUMN group_name;
@@ -0,0 +1,3 @@
+-- Служебные транзакции счёта: начальный остаток и корректировка баланса
+ALTER TABLE transactions
+    ADD COLUMN origin TEXT NOT NULL DEFAULT 'regular';
@@ -0,0 +1,3 @@
+-- Время сверки с выпиской банка, у несверенных транзакций пусто
+ALTER TABLE transactions
+    ADD COLUMN reconciled_at TIMESTAMP;
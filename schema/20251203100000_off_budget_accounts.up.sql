@@ -0,0 +1,3 @@
+-- Внебюджетные счета (инвестиции, кредиты) не участвуют в расчёте бюджета
+ALTER TABLE accounts
+    ADD COLUMN on_budget BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,9 @@
+DELETE FROM budget_allocations
+WHERE category_id IN (SELECT id FROM categories WHERE payment_account_id IS NOT NULL);
+
+DELETE FROM categories WHERE payment_account_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_categories_payment_account;
+
+ALTER TABLE categories
+    DROP COLUMN IF EXISTS payment_account_id;
@@ -0,0 +1,19 @@
+-- Категория платежа по кредитной карте: траты по карте переносят в неё деньги
+-- из категорий трат, платёж по карте их расходует
+ALTER TABLE categories
+    ADD COLUMN payment_account_id BIGINT REFERENCES accounts (id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX idx_categories_payment_account ON categories (payment_account_id);
+
+INSERT INTO categories (user_id, name, payment_account_id, sort_order, created_at, updated_at)
+SELECT a.user_id,
+       a.name,
+       a.id,
+       (SELECT COALESCE(MAX(c.sort_order) + 1, 0) FROM categories c
+        WHERE c.user_id = a.user_id AND c.group_id IS NULL AND c.deleted_at IS NULL),
+       NOW(),
+       NOW()
+FROM accounts a
+WHERE a.type = 'credit'
+  AND a.on_budget
+  AND a.deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS loan_payments;
+DROP TABLE IF EXISTS loans;
@@ -0,0 +1,25 @@
+-- Условия кредитов и ипотек для счетов типа loan
+CREATE TABLE loans
+(
+    account_id    BIGINT PRIMARY KEY REFERENCES accounts (id) ON DELETE CASCADE,
+    principal     NUMERIC(14, 2) NOT NULL,
+    interest_rate NUMERIC(7, 4)  NOT NULL,
+    term_months   INT            NOT NULL,
+    payment_day   SMALLINT       NOT NULL CHECK (payment_day BETWEEN 1 AND 31),
+    start_date    DATE           NOT NULL,
+    created_at    TIMESTAMP      NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMP      NOT NULL DEFAULT NOW()
+);
+
+-- Платёж по кредиту: половина перевода на счёт кредита и транзакция процентов,
+-- проведённая по нему же
+CREATE TABLE loan_payments
+(
+    transaction_id          BIGINT PRIMARY KEY,
+    account_id              BIGINT         NOT NULL REFERENCES loans (account_id) ON DELETE CASCADE,
+    interest_transaction_id BIGINT,
+    principal               NUMERIC(14, 2) NOT NULL,
+    interest                NUMERIC(14, 2) NOT NULL
+);
+
+CREATE INDEX idx_loan_payments_account ON loan_payments (account_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS security_prices;
+DROP TABLE IF EXISTS investment_activities;
+DROP TABLE IF EXISTS investment_holdings;
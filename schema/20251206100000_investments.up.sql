@@ -0,0 +1,42 @@
+-- Позиции по бумагам инвестиционных счетов, себестоимость по средней цене
+CREATE TABLE investment_holdings
+(
+    account_id BIGINT         NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    ticker     TEXT           NOT NULL,
+    quantity   NUMERIC(20, 8) NOT NULL,
+    cost_basis NUMERIC(14, 2) NOT NULL,
+    updated_at TIMESTAMP      NOT NULL DEFAULT now(),
+    PRIMARY KEY (account_id, ticker)
+);
+
+-- Сделки и дивиденды; движение денег проведено транзакцией transaction_id
+CREATE TABLE investment_activities
+(
+    id             BIGSERIAL PRIMARY KEY,
+    user_id        BIGINT         NOT NULL,
+    account_id     BIGINT         NOT NULL REFERENCES accounts (id) ON DELETE CASCADE,
+    transaction_id BIGINT         NOT NULL,
+    ticker         TEXT           NOT NULL,
+    kind           TEXT           NOT NULL,
+    quantity       NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    price          NUMERIC(18, 6) NOT NULL DEFAULT 0,
+    fee            NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    amount         NUMERIC(14, 2) NOT NULL,
+    realized_gain  NUMERIC(14, 2) NOT NULL DEFAULT 0,
+    date           DATE           NOT NULL,
+    created_at     TIMESTAMP      NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_investment_activities_account ON investment_activities (account_id, date);
+
+-- Цены бумаг, введённые вручную: рыночного фида нет
+CREATE TABLE security_prices
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT         NOT NULL,
+    ticker     TEXT           NOT NULL,
+    price      NUMERIC(18, 6) NOT NULL,
+    date       DATE           NOT NULL,
+    created_at TIMESTAMP      NOT NULL DEFAULT now(),
+    UNIQUE (user_id, ticker, date)
+);
@@ -0,0 +1,8 @@
+DROP FUNCTION IF EXISTS to_base_currency(BIGINT, TEXT, NUMERIC, DATE);
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE accounts
+    DROP COLUMN currency;
+
+ALTER TABLE users
+    DROP COLUMN base_currency;
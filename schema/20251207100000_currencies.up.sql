@@ -0,0 +1,50 @@
+ALTER TABLE users
+    ADD COLUMN base_currency TEXT NOT NULL DEFAULT 'RUB';
+
+ALTER TABLE accounts
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'RUB';
+
+-- Курс: одна единица from_currency стоит rate единиц to_currency на дату date
+CREATE TABLE exchange_rates
+(
+    id            BIGSERIAL PRIMARY KEY,
+    user_id       BIGINT         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    from_currency TEXT           NOT NULL,
+    to_currency   TEXT           NOT NULL,
+    rate          NUMERIC(18, 8) NOT NULL CHECK (rate > 0),
+    date          DATE           NOT NULL,
+    created_at    TIMESTAMP      NOT NULL DEFAULT now(),
+    UNIQUE (user_id, from_currency, to_currency, date)
+);
+
+-- Сумма в базовой валюте пользователя по курсу на дату. Берётся последний курс не
+-- позже даты, без него — ближайший после; подходит и обратная пара. Пока курса нет
+-- совсем, сумма учитывается как есть.
+CREATE FUNCTION to_base_currency(p_user_id BIGINT, p_currency TEXT, p_amount NUMERIC, p_date DATE)
+    RETURNS NUMERIC
+    LANGUAGE sql
+    STABLE
+AS
+$$
+SELECT CASE
+           WHEN u.base_currency = p_currency THEN p_amount
+           ELSE ROUND(p_amount * COALESCE((
+               SELECT r.rate
+               FROM (SELECT er.rate, er.date
+                     FROM exchange_rates er
+                     WHERE er.user_id = p_user_id
+                       AND er.from_currency = p_currency
+                       AND er.to_currency = u.base_currency
+                     UNION ALL
+                     SELECT 1 / er.rate, er.date
+                     FROM exchange_rates er
+                     WHERE er.user_id = p_user_id
+                       AND er.from_currency = u.base_currency
+                       AND er.to_currency = p_currency) r
+               ORDER BY r.date > p_date, ABS(r.date - p_date)
+               LIMIT 1
+           ), 1), 2)
+           END
+FROM users u
+WHERE u.id = p_user_id
+$$;
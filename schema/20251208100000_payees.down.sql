@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    DROP COLUMN payee_id;
+
+DROP TABLE IF EXISTS payee_rename_rules;
+DROP TABLE IF EXISTS payees;
@@ -0,0 +1,31 @@
+-- Получатели платежей; last_category_id — категория последней транзакции получателя,
+-- её предлагают для новых транзакций
+CREATE TABLE payees
+(
+    id               BIGSERIAL PRIMARY KEY,
+    user_id          BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name             TEXT      NOT NULL,
+    last_category_id BIGINT REFERENCES categories (id) ON DELETE SET NULL,
+    created_at       TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX ux_payees_user_name ON payees (user_id, lower(name));
+
+-- Правила переименования: текст, содержащий pattern, относится к получателю payee_id.
+-- Так «PYATEROCHKA 1234 MOSCOW» из выписки становится «Пятёрочкой».
+CREATE TABLE payee_rename_rules
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    payee_id   BIGINT    NOT NULL REFERENCES payees (id) ON DELETE CASCADE,
+    pattern    TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX ux_payee_rename_rules_user_pattern ON payee_rename_rules (user_id, lower(pattern));
+
+ALTER TABLE transactions
+    ADD COLUMN payee_id BIGINT REFERENCES payees (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_payee ON transactions (payee_id);
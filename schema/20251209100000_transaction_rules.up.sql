@@ -0,0 +1,30 @@
+-- Правила автоматической разметки транзакций. Пустое условие подходит под любую
+-- транзакцию; weekdays — битовая маска дней недели, бит 0 — понедельник, 0 — любой день.
+-- Правила проверяются по sort_order, каждое поле задаёт первое подошедшее правило.
+CREATE TABLE transaction_rules
+(
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             BIGINT    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name                TEXT      NOT NULL,
+    sort_order          INT       NOT NULL DEFAULT 0,
+    is_active           BOOLEAN   NOT NULL DEFAULT TRUE,
+
+    match_type          TEXT      NOT NULL DEFAULT 'contains' CHECK (match_type IN ('contains', 'regex')),
+    note_pattern        TEXT,
+    payee_pattern       TEXT,
+    min_amount          NUMERIC(14, 2),
+    max_amount          NUMERIC(14, 2),
+    account_id          BIGINT REFERENCES accounts (id) ON DELETE CASCADE,
+    weekdays            SMALLINT  NOT NULL DEFAULT 0,
+
+    set_category_id     BIGINT REFERENCES categories (id) ON DELETE SET NULL,
+    set_payee_id        BIGINT REFERENCES payees (id) ON DELETE SET NULL,
+    set_note            TEXT,
+    set_approved        BOOLEAN,
+    transfer_account_id BIGINT REFERENCES accounts (id) ON DELETE SET NULL,
+
+    created_at          TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at          TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_transaction_rules_user ON transaction_rules (user_id, sort_order, id);
@@ -0,0 +1,30 @@
+CREATE OR REPLACE FUNCTION to_base_currency(p_user_id BIGINT, p_currency TEXT, p_amount NUMERIC, p_date DATE)
+    RETURNS NUMERIC
+    LANGUAGE sql
+    STABLE
+AS
+$$
+SELECT CASE
+           WHEN u.base_currency = p_currency THEN p_amount
+           ELSE ROUND(p_amount * COALESCE((
+               SELECT r.rate
+               FROM (SELECT er.rate, er.date
+                     FROM exchange_rates er
+                     WHERE er.user_id = p_user_id
+                       AND er.from_currency = p_currency
+                       AND er.to_currency = u.base_currency
+                     UNION ALL
+                     SELECT 1 / er.rate, er.date
+                     FROM exchange_rates er
+                     WHERE er.user_id = p_user_id
+                       AND er.from_currency = u.base_currency
+                       AND er.to_currency = p_currency) r
+               ORDER BY r.date > p_date, ABS(r.date - p_date)
+               LIMIT 1
+           ), 1), 2)
+           END
+FROM users u
+WHERE u.id = p_user_id
+$$;
+
+DROP FUNCTION IF EXISTS base_currency_rate(BIGINT, TEXT, DATE);
@@ -0,0 +1,42 @@
+-- Курс валюты к базовой валюте пользователя на дату: 1 для базовой валюты, иначе
+-- последний курс не позже даты, без него — ближайший после; подходит и обратная
+-- пара. NULL, если курса этой пары нет совсем.
+CREATE FUNCTION base_currency_rate(p_user_id BIGINT, p_currency TEXT, p_date DATE)
+    RETURNS NUMERIC
+    LANGUAGE sql
+    STABLE
+AS
+$$
+SELECT CASE
+           WHEN u.base_currency = p_currency THEN 1::numeric
+           ELSE (
+               SELECT r.rate
+               FROM (SELECT er.rate, er.date
+                     FROM exchange_rates er
+                     WHERE er.user_id = p_user_id
+                       AND er.from_currency = p_currency
+                       AND er.to_currency = u.base_currency
+                     UNION ALL
+                     SELECT 1 / er.rate, er.date
+                     FROM exchange_rates er
+                     WHERE er.user_id = p_user_id
+                       AND er.from_currency = u.base_currency
+                       AND er.to_currency = p_currency) r
+               ORDER BY r.date > p_date, ABS(r.date - p_date)
+               LIMIT 1
+           )
+           END
+FROM users u
+WHERE u.id = p_user_id
+$$;
+
+-- Без курса сумма больше не учитывается один к одному: результат NULL, и SUM её
+-- пропускает
+CREATE OR REPLACE FUNCTION to_base_currency(p_user_id BIGINT, p_currency TEXT, p_amount NUMERIC, p_date DATE)
+    RETURNS NUMERIC
+    LANGUAGE sql
+    STABLE
+AS
+$$
+SELECT ROUND(p_amount * base_currency_rate(p_user_id, p_currency, p_date), 2)
+$$;
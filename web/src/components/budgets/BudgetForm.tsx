@@ -71,7 +71,7 @@ export function BudgetForm({ budget, defaultYear, defaultMonth, onClose, onSucce
     <form onSubmit={handleSubmit} className="grid grid-cols-1 md:grid-cols-4 gap-4">
       <div>
         <Label htmlFor="category_id">Категория</Label>
-        <select id="category_id" name="category_id" value={form.category_id} onChange={handleChange} className="mt-1 block w-full border rounded p-2">
+        <select id="category_id" name="category_id" value={form.category_id} onChange={handleChange} disabled={!!budget} className="mt-1 block w-full border rounded p-2">
           <option value="">—</option>
           {categories.map((c) => (
             <option key={c.id} value={c.id}>
@@ -82,11 +82,11 @@ export function BudgetForm({ budget, defaultYear, defaultMonth, onClose, onSucce
       </div>
       <div>
         <Label htmlFor="year">Год</Label>
-        <Input type="number" id="year" name="year" value={form.year as number} onChange={handleChange} className="mt-1" />
+        <Input type="number" id="year" name="year" value={form.year as number} onChange={handleChange} disabled={!!budget} className="mt-1" />
       </div>
       <div>
         <Label htmlFor="month">Месяц</Label>
-        <Input type="number" id="month" name="month" value={form.month as number} onChange={handleChange} className="mt-1" min={1} max={12} />
+        <Input type="number" id="month" name="month" value={form.month as number} onChange={handleChange} disabled={!!budget} className="mt-1" min={1} max={12} />
       </div>
       <div>
         <Label htmlFor="assigned">Сумма</Label>
@@ -289,10 +289,13 @@ export const importApi = {
       },
     });
   },
-  importData: (file: File, mapping: ExcelColumnMapping) => {
+  importData: (file: File, mapping: ExcelColumnMapping, accountId?: number) => {
     const formData = new FormData();
     formData.append("file", file);
     formData.append("mapping", JSON.stringify(mapping));
+    if (accountId !== undefined) {
+      formData.append("account_id", String(accountId));
+    }
     return api.post<ImportResult>("/import/data", formData, {
       headers: {
         "Content-Type": "multipart/form-data",